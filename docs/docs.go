@@ -32,27 +32,104 @@ var doc = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/v1/whatsapp/groups/{jid}": {
+        "/v1/auth/events": {
             "get": {
-                "description": "Get group metadata by phone number.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Stream the events of the sessions live, over a WebSocket when the request is an upgrade and as Server-Sent Events otherwise.\nEach WebSocket text message or SSE data line is a domain.WaEvent as JSON, the SSE id is the event id.\nTo resume after a disconnect pass the id of the last event received in last_event_id or, for SSE, the Last-Event-ID header\nbrowsers send on their own. Only the latest events are kept for resuming. The JWT can be passed in the access_token query\nparameter, browsers can not set the Authorization header on EventSource and WebSocket requests.",
                 "produces": [
-                    "application/json"
+                    "text/event-stream"
                 ],
                 "tags": [
-                    "Info"
+                    "Event"
                 ],
-                "summary": "get group metadata",
+                "summary": "stream events",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "JID. eg: group_creator-timstamp_created -\u003e 6281271471566-1619679643",
-                        "name": "jid",
-                        "in": "path",
-                        "required": true
+                        "description": "Only the events of this session",
+                        "name": "session_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated event types, eg: message.text,connection",
+                        "name": "types",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated chat JIDs",
+                        "name": "jids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resume after this event",
+                        "name": "last_event_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "JWT, instead of the Authorization header",
+                        "name": "access_token",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
+                        "description": "Description",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WaEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/auth/media": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Store a file in the media library, e.g. to be sent by the auto-reply rules with its id.\nThe size limit is MEDIA_MAX_SIZE_MB, or MEDIA_MAX_SIZE_MB_UPLOAD when set.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Message"
+                ],
+                "summary": "upload media",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "File",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
                         "description": "Description",
                         "schema": {
                             "allOf": [
@@ -63,7 +140,7 @@ var doc = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/domain.WaGroup"
+                                            "$ref": "#/definitions/domain.WaMedia"
                                         },
                                         "message": {
                                             "type": "string"
@@ -79,21 +156,12 @@ var doc = `{
                             "$ref": "#/definitions/domain.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "413": {
+                        "description": "Request Entity Too Large",
                         "schema": {
                             "$ref": "#/definitions/domain.HTTPError"
                         }
                     },
-                    "422": {
-                        "description": "Unprocessable Entity",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.HTTPErrorValidation"
-                            }
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -103,16 +171,21 @@ var doc = `{
                 }
             }
         },
-        "/v1/whatsapp/info": {
+        "/v1/auth/webhooks": {
             "get": {
-                "description": "Get info metadata.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the webhook subscriptions.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Info"
+                    "Webhook"
                 ],
-                "summary": "get info metadata",
+                "summary": "list webhooks",
                 "responses": {
                     "200": {
                         "description": "Description",
@@ -125,7 +198,10 @@ var doc = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/domain.WaWeb"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.Webhook"
+                                            }
                                         },
                                         "message": {
                                             "type": "string"
@@ -135,14 +211,66 @@ var doc = `{
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/domain.HTTPError"
                         }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Subscribe an URL to the events of the sessions. The events are posted as JSON, the body is signed with the secret\nusing HMAC-SHA256 and the hex signature sent in the X-Webhook-Signature header as \"sha256=\u003csignature\u003e\".\nA secret is generated when none is given, it is only answered here.\nDeliveries failing or answered with a non 2xx status are retried with an exponential backoff.\nEmpty event_types, jids or session_id match every event. Event types: message.text, message.image, message.document,\nmessage.audio, message.video, message.contact, message.location, battery, json and connection.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "create webhook",
+                "parameters": [
+                    {
+                        "description": "Webhook",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.WebhookForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.WebhookCreated"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/domain.HTTPError"
                         }
@@ -165,89 +293,72 @@ var doc = `{
                 }
             }
         },
-        "/v1/whatsapp/login": {
-            "post": {
-                "description": "Login to whatsapp web by scanning a QR Code.",
-                "consumes": [
-                    "multipart/form-data"
+        "/v1/auth/webhooks/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "List the delivery log, newest first. Under /v1/webhooks/{id}/deliveries only the deliveries of that webhook are listed.",
                 "produces": [
-                    "image/png"
+                    "application/json"
                 ],
                 "tags": [
-                    "Whatsapp"
+                    "Webhook"
                 ],
-                "summary": "login whatsapp web",
+                "summary": "list webhook deliveries",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Reconnect, default to 50",
-                        "name": "reconnect",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "QR Scan timeout in second, default 20",
-                        "name": "timeout",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Long client name, default: Go Whatsapp REST Api Fiber",
-                        "name": "client_name_long",
-                        "in": "formData"
-                    },
-                    {
+                        "enum": [
+                            "pending",
+                            "success",
+                            "failed"
+                        ],
                         "type": "string",
-                        "description": "Short client name, default: Go Whatsapp",
-                        "name": "client_name_short",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Whatsapp Client major version, default: 2",
-                        "name": "client_version_major",
-                        "in": "formData"
+                        "description": "Delivery status",
+                        "name": "status",
+                        "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Whatsapp Client minor version, default: 2126",
-                        "name": "client_version_minor",
-                        "in": "formData"
+                        "description": "Page, default 1",
+                        "name": "page",
+                        "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Whatsapp Client build version, default: 11",
-                        "name": "client_version_build",
-                        "in": "formData"
+                        "description": "Deliveries per page, default 20, max 100",
+                        "name": "per_page",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "Description",
                         "schema": {
-                            "type": "file"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/domain.HTTPError"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/domain.HTTPError"
-                        }
-                    },
-                    "422": {
-                        "description": "Unprocessable Entity",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.HTTPErrorValidation"
-                            }
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.WebhookDelivery"
+                                            }
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/domain.JSONResultMeta"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "500": {
@@ -259,16 +370,30 @@ var doc = `{
                 }
             }
         },
-        "/v1/whatsapp/logout": {
+        "/v1/auth/webhooks/deliveries/{delivery_id}/redeliver": {
             "post": {
-                "description": "Logout from whatsapp web.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Queue a delivery again, e.g. a failed one once the receiver is fixed.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Whatsapp"
+                    "Webhook"
+                ],
+                "summary": "redeliver webhook delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "delivery_id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "logout whatsapp web",
                 "responses": {
                     "200": {
                         "description": "Description",
@@ -281,7 +406,7 @@ var doc = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "string"
+                                            "$ref": "#/definitions/domain.WebhookDelivery"
                                         },
                                         "message": {
                                             "type": "string"
@@ -291,27 +416,12 @@ var doc = `{
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/domain.HTTPError"
-                        }
-                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/domain.HTTPError"
                         }
                     },
-                    "422": {
-                        "description": "Unprocessable Entity",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.HTTPErrorValidation"
-                            }
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -321,51 +431,28 @@ var doc = `{
                 }
             }
         },
-        "/v1/whatsapp/send-audio": {
-            "post": {
-                "description": "Send audio message.",
-                "consumes": [
-                    "application/x-www-form-urlencoded"
+        "/v1/auth/webhooks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Get a webhook subscription.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Messaging"
+                    "Webhook"
                 ],
-                "summary": "send audio message",
+                "summary": "get webhook",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Destination number. eg: 6281255423 or group_creator-timstamp_created -\u003e 6281271471566-1619679643 for group",
-                        "name": "msisdn",
-                        "in": "formData",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "file",
-                        "description": "Audio file",
-                        "name": "audio_file",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Message Quoted ID",
-                        "name": "msg_quoted_id",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Message Quoted",
-                        "name": "msg_quoted",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Message to include",
-                        "name": "message",
-                        "in": "formData"
                     }
                 ],
                 "responses": {
@@ -380,7 +467,7 @@ var doc = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object"
+                                            "$ref": "#/definitions/domain.Webhook"
                                         },
                                         "message": {
                                             "type": "string"
@@ -390,27 +477,12 @@ var doc = `{
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/domain.HTTPError"
-                        }
-                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/domain.HTTPError"
                         }
                     },
-                    "422": {
-                        "description": "Unprocessable Entity",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.HTTPErrorValidation"
-                            }
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -418,53 +490,40 @@ var doc = `{
                         }
                     }
                 }
-            }
-        },
-        "/v1/whatsapp/send-document": {
-            "post": {
-                "description": "Send document message.",
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replace the url, secret and filters of a webhook, the secret and active are kept when omitted.",
                 "consumes": [
-                    "application/x-www-form-urlencoded"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Messaging"
+                    "Webhook"
                 ],
-                "summary": "send document message",
+                "summary": "update webhook",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Destination number. eg: 6281255423 or group_creator-timstamp_created -\u003e 6281271471566-1619679643 for group",
-                        "name": "msisdn",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "file",
-                        "description": "Document file",
-                        "name": "document_file",
-                        "in": "formData",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Message Quoted ID",
-                        "name": "msg_quoted_id",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Message Quoted",
-                        "name": "msg_quoted",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Message to include",
-                        "name": "message",
-                        "in": "formData"
+                        "description": "Webhook",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.WebhookForm"
+                        }
                     }
                 ],
                 "responses": {
@@ -479,7 +538,7 @@ var doc = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object"
+                                            "$ref": "#/definitions/domain.Webhook"
                                         },
                                         "message": {
                                             "type": "string"
@@ -517,53 +576,28 @@ var doc = `{
                         }
                     }
                 }
-            }
-        },
-        "/v1/whatsapp/send-image": {
-            "post": {
-                "description": "Send image message.",
-                "consumes": [
-                    "application/x-www-form-urlencoded"
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Delete a webhook subscription and its delivery log.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Messaging"
+                    "Webhook"
                 ],
-                "summary": "send image message",
+                "summary": "delete webhook",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Destination number. eg: 6281255423 or group_creator-timstamp_created -\u003e 6281271471566-1619679643 for group",
-                        "name": "msisdn",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "file",
-                        "description": "Image file",
-                        "name": "image_file",
-                        "in": "formData",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Message Quoted ID",
-                        "name": "msg_quoted_id",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Message Quoted",
-                        "name": "msg_quoted",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Message to include",
-                        "name": "message",
-                        "in": "formData"
                     }
                 ],
                 "responses": {
@@ -588,25 +622,54 @@ var doc = `{
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/domain.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/domain.HTTPError"
                         }
-                    },
-                    "422": {
-                        "description": "Unprocessable Entity",
+                    }
+                }
+            }
+        },
+        "/v1/auto-replies": {
+            "get": {
+                "description": "List the auto-reply rules by priority.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auto Reply"
+                ],
+                "summary": "list auto-reply rules",
+                "responses": {
+                    "200": {
+                        "description": "Description",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.HTTPErrorValidation"
-                            }
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.AutoReplyRule"
+                                            }
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "500": {
@@ -616,56 +679,32 @@ var doc = `{
                         }
                     }
                 }
-            }
-        },
-        "/v1/whatsapp/send-location": {
+            },
             "post": {
-                "description": "Send location message.",
+                "description": "Answer the received text messages matching the pattern: exact (whole text), contains or regex. The first active rule\nmatching, lowest priority first, answers. Empty jids match every chat, a jid matches the chat or the sender in groups.\ntime_start and time_end (HH:MM) and days (0 is Sunday) limit when the rule answers, in timezone (default to UTC).\ncooldown_seconds is the time a contact waits before the rule answers it again.\nThe response is a text, a location or a media of the media library (image, document, audio, video) with text as caption.",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Messaging"
+                    "Auto Reply"
                 ],
-                "summary": "send location message",
+                "summary": "create auto-reply rule",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Destination number. eg: 6281255423 or group_creator-timstamp_created -\u003e 6281271471566-1619679643 for group",
-                        "name": "msisdn",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "number",
-                        "description": "Latitude. eg: -5.3836767",
-                        "name": "latitude",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "number",
-                        "description": "Longitude. eg: 105.2937439",
-                        "name": "longitude",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Message Quoted ID",
-                        "name": "msg_quoted_id",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Message Quoted",
-                        "name": "msg_quoted",
-                        "in": "formData"
+                        "description": "Rule",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.AutoReplyRuleForm"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
+                    "201": {
                         "description": "Description",
                         "schema": {
                             "allOf": [
@@ -676,7 +715,7 @@ var doc = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object"
+                                            "$ref": "#/definitions/domain.AutoReplyRule"
                                         },
                                         "message": {
                                             "type": "string"
@@ -692,12 +731,6 @@ var doc = `{
                             "$ref": "#/definitions/domain.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/domain.HTTPError"
-                        }
-                    },
                     "422": {
                         "description": "Unprocessable Entity",
                         "schema": {
@@ -716,45 +749,23 @@ var doc = `{
                 }
             }
         },
-        "/v1/whatsapp/send-text": {
-            "post": {
-                "description": "Send text message.",
-                "consumes": [
-                    "multipart/form-data"
-                ],
+        "/v1/auto-replies/{id}": {
+            "get": {
+                "description": "Get an auto-reply rule.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Messaging"
+                    "Auto Reply"
                 ],
-                "summary": "send text message",
+                "summary": "get auto-reply rule",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Destination number. eg: 6281255423 or group_creator-timstamp_created -\u003e 6281271471566-1619679643 for group",
-                        "name": "msisdn",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Message text",
-                        "name": "text",
-                        "in": "formData",
+                        "description": "Rule ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Message Quoted ID",
-                        "name": "msg_quoted_id",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Message Quoted",
-                        "name": "msg_quoted",
-                        "in": "formData"
                     }
                 ],
                 "responses": {
@@ -769,7 +780,7 @@ var doc = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object"
+                                            "$ref": "#/definitions/domain.AutoReplyRule"
                                         },
                                         "message": {
                                             "type": "string"
@@ -779,27 +790,12 @@ var doc = `{
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/domain.HTTPError"
-                        }
-                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/domain.HTTPError"
                         }
                     },
-                    "422": {
-                        "description": "Unprocessable Entity",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.HTTPErrorValidation"
-                            }
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -807,53 +803,104 @@ var doc = `{
                         }
                     }
                 }
-            }
-        },
-        "/v1/whatsapp/send-video": {
-            "post": {
-                "description": "Send video message.",
+            },
+            "put": {
+                "description": "Replace an auto-reply rule, active is kept when omitted.",
                 "consumes": [
-                    "application/x-www-form-urlencoded"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Messaging"
+                    "Auto Reply"
                 ],
-                "summary": "send video message",
+                "summary": "update auto-reply rule",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Destination number. eg: 6281255423 or group_creator-timstamp_created -\u003e 6281271471566-1619679643 for group",
-                        "name": "msisdn",
-                        "in": "formData",
+                        "description": "Rule ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "type": "file",
-                        "description": "Video file",
-                        "name": "video_file",
-                        "in": "formData",
-                        "required": true
+                        "description": "Rule",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.AutoReplyRuleForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.AutoReplyRule"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "Message Quoted ID",
-                        "name": "msg_quoted_id",
-                        "in": "formData"
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "Message Quoted",
-                        "name": "msg_quoted",
-                        "in": "formData"
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.HTTPErrorValidation"
+                            }
+                        }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete an auto-reply rule.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auto Reply"
+                ],
+                "summary": "delete auto-reply rule",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "Message to include",
-                        "name": "message",
-                        "in": "formData"
+                        "description": "Rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -878,14 +925,112 @@ var doc = `{
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/domain.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/flows": {
+            "get": {
+                "description": "List the conversational flows by priority.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Flow"
+                ],
+                "summary": "list flows",
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.Flow"
+                                            }
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a multi-step conversation, sent as JSON or as YAML with a yaml content type (e.g. application/x-yaml).\nA received text matching the trigger starts the flow at the start state for the contact. Entering a state sends its\nprompt, the answer must match validate, is saved in the variable named save and moves the conversation to the next\nstate of the first matching branch, or to next. A state without branches and next ends the conversation.\nPrompts can use {{variable}}, push_name is set when the flow starts. A contact not answering in timeout_seconds\nmoves to timeout_next, or leaves the flow. While a contact is in a flow its messages are not auto-replied.",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Flow"
+                ],
+                "summary": "create flow",
+                "parameters": [
+                    {
+                        "description": "Flow",
+                        "name": "flow",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.FlowForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.Flow"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/domain.HTTPError"
                         }
@@ -907,104 +1052,5869 @@ var doc = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "domain.HTTPError": {
+        },
+        "/v1/flows/conversations/{conversation_id}": {
+            "delete": {
+                "description": "Take a contact out of its flow, its next message can trigger a flow again.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Flow"
+                ],
+                "summary": "end flow conversation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Conversation ID",
+                        "name": "conversation_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/flows/{id}": {
+            "get": {
+                "description": "Get a flow.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Flow"
+                ],
+                "summary": "get flow",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Flow ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.Flow"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace a flow, as JSON or YAML, active is kept when omitted. The conversations in a removed state leave the flow.",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Flow"
+                ],
+                "summary": "update flow",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Flow ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Flow",
+                        "name": "flow",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.FlowForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.Flow"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.HTTPErrorValidation"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a flow and end its conversations.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Flow"
+                ],
+                "summary": "delete flow",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Flow ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/flows/{id}/conversations": {
+            "get": {
+                "description": "List the contacts in a flow, their state and saved variables.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Flow"
+                ],
+                "summary": "list flow conversations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Flow ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.FlowConversation"
+                                            }
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/media/{id}": {
+            "get": {
+                "description": "Download the media of a received message, the media id is the media_id of the message.\nImages, audios and videos are served inline, add download=true to get them as an attachment.\nThe other media are always served as an attachment.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Message"
+                ],
+                "summary": "download media",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Media ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Serve as an attachment",
+                        "name": "download",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/templates": {
+            "get": {
+                "description": "List the message templates by name.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Template"
+                ],
+                "summary": "list templates",
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.Template"
+                                            }
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Store a message text with {{variable}} placeholders. defaults are the values of the variables not given\nwhen it is rendered, variants the text in other languages by language code, eg: {\"id\": \"Halo {{name}}\"}.\nA template is sent with the template_id and variables of the send text and send file endpoints.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Template"
+                ],
+                "summary": "create template",
+                "parameters": [
+                    {
+                        "description": "Template",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.TemplateForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.Template"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.HTTPErrorValidation"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/templates/{id}": {
+            "get": {
+                "description": "Get a message template with its placeholders.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Template"
+                ],
+                "summary": "get template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.Template"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace a message template.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Template"
+                ],
+                "summary": "update template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Template",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.TemplateForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.Template"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.HTTPErrorValidation"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a message template.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Template"
+                ],
+                "summary": "delete template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/templates/{id}/render": {
+            "post": {
+                "description": "Render a template with the variables, in the language variant when there is one, without sending it.\nA placeholder with neither a variable nor a default fails.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Template"
+                ],
+                "summary": "render template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Variables",
+                        "name": "render",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.TemplateRenderForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.TemplateRender"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/campaigns": {
+            "get": {
+                "description": "List the broadcast campaigns of the session, newest first, with their progress.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Campaign"
+                ],
+                "summary": "list campaigns",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page, default 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Campaigns per page, default 20, max 100",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.Campaign"
+                                            }
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/domain.JSONResultMeta"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Broadcast a message to the recipients of a CSV file. The CSV header names the columns, msisdn is required\nand every column is a variable: {{name}} in the text is replaced with the name column of the recipient.\nA media campaign sends the uploaded file, or the media_id of the media library, with the text as caption.\nThe campaign starts at scheduled_at, right away without it, and its messages are sent one at a time\nwithin the rate limits of the session.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Campaign"
+                ],
+                "summary": "create campaign",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Campaign name",
+                        "name": "name",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "text",
+                            "image",
+                            "document",
+                            "audio",
+                            "video"
+                        ],
+                        "type": "string",
+                        "description": "Message type",
+                        "name": "type",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message text or caption, eg: Hello {{name}}, your order {{order}} is ready",
+                        "name": "text",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Recipients CSV",
+                        "name": "recipients",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Media file",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Media library ID, instead of the file",
+                        "name": "media_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start time, RFC3339, eg: 2021-07-01T09:00:00+07:00",
+                        "name": "scheduled_at",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.Campaign"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.HTTPErrorValidation"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/campaigns/{id}": {
+            "get": {
+                "description": "Get a campaign with its progress: the recipients queued, sent, failed and cancelled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Campaign"
+                ],
+                "summary": "get campaign",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Campaign ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.Campaign"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/campaigns/{id}/cancel": {
+            "post": {
+                "description": "Cancel a campaign, the recipients not sent yet are cancelled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Campaign"
+                ],
+                "summary": "cancel campaign",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Campaign ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.Campaign"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/campaigns/{id}/pause": {
+            "post": {
+                "description": "Pause a running or scheduled campaign, the message being sent is still sent.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Campaign"
+                ],
+                "summary": "pause campaign",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Campaign ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.Campaign"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/campaigns/{id}/recipients": {
+            "get": {
+                "description": "List the recipients of a campaign in the CSV order, with the status, message id or error of their message.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Campaign"
+                ],
+                "summary": "list campaign recipients",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Campaign ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "queued",
+                            "sending",
+                            "sent",
+                            "failed",
+                            "cancelled"
+                        ],
+                        "type": "string",
+                        "description": "Recipient status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page, default 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Recipients per page, default 20, max 100",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.CampaignRecipient"
+                                            }
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/domain.JSONResultMeta"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/campaigns/{id}/report": {
+            "get": {
+                "description": "Export the result of every recipient as CSV: row, msisdn, status, message_id, error and sent_at.",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "Campaign"
+                ],
+                "summary": "export campaign report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Campaign ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/campaigns/{id}/resume": {
+            "post": {
+                "description": "Resume a paused campaign, it waits for its scheduled_at when it is not reached yet.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Campaign"
+                ],
+                "summary": "resume campaign",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Campaign ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.Campaign"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/chats": {
+            "get": {
+                "description": "List the chats of the phone, pinned first then by last message, with their unread count, archived and muted flags\nand the last message stored by the service.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "list chats",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search in the jid and the name",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only the archived chats, or only the others",
+                        "name": "archived",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only the chats with unread messages",
+                        "name": "unread",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page, default 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Chats per page, default 20, max 100",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.WaChat"
+                                            }
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/domain.JSONResultMeta"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/chats/{jid}/messages": {
+            "get": {
+                "description": "Load the history of a chat from the phone, newest first, in the same shape as the stored messages and the webhooks.\nPage backwards by passing meta.before as the before of the next request.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "chat history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JID or msisdn, eg: 6281234567890@s.whatsapp.net",
+                        "name": "jid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Load the messages sent before this message ID, default to the latest messages",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Messages to load, default 20, max 100",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.WaMessage"
+                                            }
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/domain.WaChatHistoryMeta"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/chats/{jid}/read": {
+            "post": {
+                "description": "Mark a chat as read on the phone, up to the given message or up to its latest message.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "mark chat as read",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JID or msisdn, eg: 6281234567890@s.whatsapp.net",
+                        "name": "jid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Mark as read up to this message ID, default to the latest received message of the chat",
+                        "name": "message_id",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/chats/{jid}/state": {
+            "post": {
+                "description": "Show the chat that the session is typing (composing), recording an audio or stopped (paused).",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "send chat state",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JID or msisdn, eg: 6281234567890@s.whatsapp.net",
+                        "name": "jid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "composing",
+                            "recording",
+                            "paused"
+                        ],
+                        "type": "string",
+                        "description": "Chat state",
+                        "name": "state",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/connection": {
+            "get": {
+                "description": "Get the connection state kept by the session supervisor: connected, reconnecting, logged-out or disconnected.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Info"
+                ],
+                "summary": "get connection state",
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.WaConnectionStatus"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/contacts": {
+            "get": {
+                "description": "List the contacts and groups known by the session, by display name. The directory is filled from the phone contacts,\nthe chats and the push names of the messages received.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Contact"
+                ],
+                "summary": "list contacts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search in the jid and the names",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only the business accounts, or only the others",
+                        "name": "business",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page, default 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Contacts per page, default 20, max 100",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.WaContact"
+                                            }
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/domain.JSONResultMeta"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/contacts/{jid}": {
+            "get": {
+                "description": "Get a contact, or a group, by jid or msisdn.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Contact"
+                ],
+                "summary": "get contact",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JID or msisdn, eg: 6281234567890@s.whatsapp.net",
+                        "name": "jid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.WaContact"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/groups/{jid}": {
+            "get": {
+                "description": "Get group metadata by phone number.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Info"
+                ],
+                "summary": "get group metadata",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JID. eg: group_creator-timstamp_created -\u003e 6281271471566-1619679643",
+                        "name": "jid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.WaGroup"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.HTTPErrorValidation"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/info": {
+            "get": {
+                "description": "Get info metadata.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Info"
+                ],
+                "summary": "get info metadata",
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.WaWeb"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.HTTPErrorValidation"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/jobs": {
+            "get": {
+                "description": "List the messages queued with async=true by the session, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Send Queue"
+                ],
+                "summary": "list send jobs",
+                "parameters": [
+                    {
+                        "enum": [
+                            "queued",
+                            "sending",
+                            "sent",
+                            "failed",
+                            "cancelled"
+                        ],
+                        "type": "string",
+                        "description": "Job status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page, default 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jobs per page, default 20, max 100",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.WaSendJob"
+                                            }
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/domain.JSONResultMeta"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/jobs/{id}": {
+            "get": {
+                "description": "Get a queued message with the error of each send attempt. A sent job holds the message_id of the message.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Send Queue"
+                ],
+                "summary": "get send job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.WaSendJob"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/jobs/{id}/cancel": {
+            "post": {
+                "description": "Cancel a queued message, a message being sent or already sent can not be cancelled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Send Queue"
+                ],
+                "summary": "cancel send job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.WaSendJob"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/jobs/{id}/retry": {
+            "post": {
+                "description": "Queue a failed or cancelled message again, with a new set of attempts.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Send Queue"
+                ],
+                "summary": "retry send job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.WaSendJob"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/login": {
+            "post": {
+                "description": "Login to whatsapp web by scanning a QR Code. The response is the first QR code, the login attempt keeps\nrequesting a new QR code when the previous one expires until it is scanned or login_timeout is reached.\nThe attempt id is returned in the X-Login-Attempt-Id header, use it to follow the attempt and receive the new QR codes.\nThe QR code format is chosen with the format field or, without it, from the Accept header\n(image/png, application/json, image/svg+xml or text/plain for the terminal form). The json and base64\nformats return a domain.WaLoginQrCode, base64 holds the PNG as a data URI.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "image/png",
+                    "application/json",
+                    "image/svg+xml",
+                    "text/plain"
+                ],
+                "tags": [
+                    "Whatsapp"
+                ],
+                "summary": "login whatsapp web",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Maximum reconnect attempts after the connection is lost, default to 50",
+                        "name": "reconnect",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "QR Scan timeout in second, default 20",
+                        "name": "timeout",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Seconds the QR code is refreshed before the attempt times out, default 120",
+                        "name": "login_timeout",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "png",
+                            "base64",
+                            "json",
+                            "svg",
+                            "terminal"
+                        ],
+                        "type": "string",
+                        "description": "QR code format: png, base64, json, svg or terminal",
+                        "name": "format",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Image size in pixel for png, base64 and svg, default 256",
+                        "name": "size",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "low",
+                            "medium",
+                            "high",
+                            "highest"
+                        ],
+                        "type": "string",
+                        "description": "QR code recovery level, default medium",
+                        "name": "recovery_level",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Long client name, default: Go Whatsapp REST Api Fiber",
+                        "name": "client_name_long",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Short client name, default: Go Whatsapp",
+                        "name": "client_name_short",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Whatsapp Client major version, default: 2",
+                        "name": "client_version_major",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Whatsapp Client minor version, default: 2126",
+                        "name": "client_version_minor",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Whatsapp Client build version, default: 11",
+                        "name": "client_version_build",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "type": "file"
+                        },
+                        "headers": {
+                            "X-Login-Attempt-Id": {
+                                "type": "string",
+                                "description": "Login attempt ID"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "A new session past WHATSAPP_MAX_SESSIONS",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.HTTPErrorValidation"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/login/{attempt_id}": {
+            "get": {
+                "description": "Get the status of a login attempt: pending, success, timeout or failed. A pending attempt\nholds the current QR code, a successful one holds the session JID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Whatsapp"
+                ],
+                "summary": "get login attempt",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Login attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.WaLoginAttempt"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/login/{attempt_id}/events": {
+            "get": {
+                "description": "Stream the login attempt as Server-Sent Events. Every event is a \"login\" event holding the attempt,\na new one is sent each time the QR code is refreshed or the status changes. The stream ends with the attempt.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Whatsapp"
+                ],
+                "summary": "stream login attempt",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Login attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WaLoginAttempt"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/login/{attempt_id}/qr": {
+            "get": {
+                "description": "Get the current QR code of a pending login attempt, in the same formats as login.",
+                "produces": [
+                    "image/png",
+                    "application/json",
+                    "image/svg+xml",
+                    "text/plain"
+                ],
+                "tags": [
+                    "Whatsapp"
+                ],
+                "summary": "get login QR code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Login attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "png",
+                            "base64",
+                            "json",
+                            "svg",
+                            "terminal"
+                        ],
+                        "type": "string",
+                        "description": "QR code format: png, base64, json, svg or terminal",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Image size in pixel for png, base64 and svg, default 256",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "low",
+                            "medium",
+                            "high",
+                            "highest"
+                        ],
+                        "type": "string",
+                        "description": "QR code recovery level, default medium",
+                        "name": "recovery_level",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/login/{attempt_id}/ws": {
+            "get": {
+                "description": "Same as the login events stream, over a WebSocket. Each text message is the attempt as JSON.",
+                "tags": [
+                    "Whatsapp"
+                ],
+                "summary": "stream login attempt over websocket",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Login attempt ID",
+                        "name": "attempt_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Description",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WaLoginAttempt"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "426": {
+                        "description": "Upgrade Required",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/logout": {
+            "post": {
+                "description": "Logout from whatsapp web.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Whatsapp"
+                ],
+                "summary": "logout whatsapp web",
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.HTTPErrorValidation"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/messages": {
+            "get": {
+                "description": "List the messages sent and received by the session, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Message"
+                ],
+                "summary": "list messages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chat JID, eg: 6281234567890@s.whatsapp.net",
+                        "name": "jid",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "inbound",
+                            "outbound"
+                        ],
+                        "type": "string",
+                        "description": "Direction",
+                        "name": "direction",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "text",
+                            "image",
+                            "document",
+                            "audio",
+                            "video",
+                            "location",
+                            "contact"
+                        ],
+                        "type": "string",
+                        "description": "Message type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Oldest message time, RFC3339, eg: 2021-07-01T00:00:00+07:00",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Newest message time, RFC3339",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search in the text, caption, file name and contact name",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page, default 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Messages per page, default 20, max 100",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.WaMessage"
+                                            }
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/domain.JSONResultMeta"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/messages/{id}": {
+            "get": {
+                "description": "Get a message sent or received by the session.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Message"
+                ],
+                "summary": "get message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.WaMessage"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/messages/{id}/status": {
+            "get": {
+                "description": "Get the delivery status of a message sent by the session: pending, server-ack, delivered, read or played,\nwith the time each status was reached. Every change is published as a message.status event.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Message"
+                ],
+                "summary": "get message status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.WaMessageStatus"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/presence": {
+            "post": {
+                "description": "Set the presence of the session seen by its contacts: available (online) or unavailable.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "set presence",
+                "parameters": [
+                    {
+                        "enum": [
+                            "available",
+                            "unavailable"
+                        ],
+                        "type": "string",
+                        "description": "Presence",
+                        "name": "presence",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/schedules": {
+            "get": {
+                "description": "List the messages scheduled with send_at or cron by the session, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "list schedules",
+                "parameters": [
+                    {
+                        "enum": [
+                            "active",
+                            "completed",
+                            "cancelled"
+                        ],
+                        "type": "string",
+                        "description": "Schedule status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page, default 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedules per page, default 20, max 100",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.WaSchedule"
+                                            }
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/domain.JSONResultMeta"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/schedules/{id}": {
+            "get": {
+                "description": "Get a scheduled message with its next run. Each run queues the message, last_job_id is the send job of the last run.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "get schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.WaSchedule"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/schedules/{id}/cancel": {
+            "post": {
+                "description": "Cancel an active schedule, the messages already queued by its runs are not cancelled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "cancel schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.WaSchedule"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/send-audio": {
+            "post": {
+                "description": "Send audio message.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Messaging"
+                ],
+                "summary": "send audio message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Destination number. eg: 6281255423 or group_creator-timstamp_created -\u003e 6281271471566-1619679643 for group",
+                        "name": "msisdn",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Audio file",
+                        "name": "audio_file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message Quoted ID",
+                        "name": "msg_quoted_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message Quoted",
+                        "name": "msg_quoted",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message to include",
+                        "name": "message",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template ID, sends the rendered template instead of the message",
+                        "name": "template_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template variables, JSON object. eg: {\\",
+                        "name": "variables",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template language variant. eg: id",
+                        "name": "language",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Answer a retry with this key with the response of the first request, instead of sending the message again",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Queue the message and return the send job instead of waiting for it to be sent",
+                        "name": "async",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Send the message at this time, RFC3339 or YYYY-MM-DD HH:MM in the timezone, the response is the schedule",
+                        "name": "send_at",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Send the message at every time of this cron expression, eg: 0 9 * * MON, the response is the schedule",
+                        "name": "cron",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timezone of send_at and cron, default UTC. eg: Asia/Jakarta",
+                        "name": "timezone",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "skip",
+                            "once",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "Runs missed during a downtime: skip them, send once or send all, default to SCHEDULE_CATCH_UP",
+                        "name": "catch_up",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "202": {
+                        "description": "Queued when async is true",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.WaSendJob"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency-Key already used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.HTTPErrorValidation"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Over the rate limit, retry after the Retry-After header seconds",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/send-contact": {
+            "post": {
+                "description": "Send a contact card. The vCard 3.0 is built from the name, phones, emails and organization, or read from\nthe uploaded vcard_file instead. A vcard_file holding several cards sends them all in one message.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Messaging"
+                ],
+                "summary": "send contact message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Destination number. eg: 6281255423 or group_creator-timstamp_created -\u003e 6281271471566-1619679643 for group",
+                        "name": "msisdn",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Contact name, required without vcard_file",
+                        "name": "name",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Contact phone numbers, at least one without vcard_file. eg: 6281234567890",
+                        "name": "phones",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Contact emails",
+                        "name": "emails",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Contact organization",
+                        "name": "organization",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "vCard file, instead of the contact fields",
+                        "name": "vcard_file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message Quoted ID",
+                        "name": "msg_quoted_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message Quoted",
+                        "name": "msg_quoted",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Answer a retry with this key with the response of the first request, instead of sending the message again",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Queue the message and return the send job instead of waiting for it to be sent",
+                        "name": "async",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Send the message at this time, RFC3339 or YYYY-MM-DD HH:MM in the timezone, the response is the schedule",
+                        "name": "send_at",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Send the message at every time of this cron expression, eg: 0 9 * * MON, the response is the schedule",
+                        "name": "cron",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timezone of send_at and cron, default UTC. eg: Asia/Jakarta",
+                        "name": "timezone",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "skip",
+                            "once",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "Runs missed during a downtime: skip them, send once or send all, default to SCHEDULE_CATCH_UP",
+                        "name": "catch_up",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "202": {
+                        "description": "Queued when async is true",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.WaSendJob"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency-Key already used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.HTTPErrorValidation"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Over the rate limit, retry after the Retry-After header seconds",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/send-contacts": {
+            "post": {
+                "description": "Send several contact cards at once, in a single message. contacts is a JSON array of\n{\"name\", \"phones\", \"emails\", \"organization\"} or {\"vcard\"} objects, the cards of the uploaded vcard_file are added to them.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Messaging"
+                ],
+                "summary": "send contacts message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Destination number. eg: 6281255423 or group_creator-timstamp_created -\u003e 6281271471566-1619679643 for group",
+                        "name": "msisdn",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Contacts, JSON array. eg: [{\\",
+                        "name": "contacts",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "vCard file holding one or several cards",
+                        "name": "vcard_file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message Quoted ID",
+                        "name": "msg_quoted_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message Quoted",
+                        "name": "msg_quoted",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Answer a retry with this key with the response of the first request, instead of sending the message again",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Queue the message and return the send job instead of waiting for it to be sent",
+                        "name": "async",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Send the message at this time, RFC3339 or YYYY-MM-DD HH:MM in the timezone, the response is the schedule",
+                        "name": "send_at",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Send the message at every time of this cron expression, eg: 0 9 * * MON, the response is the schedule",
+                        "name": "cron",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timezone of send_at and cron, default UTC. eg: Asia/Jakarta",
+                        "name": "timezone",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "skip",
+                            "once",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "Runs missed during a downtime: skip them, send once or send all, default to SCHEDULE_CATCH_UP",
+                        "name": "catch_up",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "202": {
+                        "description": "Queued when async is true",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.WaSendJob"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency-Key already used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.HTTPErrorValidation"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Over the rate limit, retry after the Retry-After header seconds",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/send-document": {
+            "post": {
+                "description": "Send document message.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Messaging"
+                ],
+                "summary": "send document message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Destination number. eg: 6281255423 or group_creator-timstamp_created -\u003e 6281271471566-1619679643 for group",
+                        "name": "msisdn",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Document file",
+                        "name": "document_file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message Quoted ID",
+                        "name": "msg_quoted_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message Quoted",
+                        "name": "msg_quoted",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message to include",
+                        "name": "message",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template ID, sends the rendered template instead of the message",
+                        "name": "template_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template variables, JSON object. eg: {\\",
+                        "name": "variables",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template language variant. eg: id",
+                        "name": "language",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Answer a retry with this key with the response of the first request, instead of sending the message again",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Queue the message and return the send job instead of waiting for it to be sent",
+                        "name": "async",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Send the message at this time, RFC3339 or YYYY-MM-DD HH:MM in the timezone, the response is the schedule",
+                        "name": "send_at",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Send the message at every time of this cron expression, eg: 0 9 * * MON, the response is the schedule",
+                        "name": "cron",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timezone of send_at and cron, default UTC. eg: Asia/Jakarta",
+                        "name": "timezone",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "skip",
+                            "once",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "Runs missed during a downtime: skip them, send once or send all, default to SCHEDULE_CATCH_UP",
+                        "name": "catch_up",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "202": {
+                        "description": "Queued when async is true",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.WaSendJob"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency-Key already used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.HTTPErrorValidation"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Over the rate limit, retry after the Retry-After header seconds",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/send-image": {
+            "post": {
+                "description": "Send image message.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Messaging"
+                ],
+                "summary": "send image message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Destination number. eg: 6281255423 or group_creator-timstamp_created -\u003e 6281271471566-1619679643 for group",
+                        "name": "msisdn",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Image file",
+                        "name": "image_file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message Quoted ID",
+                        "name": "msg_quoted_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message Quoted",
+                        "name": "msg_quoted",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message to include",
+                        "name": "message",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template ID, sends the rendered template instead of the message",
+                        "name": "template_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template variables, JSON object. eg: {\\",
+                        "name": "variables",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template language variant. eg: id",
+                        "name": "language",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Answer a retry with this key with the response of the first request, instead of sending the message again",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Queue the message and return the send job instead of waiting for it to be sent",
+                        "name": "async",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Send the message at this time, RFC3339 or YYYY-MM-DD HH:MM in the timezone, the response is the schedule",
+                        "name": "send_at",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Send the message at every time of this cron expression, eg: 0 9 * * MON, the response is the schedule",
+                        "name": "cron",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timezone of send_at and cron, default UTC. eg: Asia/Jakarta",
+                        "name": "timezone",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "skip",
+                            "once",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "Runs missed during a downtime: skip them, send once or send all, default to SCHEDULE_CATCH_UP",
+                        "name": "catch_up",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "202": {
+                        "description": "Queued when async is true",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.WaSendJob"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency-Key already used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.HTTPErrorValidation"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Over the rate limit, retry after the Retry-After header seconds",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/send-location": {
+            "post": {
+                "description": "Send location message.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Messaging"
+                ],
+                "summary": "send location message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Destination number. eg: 6281255423 or group_creator-timstamp_created -\u003e 6281271471566-1619679643 for group",
+                        "name": "msisdn",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Latitude. eg: -5.3836767",
+                        "name": "latitude",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Longitude. eg: 105.2937439",
+                        "name": "longitude",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message Quoted ID",
+                        "name": "msg_quoted_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message Quoted",
+                        "name": "msg_quoted",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Answer a retry with this key with the response of the first request, instead of sending the message again",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Queue the message and return the send job instead of waiting for it to be sent",
+                        "name": "async",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Send the message at this time, RFC3339 or YYYY-MM-DD HH:MM in the timezone, the response is the schedule",
+                        "name": "send_at",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Send the message at every time of this cron expression, eg: 0 9 * * MON, the response is the schedule",
+                        "name": "cron",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timezone of send_at and cron, default UTC. eg: Asia/Jakarta",
+                        "name": "timezone",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "skip",
+                            "once",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "Runs missed during a downtime: skip them, send once or send all, default to SCHEDULE_CATCH_UP",
+                        "name": "catch_up",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "202": {
+                        "description": "Queued when async is true",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.WaSendJob"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency-Key already used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.HTTPErrorValidation"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Over the rate limit, retry after the Retry-After header seconds",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/send-text": {
+            "post": {
+                "description": "Send text message.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Messaging"
+                ],
+                "summary": "send text message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Destination number. eg: 6281255423 or group_creator-timstamp_created -\u003e 6281271471566-1619679643 for group",
+                        "name": "msisdn",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message text, required without template_id",
+                        "name": "text",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template ID, sends the rendered template instead of the text",
+                        "name": "template_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template variables, JSON object. eg: {\\",
+                        "name": "variables",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template language variant. eg: id",
+                        "name": "language",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message Quoted ID",
+                        "name": "msg_quoted_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message Quoted",
+                        "name": "msg_quoted",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Seconds the session is shown typing before the text is sent, max 60",
+                        "name": "typing_seconds",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Answer a retry with this key with the response of the first request, instead of sending the message again",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Queue the message and return the send job instead of waiting for it to be sent",
+                        "name": "async",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Send the message at this time, RFC3339 or YYYY-MM-DD HH:MM in the timezone, the response is the schedule",
+                        "name": "send_at",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Send the message at every time of this cron expression, eg: 0 9 * * MON, the response is the schedule",
+                        "name": "cron",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timezone of send_at and cron, default UTC. eg: Asia/Jakarta",
+                        "name": "timezone",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "skip",
+                            "once",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "Runs missed during a downtime: skip them, send once or send all, default to SCHEDULE_CATCH_UP",
+                        "name": "catch_up",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "202": {
+                        "description": "Queued when async is true",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.WaSendJob"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency-Key already used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.HTTPErrorValidation"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Over the rate limit, retry after the Retry-After header seconds",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/send-video": {
+            "post": {
+                "description": "Send video message.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Messaging"
+                ],
+                "summary": "send video message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Destination number. eg: 6281255423 or group_creator-timstamp_created -\u003e 6281271471566-1619679643 for group",
+                        "name": "msisdn",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Video file",
+                        "name": "video_file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message Quoted ID",
+                        "name": "msg_quoted_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message Quoted",
+                        "name": "msg_quoted",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message to include",
+                        "name": "message",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template ID, sends the rendered template instead of the message",
+                        "name": "template_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template variables, JSON object. eg: {\\",
+                        "name": "variables",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template language variant. eg: id",
+                        "name": "language",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Answer a retry with this key with the response of the first request, instead of sending the message again",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Queue the message and return the send job instead of waiting for it to be sent",
+                        "name": "async",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Send the message at this time, RFC3339 or YYYY-MM-DD HH:MM in the timezone, the response is the schedule",
+                        "name": "send_at",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Send the message at every time of this cron expression, eg: 0 9 * * MON, the response is the schedule",
+                        "name": "cron",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timezone of send_at and cron, default UTC. eg: Asia/Jakarta",
+                        "name": "timezone",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "skip",
+                            "once",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "Runs missed during a downtime: skip them, send once or send all, default to SCHEDULE_CATCH_UP",
+                        "name": "catch_up",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "202": {
+                        "description": "Queued when async is true",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.WaSendJob"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency-Key already used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.HTTPErrorValidation"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Over the rate limit, retry after the Retry-After header seconds",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/sessions": {
+            "get": {
+                "description": "List the registered whatsapp sessions. Every whatsapp endpoint is also available for a named session under /v1/whatsapp/sessions/{session_id}/..., login creates the session when it does not exist yet.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Whatsapp"
+                ],
+                "summary": "list sessions",
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "type": "string"
+                                            }
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/whatsapp/status": {
+            "get": {
+                "description": "Get the connection state, the session JID, the last successful phone ping and the phone battery.\nThe phone is pinged in background every WHATSAPP_PING_INTERVAL seconds.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Info"
+                ],
+                "summary": "get session status",
+                "responses": {
+                    "200": {
+                        "description": "Description",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.JSONResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.WaStatus"
+                                        },
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HTTPError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.AutoReplyResponse": {
+            "type": "object",
+            "required": [
+                "type"
+            ],
+            "properties": {
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "media_id": {
+                    "type": "string"
+                },
+                "quote": {
+                    "description": "Quote the received message in the reply",
+                    "type": "boolean"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Our opening hours are 08:00 - 17:00"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "text"
+                }
+            }
+        },
+        "domain.AutoReplyRule": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "case_sensitive": {
+                    "type": "boolean"
+                },
+                "cooldown_seconds": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "jids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "match": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "pattern": {
+                    "type": "string"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "response": {
+                    "$ref": "#/definitions/domain.AutoReplyResponse"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "time_end": {
+                    "type": "string"
+                },
+                "time_start": {
+                    "type": "string"
+                },
+                "timezone": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.AutoReplyRuleForm": {
+            "type": "object",
+            "required": [
+                "match",
+                "name",
+                "pattern",
+                "response"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "case_sensitive": {
+                    "type": "boolean"
+                },
+                "cooldown_seconds": {
+                    "type": "integer",
+                    "example": 3600
+                },
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2,
+                        3,
+                        4,
+                        5
+                    ]
+                },
+                "jids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "6281234567890@s.whatsapp.net"
+                    ]
+                },
+                "match": {
+                    "type": "string",
+                    "example": "contains"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Opening hours"
+                },
+                "pattern": {
+                    "type": "string",
+                    "example": "opening hours"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "response": {
+                    "$ref": "#/definitions/domain.AutoReplyResponse"
+                },
+                "session_id": {
+                    "type": "string",
+                    "example": "default"
+                },
+                "time_end": {
+                    "type": "string",
+                    "example": "17:00"
+                },
+                "time_start": {
+                    "type": "string",
+                    "example": "08:00"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Asia/Jakarta"
+                }
+            }
+        },
+        "domain.Campaign": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "media_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "progress": {
+                    "$ref": "#/definitions/domain.CampaignProgress"
+                },
+                "scheduled_at": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Hello {{name}}, your order {{order}} is ready"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "text"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.CampaignProgress": {
+            "type": "object",
+            "properties": {
+                "cancelled": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "queued": {
+                    "type": "integer"
+                },
+                "sending": {
+                    "type": "integer"
+                },
+                "sent": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.CampaignRecipient": {
+            "type": "object",
+            "properties": {
+                "campaign_id": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "message_id": {
+                    "type": "string"
+                },
+                "msisdn": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "integer"
+                },
+                "sent_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.Flow": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "jids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                },
+                "states": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/domain.FlowState"
+                    }
+                },
+                "timeout_seconds": {
+                    "type": "integer"
+                },
+                "trigger": {
+                    "$ref": "#/definitions/domain.FlowTrigger"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.FlowBranch": {
+            "type": "object",
+            "properties": {
+                "case_sensitive": {
+                    "type": "boolean"
+                },
+                "match": {
+                    "type": "string",
+                    "example": "exact"
+                },
+                "next": {
+                    "type": "string",
+                    "example": "billing"
+                },
+                "pattern": {
+                    "type": "string",
+                    "example": "1"
+                }
+            }
+        },
+        "domain.FlowConversation": {
+            "type": "object",
+            "properties": {
+                "contact_jid": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "flow_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "jid": {
+                    "type": "string"
+                },
+                "retries": {
+                    "type": "integer"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.FlowForm": {
+            "type": "object",
+            "required": [
+                "name",
+                "start",
+                "states"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "jids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Support menu"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "session_id": {
+                    "type": "string",
+                    "example": "default"
+                },
+                "start": {
+                    "description": "Start is the state entered when the flow is triggered",
+                    "type": "string",
+                    "example": "menu"
+                },
+                "states": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/domain.FlowState"
+                    }
+                },
+                "timeout_seconds": {
+                    "description": "TimeoutSeconds is the time a contact has to answer, 0 waits forever",
+                    "type": "integer",
+                    "example": 600
+                },
+                "trigger": {
+                    "$ref": "#/definitions/domain.FlowTrigger"
+                }
+            }
+        },
+        "domain.FlowState": {
+            "type": "object",
+            "properties": {
+                "branches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FlowBranch"
+                    }
+                },
+                "error": {
+                    "description": "Error is sent for an invalid answer, or an answer matching no branch\nwithout Next. The prompt is sent again when empty.",
+                    "type": "string",
+                    "example": "Please answer 1 or 2"
+                },
+                "max_retries": {
+                    "description": "MaxRetries invalid answers end the conversation, 0 never does",
+                    "type": "integer"
+                },
+                "next": {
+                    "type": "string"
+                },
+                "prompt": {
+                    "description": "Prompt is the text sent, {{name}} is replaced with the variable name",
+                    "type": "string",
+                    "example": "Press 1 for billing, 2 for support"
+                },
+                "quote": {
+                    "description": "Quote the message answered in the prompt",
+                    "type": "boolean"
+                },
+                "save": {
+                    "description": "Save stores the answer in the variable named Save",
+                    "type": "string"
+                },
+                "timeout_next": {
+                    "description": "TimeoutNext is the state entered when the contact does not answer in\ntime, the conversation ends when empty",
+                    "type": "string"
+                },
+                "timeout_seconds": {
+                    "description": "TimeoutSeconds overrides the flow timeout for this state",
+                    "type": "integer"
+                },
+                "validate": {
+                    "description": "Validate is the regex a valid answer matches",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.FlowTrigger": {
+            "type": "object",
+            "properties": {
+                "case_sensitive": {
+                    "type": "boolean"
+                },
+                "match": {
+                    "type": "string",
+                    "example": "exact"
+                },
+                "pattern": {
+                    "type": "string",
+                    "example": "menu"
+                }
+            }
+        },
+        "domain.HTTPError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "message": {
+                    "type": "string",
+                    "example": "status bad request"
+                }
+            }
+        },
+        "domain.HTTPErrorValidation": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "username"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "username cannot empty"
+                }
+            }
+        },
+        "domain.JSONResult": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "object"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "meta": {
+                    "type": "object"
+                }
+            }
+        },
+        "domain.JSONResultMeta": {
+            "type": "object",
+            "properties": {
+                "current_page": {
+                    "type": "integer"
+                },
+                "page_count": {
+                    "type": "integer"
+                },
+                "per_page": {
+                    "type": "integer"
+                },
+                "total_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.Template": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "defaults": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "language": {
+                    "type": "string",
+                    "example": "en"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "placeholders": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Hello {{name}}, your order {{order}} is ready"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "variants": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.TemplateForm": {
+            "type": "object",
+            "required": [
+                "name",
+                "text",
+                "variants"
+            ],
+            "properties": {
+                "defaults": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "name": "customer"
+                    }
+                },
+                "language": {
+                    "type": "string",
+                    "example": "en"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Order ready"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Hello {{name}}, your order {{order}} is ready"
+                },
+                "variants": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "id": "Halo {{name}} pesanan {{order}} sudah siap"
+                    }
+                }
+            }
+        },
+        "domain.TemplateRender": {
+            "type": "object",
+            "properties": {
+                "language": {
+                    "type": "string"
+                },
+                "template_id": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.TemplateRenderForm": {
+            "type": "object",
+            "properties": {
+                "language": {
+                    "type": "string",
+                    "example": "id"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "order": "A-1001"
+                    }
+                }
+            }
+        },
+        "domain.WaBattery": {
+            "type": "object",
+            "properties": {
+                "percentage": {
+                    "type": "integer"
+                },
+                "plugged": {
+                    "type": "boolean"
+                },
+                "powersave": {
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WaChat": {
+            "type": "object",
+            "properties": {
+                "archived": {
+                    "type": "boolean"
+                },
+                "jid": {
+                    "type": "string",
+                    "example": "6281234567890@s.whatsapp.net"
+                },
+                "last_message": {
+                    "$ref": "#/definitions/domain.WaMessage"
+                },
+                "last_message_at": {
+                    "type": "string"
+                },
+                "muted": {
+                    "type": "boolean"
+                },
+                "muted_until": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "pinned": {
+                    "type": "boolean"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "spam": {
+                    "type": "boolean"
+                },
+                "unread_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.WaChatHistoryMeta": {
+            "type": "object",
+            "properties": {
+                "before": {
+                    "type": "string"
+                },
+                "count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.WaConnectionStatus": {
+            "type": "object",
+            "properties": {
+                "disconnected_at": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "max_reconnect_attempts": {
+                    "type": "integer"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "reconnect_attempts": {
+                    "type": "integer"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WaContact": {
+            "type": "object",
+            "properties": {
+                "business": {
+                    "type": "boolean"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "jid": {
+                    "type": "string",
+                    "example": "6281234567890@s.whatsapp.net"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "notify": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "short": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "verified_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WaContactCard": {
+            "type": "object",
+            "properties": {
+                "emails": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "budi@example.com"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Budi Santoso"
+                },
+                "organization": {
+                    "type": "string",
+                    "example": "Cooljar"
+                },
+                "phones": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "6281234567890"
+                    ]
+                },
+                "vcard": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WaEvent": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "jid": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WaGroup": {
+            "type": "object",
+            "properties": {
+                "creation": {
+                    "type": "integer"
+                },
+                "desc": {
+                    "type": "string"
+                },
+                "descId": {
+                    "type": "string"
+                },
+                "descOwner": {
+                    "type": "string"
+                },
+                "descTime": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "owner": {
+                    "type": "string"
+                },
+                "participants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.WaGroupParticipants"
+                    }
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "subjectOwner": {
+                    "type": "string"
+                },
+                "subjectTime": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.WaGroupParticipants": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "isAdmin": {
+                    "type": "boolean"
+                },
+                "isSuperAdmin": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.WaLoginAttempt": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "jid": {
+                    "type": "string"
+                },
+                "qr_code": {
+                    "type": "string"
+                },
+                "qr_count": {
+                    "type": "integer"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WaMedia": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "file_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "mime_type": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.WaMessage": {
+            "type": "object",
+            "properties": {
+                "caption": {
+                    "type": "string"
+                },
+                "chat_name": {
+                    "type": "string"
+                },
+                "contact_name": {
+                    "type": "string"
+                },
+                "direction": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "file_name": {
+                    "type": "string"
+                },
+                "from_me": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "jid": {
+                    "type": "string"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "media_id": {
+                    "type": "string"
+                },
+                "mime_type": {
+                    "type": "string"
+                },
+                "push_name": {
+                    "type": "string"
+                },
+                "quoted_message_id": {
+                    "type": "string"
+                },
+                "sender_jid": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "text"
+                },
+                "vcard": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WaMessageStatus": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer",
-                    "example": 400
+                "delivered_at": {
+                    "type": "string"
                 },
-                "message": {
+                "jid": {
+                    "type": "string"
+                },
+                "message_id": {
+                    "type": "string"
+                },
+                "pending_at": {
+                    "type": "string"
+                },
+                "played_at": {
+                    "type": "string"
+                },
+                "read_at": {
+                    "type": "string"
+                },
+                "server_ack_at": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "status": {
                     "type": "string",
-                    "example": "status bad request"
+                    "example": "read"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "domain.HTTPErrorValidation": {
+        "domain.WaSchedule": {
             "type": "object",
             "properties": {
-                "field": {
+                "catch_up": {
                     "type": "string",
-                    "example": "username"
+                    "example": "once"
                 },
-                "message": {
+                "created_at": {
+                    "type": "string"
+                },
+                "cron": {
                     "type": "string",
-                    "example": "username cannot empty"
+                    "example": "0 9 * * MON"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_job_id": {
+                    "type": "string"
+                },
+                "last_run_at": {
+                    "type": "string"
+                },
+                "next_run_at": {
+                    "type": "string"
+                },
+                "request": {
+                    "$ref": "#/definitions/domain.WaSendRequest"
+                },
+                "runs": {
+                    "type": "integer"
+                },
+                "send_at": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Asia/Jakarta"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "domain.JSONResult": {
+        "domain.WaSendAttempt": {
             "type": "object",
             "properties": {
-                "code": {
+                "attempt": {
                     "type": "integer"
                 },
-                "data": {
-                    "type": "object"
+                "error": {
+                    "type": "string"
                 },
-                "message": {
+                "finished_at": {
                     "type": "string"
                 },
-                "meta": {
-                    "type": "object"
+                "job_id": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
                 }
             }
         },
-        "domain.WaGroup": {
+        "domain.WaSendJob": {
             "type": "object",
             "properties": {
-                "creation": {
+                "attempt_log": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.WaSendAttempt"
+                    }
+                },
+                "attempts": {
                     "type": "integer"
                 },
-                "desc": {
+                "created_at": {
                     "type": "string"
                 },
-                "descId": {
+                "id": {
                     "type": "string"
                 },
-                "descOwner": {
+                "last_error": {
                     "type": "string"
                 },
-                "descTime": {
+                "max_attempts": {
                     "type": "integer"
                 },
-                "id": {
+                "message_id": {
                     "type": "string"
                 },
-                "owner": {
+                "next_attempt_at": {
                     "type": "string"
                 },
-                "participants": {
+                "request": {
+                    "$ref": "#/definitions/domain.WaSendRequest"
+                },
+                "sent_at": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WaSendRequest": {
+            "type": "object",
+            "properties": {
+                "contacts": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/domain.WaGroupParticipants"
+                        "$ref": "#/definitions/domain.WaContactCard"
                     }
                 },
-                "subject": {
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "media_id": {
                     "type": "string"
                 },
-                "subjectOwner": {
+                "msg_quoted": {
                     "type": "string"
                 },
-                "subjectTime": {
+                "msg_quoted_id": {
+                    "type": "string"
+                },
+                "msisdn": {
+                    "type": "string",
+                    "example": "6281234567890"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "text"
+                },
+                "typing_seconds": {
                     "type": "integer"
                 }
             }
         },
-        "domain.WaGroupParticipants": {
+        "domain.WaStatus": {
             "type": "object",
             "properties": {
-                "id": {
-                    "type": "string"
+                "battery": {
+                    "$ref": "#/definitions/domain.WaBattery"
                 },
-                "isAdmin": {
+                "connected": {
                     "type": "boolean"
                 },
-                "isSuperAdmin": {
+                "connection": {
+                    "$ref": "#/definitions/domain.WaConnectionStatus"
+                },
+                "jid": {
+                    "type": "string"
+                },
+                "last_ping_at": {
+                    "type": "string"
+                },
+                "last_ping_error": {
+                    "type": "string"
+                },
+                "logged_in": {
                     "type": "boolean"
+                },
+                "push_name": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
                 }
             }
         },
@@ -1056,6 +6966,172 @@ var doc = `{
                     }
                 }
             }
+        },
+        "domain.Webhook": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "jids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WebhookCreated": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "jids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "event_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "string"
+                },
+                "response_status": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "webhook_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WebhookForm": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "message.text",
+                        "message.image"
+                    ]
+                },
+                "jids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "6281234567890@s.whatsapp.net"
+                    ]
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "my-signing-secret"
+                },
+                "session_id": {
+                    "type": "string",
+                    "example": "default"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/whatsapp/webhook"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -0,0 +1,99 @@
+package domain
+
+import "time"
+
+// AutoReplyMatch is how a rule pattern is compared to the received text
+type AutoReplyMatch string
+
+const (
+	AutoReplyExact    AutoReplyMatch = "exact"
+	AutoReplyContains AutoReplyMatch = "contains"
+	AutoReplyRegex    AutoReplyMatch = "regex"
+)
+
+// AutoReplyResponseType is the kind of message sent back by a rule
+type AutoReplyResponseType string
+
+const (
+	AutoReplyText     AutoReplyResponseType = "text"
+	AutoReplyImage    AutoReplyResponseType = "image"
+	AutoReplyDocument AutoReplyResponseType = "document"
+	AutoReplyAudio    AutoReplyResponseType = "audio"
+	AutoReplyVideo    AutoReplyResponseType = "video"
+	AutoReplyLocation AutoReplyResponseType = "location"
+)
+
+// AutoReplyResponse is the message sent when a rule matches. Media responses
+// send the media MediaID of the media library with Text as caption.
+type AutoReplyResponse struct {
+	Type      AutoReplyResponseType `json:"type" validate:"required,oneof=text image document audio video location" example:"text"`
+	Text      string                `json:"text,omitempty" example:"Our opening hours are 08:00 - 17:00"`
+	MediaID   string                `json:"media_id,omitempty"`
+	Latitude  float64               `json:"latitude,omitempty"`
+	Longitude float64               `json:"longitude,omitempty"`
+	// Quote the received message in the reply
+	Quote bool `json:"quote"`
+}
+
+// AutoReplyRule answers the received text messages matching it. Empty Jids
+// match every chat, a JID matches the chat or, in groups, the sender. The
+// time window is in Timezone, an end before the start spans midnight, and Days
+// are the week days (0 is Sunday) the rule is active, every day when empty.
+type AutoReplyRule struct {
+	ID              string            `json:"id"`
+	Name            string            `json:"name"`
+	SessionID       string            `json:"session_id,omitempty"`
+	Match           AutoReplyMatch    `json:"match"`
+	Pattern         string            `json:"pattern"`
+	CaseSensitive   bool              `json:"case_sensitive"`
+	Jids            []string          `json:"jids"`
+	TimeStart       string            `json:"time_start,omitempty"`
+	TimeEnd         string            `json:"time_end,omitempty"`
+	Days            []int             `json:"days"`
+	Timezone        string            `json:"timezone,omitempty"`
+	CooldownSeconds int               `json:"cooldown_seconds"`
+	Priority        int               `json:"priority"`
+	Active          bool              `json:"active"`
+	Response        AutoReplyResponse `json:"response"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+// AutoReplyRuleForm creates or updates an auto-reply rule
+type AutoReplyRuleForm struct {
+	Name            string            `json:"name" validate:"required" example:"Opening hours"`
+	SessionID       string            `json:"session_id" example:"default"`
+	Match           AutoReplyMatch    `json:"match" validate:"required,oneof=exact contains regex" example:"contains"`
+	Pattern         string            `json:"pattern" validate:"required" example:"opening hours"`
+	CaseSensitive   bool              `json:"case_sensitive"`
+	Jids            []string          `json:"jids" example:"6281234567890@s.whatsapp.net"`
+	TimeStart       string            `json:"time_start" example:"08:00"`
+	TimeEnd         string            `json:"time_end" example:"17:00"`
+	Days            []int             `json:"days" validate:"dive,min=0,max=6" example:"1,2,3,4,5"`
+	Timezone        string            `json:"timezone" example:"Asia/Jakarta"`
+	CooldownSeconds int               `json:"cooldown_seconds" validate:"min=0" example:"3600"`
+	Priority        int               `json:"priority"`
+	Active          *bool             `json:"active"`
+	Response        AutoReplyResponse `json:"response" validate:"required"`
+}
+
+type AutoReplyRepository interface {
+	Store(rule AutoReplyRule) error
+	Update(rule AutoReplyRule) error
+	Delete(id string) error
+	GetByID(id string) (AutoReplyRule, error)
+	// Fetch returns the rules by priority
+	Fetch() ([]AutoReplyRule, error)
+}
+
+type AutoReplyUsecase interface {
+	Create(form AutoReplyRuleForm) (AutoReplyRule, error)
+	Update(id string, form AutoReplyRuleForm) (AutoReplyRule, error)
+	Delete(id string) error
+	Get(id string) (AutoReplyRule, error)
+	Fetch() ([]AutoReplyRule, error)
+
+	// HandleEvent answers the received text messages with the first rule
+	// matching them
+	HandleEvent(event WaEvent)
+}
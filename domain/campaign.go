@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"context"
+	"io"
+	"mime/multipart"
+	"time"
+)
+
+// CampaignStatus is the status of a broadcast campaign
+type CampaignStatus string
+
+const (
+	CampaignScheduled CampaignStatus = "scheduled"
+	CampaignRunning   CampaignStatus = "running"
+	CampaignPaused    CampaignStatus = "paused"
+	CampaignCompleted CampaignStatus = "completed"
+	CampaignCancelled CampaignStatus = "cancelled"
+)
+
+// CampaignRecipientStatus is the status of the message of a recipient
+type CampaignRecipientStatus string
+
+const (
+	CampaignRecipientQueued    CampaignRecipientStatus = "queued"
+	CampaignRecipientSending   CampaignRecipientStatus = "sending"
+	CampaignRecipientSent      CampaignRecipientStatus = "sent"
+	CampaignRecipientFailed    CampaignRecipientStatus = "failed"
+	CampaignRecipientCancelled CampaignRecipientStatus = "cancelled"
+)
+
+// Campaign broadcasts a message to a list of recipients. Text, the message or
+// the caption of the media, may hold {{variable}} placeholders replaced with
+// the columns of the recipient row.
+type Campaign struct {
+	ID          string           `json:"id"`
+	SessionID   string           `json:"session_id"`
+	Name        string           `json:"name"`
+	Type        string           `json:"type" example:"text"`
+	Text        string           `json:"text,omitempty" example:"Hello {{name}}, your order {{order}} is ready"`
+	MediaID     string           `json:"media_id,omitempty"`
+	Status      CampaignStatus   `json:"status"`
+	ScheduledAt *time.Time       `json:"scheduled_at,omitempty"`
+	StartedAt   *time.Time       `json:"started_at,omitempty"`
+	CompletedAt *time.Time       `json:"completed_at,omitempty"`
+	Progress    CampaignProgress `json:"progress"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// CampaignProgress counts the recipients of a campaign by status
+type CampaignProgress struct {
+	Total     int `json:"total"`
+	Queued    int `json:"queued"`
+	Sending   int `json:"sending"`
+	Sent      int `json:"sent"`
+	Failed    int `json:"failed"`
+	Cancelled int `json:"cancelled"`
+}
+
+// CampaignRecipient is a row of the recipient list, Variables are its columns
+type CampaignRecipient struct {
+	CampaignID string                  `json:"campaign_id"`
+	Row        int                     `json:"row"`
+	Msisdn     string                  `json:"msisdn"`
+	Variables  map[string]string       `json:"variables"`
+	Status     CampaignRecipientStatus `json:"status"`
+	MessageID  string                  `json:"message_id,omitempty"`
+	Error      string                  `json:"error,omitempty"`
+	SentAt     *time.Time              `json:"sent_at,omitempty"`
+	UpdatedAt  time.Time               `json:"updated_at"`
+}
+
+// CampaignForm creates a campaign. The media of a media campaign is the
+// uploaded file or the media MediaID of the media library.
+type CampaignForm struct {
+	Name        string `validate:"required"`
+	Type        string `validate:"required,oneof=text image document audio video"`
+	Text        string `validate:"required_if=Type text"`
+	MediaID     string
+	ScheduledAt *time.Time
+}
+
+// CampaignRecipientFilter filters the recipients of a campaign, zero values
+// match everything
+type CampaignRecipientFilter struct {
+	CampaignID string
+	Status     CampaignRecipientStatus
+	Page       int
+	PerPage    int
+}
+
+type CampaignRepository interface {
+	// Store stores the campaign with its recipients
+	Store(campaign Campaign, recipients []CampaignRecipient) error
+	Update(campaign Campaign) error
+	GetByID(id string) (Campaign, error)
+	Fetch(sessionID string, page, perPage int) (campaigns []Campaign, total int, err error)
+	FetchByStatus(status CampaignStatus) ([]Campaign, error)
+	Progress(campaignID string) (CampaignProgress, error)
+
+	FetchRecipients(filter CampaignRecipientFilter) (recipients []CampaignRecipient, total int, err error)
+	// NextRecipient returns the first queued recipient, false when none is left
+	NextRecipient(campaignID string) (CampaignRecipient, bool, error)
+	// UpdateRecipient updates the recipient when its status is still from, it
+	// reports whether it was updated
+	UpdateRecipient(recipient CampaignRecipient, from CampaignRecipientStatus) (bool, error)
+	// FailSendingRecipients fails the recipients left sending by a stop in
+	// the middle of a send, whether their message went out is unknown
+	FailSendingRecipients(reason string) error
+	// CancelRecipients cancels the queued recipients of the campaign
+	CancelRecipients(campaignID string) error
+}
+
+type CampaignUsecase interface {
+	// Create reads the recipients from the CSV recipients, its header names
+	// the columns: msisdn and the variables. The campaign of a session starts
+	// at form.ScheduledAt, right away when it is nil.
+	Create(sessionID string, form CampaignForm, recipients io.Reader, file *multipart.FileHeader) (Campaign, error)
+	Get(sessionID, id string) (Campaign, error)
+	Fetch(sessionID string, page, perPage int) (campaigns []Campaign, meta JSONResultMeta, err error)
+	Recipients(sessionID string, filter CampaignRecipientFilter) (recipients []CampaignRecipient, meta JSONResultMeta, err error)
+	// Report writes the result of every recipient as CSV
+	Report(sessionID, id string, w io.Writer) error
+	Pause(sessionID, id string) (Campaign, error)
+	Resume(sessionID, id string) (Campaign, error)
+	// Cancel stops the campaign, the recipients not sent yet are cancelled
+	Cancel(sessionID, id string) (Campaign, error)
+
+	// Start starts sending the campaigns in the background
+	Start()
+	// Shutdown stops the campaign worker, waiting for the message being sent
+	// until ctx is done. The running campaigns resume on the next start.
+	Shutdown(ctx context.Context) error
+}
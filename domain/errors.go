@@ -21,4 +21,59 @@ var (
 	ErrPhoneNotConnected          = errors.New("something when wrong while trying to ping, please check phone connectivity")
 
 	ErrOptionsNotProvided         = errors.New("new conn options not provided")
+
+	ErrSessionNotFound  = errors.New("session not found")
+	ErrTooManySessions  = errors.New("too many sessions, see WHATSAPP_MAX_SESSIONS")
+	ErrInvalidSessionID = errors.New("invalid session id, only letters, numbers, '-' and '_' are allowed")
+	ErrSessionNotStored = errors.New("session not stored")
+	ErrSessionDecrypt   = errors.New("session can not be decrypted, check the session encryption key")
+	ErrSessionEncrypted = errors.New("session is encrypted but no session encryption key is configured")
+
+	ErrLoginAttemptNotFound = errors.New("login attempt not found")
+	ErrShuttingDown         = errors.New("service is shutting down")
+
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrInvalidEventType        = errors.New("invalid event type")
+
+	ErrMessageNotFound       = errors.New("message not found")
+	ErrMessageStatusNotFound = errors.New("message status not found")
+
+	ErrMediaNotFound = errors.New("media not found")
+	ErrMediaTooLarge = errors.New("media is larger than the size limit")
+
+	ErrAutoReplyRuleNotFound = errors.New("auto-reply rule not found")
+	ErrInvalidAutoReplyRule  = errors.New("invalid auto-reply rule")
+
+	ErrFlowNotFound             = errors.New("flow not found")
+	ErrInvalidFlow              = errors.New("invalid flow")
+	ErrFlowConversationNotFound = errors.New("flow conversation not found")
+
+	ErrContactNotFound = errors.New("contact not found")
+
+	ErrInvalidPresence  = errors.New("invalid presence, use available or unavailable")
+	ErrInvalidChatState = errors.New("invalid chat state, use composing, recording or paused")
+	ErrChatEmpty        = errors.New("chat has no received message to mark as read")
+
+	ErrSendJobNotFound  = errors.New("send job not found")
+	ErrSendJobBusy      = errors.New("send job is being sent or already sent")
+	ErrSendJobNotFailed = errors.New("only a failed or cancelled send job can be retried")
+	ErrInvalidContact   = errors.New("invalid contact")
+	ErrInvalidSendType  = errors.New("invalid message type, use text, location, contact, image, document, audio or video")
+
+	ErrCampaignNotFound = errors.New("campaign not found")
+	ErrInvalidCampaign  = errors.New("invalid campaign")
+	ErrCampaignState    = errors.New("campaign can not be changed in its current status")
+
+	ErrTemplateNotFound        = errors.New("template not found")
+	ErrInvalidTemplate         = errors.New("invalid template")
+	ErrTemplateVariableMissing = errors.New("template variable missing")
+
+	ErrScheduleNotFound  = errors.New("schedule not found")
+	ErrInvalidSchedule   = errors.New("invalid schedule")
+	ErrScheduleNotActive = errors.New("only an active schedule can be cancelled")
+
+	ErrIdempotencyKeyNotFound = errors.New("idempotency key not found")
+	ErrIdempotencyKeyReused   = errors.New("idempotency key already used for a different request")
+	ErrInvalidIdempotencyKey  = errors.New("invalid idempotency key, at most 255 characters")
 )
\ No newline at end of file
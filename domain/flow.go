@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// FlowTrigger is the text starting a flow, compared as the auto-reply rules do
+type FlowTrigger struct {
+	Match         AutoReplyMatch `json:"match" yaml:"match" example:"exact"`
+	Pattern       string         `json:"pattern" yaml:"pattern" example:"menu"`
+	CaseSensitive bool           `json:"case_sensitive" yaml:"case_sensitive"`
+}
+
+// FlowBranch moves the conversation to Next when the answer matches
+type FlowBranch struct {
+	Match         AutoReplyMatch `json:"match" yaml:"match" example:"exact"`
+	Pattern       string         `json:"pattern" yaml:"pattern" example:"1"`
+	CaseSensitive bool           `json:"case_sensitive" yaml:"case_sensitive"`
+	Next          string         `json:"next" yaml:"next" example:"billing"`
+}
+
+// FlowState is a step of a flow. The prompt is sent when the conversation
+// enters the state, then the answer is validated, saved and moves the
+// conversation to the first matching branch, or to Next. A state without
+// branches and Next ends the conversation once its prompt is sent.
+type FlowState struct {
+	// Prompt is the text sent, {{name}} is replaced with the variable name
+	Prompt string `json:"prompt" yaml:"prompt" example:"Press 1 for billing, 2 for support"`
+	// Quote the message answered in the prompt
+	Quote bool `json:"quote" yaml:"quote"`
+	// Validate is the regex a valid answer matches
+	Validate string `json:"validate,omitempty" yaml:"validate"`
+	// Error is sent for an invalid answer, or an answer matching no branch
+	// without Next. The prompt is sent again when empty.
+	Error string `json:"error,omitempty" yaml:"error" example:"Please answer 1 or 2"`
+	// MaxRetries invalid answers end the conversation, 0 never does
+	MaxRetries int `json:"max_retries,omitempty" yaml:"max_retries"`
+	// Save stores the answer in the variable named Save
+	Save     string       `json:"save,omitempty" yaml:"save"`
+	Branches []FlowBranch `json:"branches,omitempty" yaml:"branches"`
+	Next     string       `json:"next,omitempty" yaml:"next"`
+	// TimeoutSeconds overrides the flow timeout for this state
+	TimeoutSeconds int `json:"timeout_seconds,omitempty" yaml:"timeout_seconds"`
+	// TimeoutNext is the state entered when the contact does not answer in
+	// time, the conversation ends when empty
+	TimeoutNext string `json:"timeout_next,omitempty" yaml:"timeout_next"`
+}
+
+// Flow is a multi-step conversation started by a trigger. Empty Jids start
+// it in every chat, a JID matches the chat or, in groups, the sender.
+type Flow struct {
+	ID             string               `json:"id"`
+	Name           string               `json:"name"`
+	SessionID      string               `json:"session_id,omitempty"`
+	Jids           []string             `json:"jids"`
+	Trigger        FlowTrigger          `json:"trigger"`
+	Start          string               `json:"start"`
+	TimeoutSeconds int                  `json:"timeout_seconds"`
+	Priority       int                  `json:"priority"`
+	Active         bool                 `json:"active"`
+	States         map[string]FlowState `json:"states"`
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+}
+
+// FlowForm creates or updates a flow, sent as JSON or YAML
+type FlowForm struct {
+	Name      string      `json:"name" yaml:"name" validate:"required" example:"Support menu"`
+	SessionID string      `json:"session_id" yaml:"session_id" example:"default"`
+	Jids      []string    `json:"jids" yaml:"jids"`
+	Trigger   FlowTrigger `json:"trigger" yaml:"trigger"`
+	// Start is the state entered when the flow is triggered
+	Start string `json:"start" yaml:"start" validate:"required" example:"menu"`
+	// TimeoutSeconds is the time a contact has to answer, 0 waits forever
+	TimeoutSeconds int                  `json:"timeout_seconds" yaml:"timeout_seconds" validate:"min=0" example:"600"`
+	Priority       int                  `json:"priority" yaml:"priority"`
+	Active         *bool                `json:"active" yaml:"active"`
+	States         map[string]FlowState `json:"states" yaml:"states" validate:"required,min=1"`
+}
+
+// FlowConversation is the state of a contact in a flow, in a chat
+type FlowConversation struct {
+	ID         string            `json:"id"`
+	FlowID     string            `json:"flow_id"`
+	SessionID  string            `json:"session_id"`
+	Jid        string            `json:"jid"`
+	ContactJid string            `json:"contact_jid"`
+	State      string            `json:"state"`
+	Variables  map[string]string `json:"variables"`
+	Retries    int               `json:"retries"`
+	ExpiresAt  *time.Time        `json:"expires_at"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+type FlowRepository interface {
+	Store(flow Flow) error
+	Update(flow Flow) error
+	// Delete removes the flow and its conversations
+	Delete(id string) error
+	GetByID(id string) (Flow, error)
+	// Fetch returns the flows by priority
+	Fetch() ([]Flow, error)
+
+	// SaveConversation stores or replaces the conversation of its contact
+	SaveConversation(conversation FlowConversation) error
+	DeleteConversation(id string) error
+	GetConversation(sessionID, jid, contactJid string) (FlowConversation, error)
+	FetchConversations(flowID string) ([]FlowConversation, error)
+	// FetchExpiredConversations returns the conversations expired at now
+	FetchExpiredConversations(now time.Time, limit int) ([]FlowConversation, error)
+}
+
+type FlowUsecase interface {
+	Create(form FlowForm) (Flow, error)
+	Update(id string, form FlowForm) (Flow, error)
+	Delete(id string) error
+	Get(id string) (Flow, error)
+	Fetch() ([]Flow, error)
+	Conversations(flowID string) ([]FlowConversation, error)
+	EndConversation(id string) error
+
+	// HandleEvent drives the conversations with the received text messages,
+	// it reports whether a flow answered the message
+	HandleEvent(event WaEvent) bool
+
+	// Start runs the conversation timeouts and the replies until Shutdown
+	Start()
+	Shutdown(ctx context.Context) error
+}
@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// IdempotencyRecord is the response to a send request with an
+// Idempotency-Key header, replayed to the requests with the same key until
+// ExpiresAt. RequestHash identifies the request the key was first used for.
+type IdempotencyRecord struct {
+	SessionID   string
+	Key         string
+	RequestHash string
+	Status      int
+	ContentType string
+	Body        []byte
+	MessageID   string
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+type IdempotencyRepository interface {
+	Store(record IdempotencyRecord) error
+	// Get returns the record of the key, expired or not
+	Get(sessionID, key string) (IdempotencyRecord, error)
+	DeleteExpired(now time.Time) error
+}
+
+type IdempotencyUsecase interface {
+	// Lock waits for the requests with the same key to be answered, unlock
+	// must be called once this one is.
+	Lock(sessionID, key string) (unlock func())
+	// Get returns the record of the key, ErrIdempotencyKeyNotFound when it
+	// was not used or has expired.
+	Get(sessionID, key string) (IdempotencyRecord, error)
+	// Store keeps the response to the request of the key for the configured
+	// window.
+	Store(record IdempotencyRecord) error
+}
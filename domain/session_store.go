@@ -0,0 +1,11 @@
+package domain
+
+import "github.com/Rhymen/go-whatsapp"
+
+// SessionStore represent the persistence of the whatsapp sessions
+type SessionStore interface {
+	Read(sessionID string) (whatsapp.Session, error)
+	Write(sessionID string, session whatsapp.Session) error
+	Delete(sessionID string) error
+	List() ([]string, error)
+}
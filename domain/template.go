@@ -0,0 +1,62 @@
+package domain
+
+import "time"
+
+// Template is a message text with {{variable}} placeholders. Defaults are the
+// values of the variables not given when it is rendered, Variants the text in
+// other languages, by language code.
+type Template struct {
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	Language     string            `json:"language,omitempty" example:"en"`
+	Text         string            `json:"text" example:"Hello {{name}}, your order {{order}} is ready"`
+	Defaults     map[string]string `json:"defaults"`
+	Variants     map[string]string `json:"variants"`
+	Placeholders []string          `json:"placeholders"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+}
+
+// TemplateForm creates or updates a template
+type TemplateForm struct {
+	Name     string            `json:"name" validate:"required" example:"Order ready"`
+	Language string            `json:"language" example:"en"`
+	Text     string            `json:"text" validate:"required" example:"Hello {{name}}, your order {{order}} is ready"`
+	Defaults map[string]string `json:"defaults" example:"name:customer"`
+	Variants map[string]string `json:"variants" validate:"dive,keys,required,endkeys,required" example:"id:Halo {{name}} pesanan {{order}} sudah siap"`
+}
+
+// TemplateRenderForm renders a template with the variables, in the language
+// variant when there is one
+type TemplateRenderForm struct {
+	Language  string            `json:"language" example:"id"`
+	Variables map[string]string `json:"variables" example:"order:A-1001"`
+}
+
+// TemplateRender is a rendered template, Language is the language of the
+// text used
+type TemplateRender struct {
+	TemplateID string `json:"template_id"`
+	Language   string `json:"language,omitempty"`
+	Text       string `json:"text"`
+}
+
+type TemplateRepository interface {
+	Store(template Template) error
+	Update(template Template) error
+	Delete(id string) error
+	GetByID(id string) (Template, error)
+	// Fetch returns the templates by name
+	Fetch() ([]Template, error)
+}
+
+type TemplateUsecase interface {
+	Create(form TemplateForm) (Template, error)
+	Update(id string, form TemplateForm) (Template, error)
+	Delete(id string) error
+	Get(id string) (Template, error)
+	Fetch() ([]Template, error)
+	// Render renders the template id, it fails with ErrTemplateVariableMissing
+	// when a placeholder has neither a variable nor a default.
+	Render(id string, form TemplateRenderForm) (TemplateRender, error)
+}
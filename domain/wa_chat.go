@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+// WaChat is a chat of a session as listed by the phone. LastMessage is the
+// latest message of the chat in the message store.
+type WaChat struct {
+	SessionID     string     `json:"session_id"`
+	Jid           string     `json:"jid" example:"6281234567890@s.whatsapp.net"`
+	Name          string     `json:"name,omitempty"`
+	UnreadCount   int        `json:"unread_count"`
+	Archived      bool       `json:"archived"`
+	Pinned        bool       `json:"pinned"`
+	Muted         bool       `json:"muted"`
+	MutedUntil    *time.Time `json:"muted_until,omitempty"`
+	Spam          bool       `json:"spam"`
+	LastMessageAt *time.Time `json:"last_message_at,omitempty"`
+	LastMessage   *WaMessage `json:"last_message,omitempty"`
+}
+
+// WaChatFilter filters the chats, zero values match everything
+type WaChatFilter struct {
+	Query    string
+	Archived *bool
+	Unread   bool
+	Page     int
+	PerPage  int
+}
+
+// WaChatHistoryMeta is the cursor of a page of chat history, Before is the
+// message to load the previous page before, empty on the first message.
+type WaChatHistoryMeta struct {
+	Before string `json:"before,omitempty"`
+	Count  int    `json:"count"`
+}
@@ -0,0 +1,56 @@
+package domain
+
+import "time"
+
+// WaContact is a contact, or a group, known by a session. Name is the name
+// saved in the phone address book, Notify the name the contact set for
+// itself, VerifiedName the verified name of a business account.
+type WaContact struct {
+	SessionID    string    `json:"session_id"`
+	Jid          string    `json:"jid" example:"6281234567890@s.whatsapp.net"`
+	Name         string    `json:"name,omitempty"`
+	Notify       string    `json:"notify,omitempty"`
+	Short        string    `json:"short,omitempty"`
+	VerifiedName string    `json:"verified_name,omitempty"`
+	Business     bool      `json:"business"`
+	DisplayName  string    `json:"display_name"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// ResolveDisplayName is the best name known for the contact, its address book
+// name first.
+func (c WaContact) ResolveDisplayName() string {
+	for _, name := range []string{c.Name, c.VerifiedName, c.Notify, c.Short} {
+		if name != "" {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// WaContactFilter filters the contacts, zero values match everything
+type WaContactFilter struct {
+	SessionID string
+	Query     string
+	Business  *bool
+	Page      int
+	PerPage   int
+}
+
+type WaContactRepository interface {
+	// Save stores the contact, merged with the stored one: empty names keep
+	// the stored ones and a business account stays one.
+	Save(contact WaContact) error
+	GetByJid(sessionID, jid string) (WaContact, error)
+	Fetch(filter WaContactFilter) (contacts []WaContact, total int, err error)
+}
+
+type WaContactUsecase interface {
+	Fetch(filter WaContactFilter) (contacts []WaContact, meta JSONResultMeta, err error)
+	Get(sessionID, jid string) (WaContact, error)
+	// Save merges the contacts in the directory
+	Save(contacts []WaContact) error
+	// DisplayName returns the display name of jid, empty when it is unknown
+	DisplayName(sessionID, jid string) string
+}
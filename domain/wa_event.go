@@ -0,0 +1,118 @@
+package domain
+
+import "time"
+
+// WaEventType is the type of an event received from whatsapp
+type WaEventType string
+
+const (
+	WaEventText     WaEventType = "message.text"
+	WaEventImage    WaEventType = "message.image"
+	WaEventDocument WaEventType = "message.document"
+	WaEventAudio    WaEventType = "message.audio"
+	WaEventVideo    WaEventType = "message.video"
+	WaEventContact  WaEventType = "message.contact"
+	WaEventLocation WaEventType = "message.location"
+	WaEventStatus   WaEventType = "message.status"
+	WaEventBattery  WaEventType = "battery"
+	WaEventJSON     WaEventType = "json"
+
+	WaEventConnection WaEventType = "connection"
+)
+
+// WaEventTypes are the event types that can be subscribed to
+var WaEventTypes = []WaEventType{
+	WaEventText,
+	WaEventImage,
+	WaEventDocument,
+	WaEventAudio,
+	WaEventVideo,
+	WaEventContact,
+	WaEventLocation,
+	WaEventStatus,
+	WaEventBattery,
+	WaEventJSON,
+	WaEventConnection,
+}
+
+// Valid reports whether t is one of WaEventTypes.
+func (t WaEventType) Valid() bool {
+	for _, eventType := range WaEventTypes {
+		if eventType == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WaEvent is an event received by a session. Data is a WaMessage for the
+// message events, a WaMessageStatus for the message.status events, a
+// WaBattery for battery events, the raw whatsapp JSON for json events and a
+// WaConnectionStatus for connection events.
+type WaEvent struct {
+	ID        string      `json:"id"`
+	Type      WaEventType `json:"type"`
+	SessionID string      `json:"session_id"`
+	Jid       string      `json:"jid,omitempty"`
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+// WaEventBus fans the events of all sessions out to the subscribers.
+// Subscribers are called synchronously and must not block.
+type WaEventBus interface {
+	Publish(event WaEvent)
+	Subscribe(fn func(event WaEvent)) (unsubscribe func())
+}
+
+// WaEventFilter selects events, empty fields match everything
+type WaEventFilter struct {
+	SessionID string
+	Types     []WaEventType
+	Jids      []string
+}
+
+// Match reports whether the event passes the filter.
+func (f WaEventFilter) Match(event WaEvent) bool {
+	if f.SessionID != "" && f.SessionID != event.SessionID {
+		return false
+	}
+
+	if len(f.Types) > 0 {
+		found := false
+		for _, t := range f.Types {
+			if t == event.Type {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(f.Jids) > 0 {
+		found := false
+		for _, jid := range f.Jids {
+			if jid == event.Jid {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// WaEventStream keeps the latest events for the live event streams
+type WaEventStream interface {
+	HandleEvent(event WaEvent)
+	// Subscribe returns the buffered events published after lastEventID, none
+	// when it is empty or no longer buffered, and the channel of the next
+	// events. The channel is closed when the subscriber falls behind.
+	Subscribe(lastEventID string) (backlog []WaEvent, events <-chan WaEvent, cancel func())
+}
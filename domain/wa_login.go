@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// WaLoginStatus is the status of a login attempt
+type WaLoginStatus string
+
+const (
+	WaLoginPending WaLoginStatus = "pending"
+	WaLoginSuccess WaLoginStatus = "success"
+	WaLoginTimeout WaLoginStatus = "timeout"
+	WaLoginFailed  WaLoginStatus = "failed"
+)
+
+// WaLoginAttempt is a login of a session, the QR code is refreshed until it
+// is scanned or the attempt expires. whatsapp tells nothing between the scan
+// and the end of the login, so a pending attempt goes straight to success.
+type WaLoginAttempt struct {
+	ID        string        `json:"id"`
+	SessionID string        `json:"session_id"`
+	Status    WaLoginStatus `json:"status"`
+	QrCode    string        `json:"qr_code,omitempty"`
+	QrCount   int           `json:"qr_count"`
+	Jid       string        `json:"jid,omitempty"`
+	Error     string        `json:"error,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	ExpiresAt time.Time     `json:"expires_at"`
+}
+
+// Done reports whether the attempt reached a final status.
+func (a WaLoginAttempt) Done() bool {
+	return a.Status == WaLoginSuccess || a.Status == WaLoginTimeout || a.Status == WaLoginFailed
+}
+
+// WaLoginQrCode is the JSON form of a login QR code
+type WaLoginQrCode struct {
+	AttemptID string    `json:"attempt_id"`
+	QrCode    string    `json:"qr_code"`
+	Image     string    `json:"image,omitempty" example:"data:image/png;base64,iVBORw0KGgo..."`
+	ExpiresAt time.Time `json:"expires_at"`
+}
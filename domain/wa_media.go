@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"io"
+	"time"
+)
+
+// WaMedia is a file received in a message or uploaded. The id is the SHA-256
+// of the content, the same file received twice is stored once.
+type WaMedia struct {
+	ID        string    `json:"id"`
+	MimeType  string    `json:"mime_type"`
+	FileName  string    `json:"file_name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WaMediaRepository interface {
+	// Store saves the content and its metadata, a media already stored is
+	// kept as is.
+	Store(media WaMedia, content []byte) error
+	GetByID(id string) (WaMedia, error)
+	Open(id string) (io.ReadCloser, error)
+}
+
+type WaMediaUsecase interface {
+	// Save downloads and stores the media of a message of the type (image,
+	// video, audio, document or upload), declaredSize is the size announced
+	// by the message. It fails with ErrMediaTooLarge when the size is over the limit
+	// of the type.
+	Save(mediaType, mimeType, fileName string, declaredSize uint64, download func() ([]byte, error)) (WaMedia, error)
+	Get(id string) (media WaMedia, content io.ReadCloser, err error)
+}
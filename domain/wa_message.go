@@ -0,0 +1,137 @@
+package domain
+
+import "time"
+
+// WaMessageDirection tells whether a message was received or sent
+type WaMessageDirection string
+
+const (
+	WaMessageInbound  WaMessageDirection = "inbound"
+	WaMessageOutbound WaMessageDirection = "outbound"
+)
+
+// WaMessage is the normalized form of a whatsapp message. ContactName is the
+// display name, from the contact directory, of the sender of a received
+// message or of the recipient of a sent one, ChatName the one of a group.
+type WaMessage struct {
+	ID              string             `json:"id"`
+	SessionID       string             `json:"session_id"`
+	Jid             string             `json:"jid"`
+	SenderJid       string             `json:"sender_jid,omitempty"`
+	PushName        string             `json:"push_name,omitempty"`
+	ContactName     string             `json:"contact_name,omitempty"`
+	ChatName        string             `json:"chat_name,omitempty"`
+	FromMe          bool               `json:"from_me"`
+	Direction       WaMessageDirection `json:"direction"`
+	Type            string             `json:"type" example:"text"`
+	Text            string             `json:"text,omitempty"`
+	Caption         string             `json:"caption,omitempty"`
+	MimeType        string             `json:"mime_type,omitempty"`
+	FileName        string             `json:"file_name,omitempty"`
+	MediaID         string             `json:"media_id,omitempty"`
+	Latitude        *float64           `json:"latitude,omitempty"`
+	Longitude       *float64           `json:"longitude,omitempty"`
+	DisplayName     string             `json:"display_name,omitempty"`
+	Vcard           string             `json:"vcard,omitempty"`
+	QuotedMessageID string             `json:"quoted_message_id,omitempty"`
+	Timestamp       time.Time          `json:"timestamp"`
+}
+
+// WaMessageFilter filters the stored messages, zero values match everything
+type WaMessageFilter struct {
+	SessionID string
+	Jid       string
+	Direction WaMessageDirection
+	Type      string
+	From      *time.Time
+	To        *time.Time
+	Query     string
+	Page      int
+	PerPage   int
+}
+
+type WaMessageRepository interface {
+	// Store saves the message, a message already stored is kept as is
+	Store(message WaMessage) error
+	GetByID(sessionID, id string) (WaMessage, error)
+	Fetch(filter WaMessageFilter) (messages []WaMessage, total int, err error)
+
+	// StoreStatus saves the status of a sent message, a status already stored is kept as is
+	StoreStatus(status WaMessageStatus) error
+	// UpdateStatus saves the status of a sent message, stored or not
+	UpdateStatus(status WaMessageStatus) error
+	GetStatus(sessionID, id string) (WaMessageStatus, error)
+}
+
+type WaMessageUsecase interface {
+	Fetch(filter WaMessageFilter) (messages []WaMessage, meta JSONResultMeta, err error)
+	GetByID(sessionID, id string) (WaMessage, error)
+	Status(sessionID, id string) (WaMessageStatus, error)
+
+	// HandleEvent stores the messages received by the sessions
+	HandleEvent(event WaEvent)
+}
+
+// WaMessageAck is the delivery status of a sent message
+type WaMessageAck string
+
+const (
+	WaAckPending   WaMessageAck = "pending"
+	WaAckServer    WaMessageAck = "server-ack"
+	WaAckDelivered WaMessageAck = "delivered"
+	WaAckRead      WaMessageAck = "read"
+	WaAckPlayed    WaMessageAck = "played"
+)
+
+// WaMessageAcks are the delivery statuses, in the order a message goes through them
+var WaMessageAcks = []WaMessageAck{WaAckPending, WaAckServer, WaAckDelivered, WaAckRead, WaAckPlayed}
+
+// level is the position of a in WaMessageAcks, -1 when it is unknown.
+func (a WaMessageAck) level() int {
+	for i, ack := range WaMessageAcks {
+		if ack == a {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// WaMessageStatus is the delivery status of a message sent by a session, with
+// the time each status was reached. A status the receipts skipped, eg: read
+// without delivered, keeps no time.
+type WaMessageStatus struct {
+	SessionID   string       `json:"session_id"`
+	MessageID   string       `json:"message_id"`
+	Jid         string       `json:"jid"`
+	Status      WaMessageAck `json:"status" example:"read"`
+	PendingAt   time.Time    `json:"pending_at"`
+	ServerAckAt *time.Time   `json:"server_ack_at,omitempty"`
+	DeliveredAt *time.Time   `json:"delivered_at,omitempty"`
+	ReadAt      *time.Time   `json:"read_at,omitempty"`
+	PlayedAt    *time.Time   `json:"played_at,omitempty"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// Advance moves the status forward to ack reached at at, and reports whether
+// it changed. Receipts arrive out of order, a status never goes back.
+func (s *WaMessageStatus) Advance(ack WaMessageAck, at time.Time) bool {
+	if ack.level() <= s.Status.level() {
+		return false
+	}
+
+	s.Status = ack
+	s.UpdatedAt = at
+	switch ack {
+	case WaAckServer:
+		s.ServerAckAt = &at
+	case WaAckDelivered:
+		s.DeliveredAt = &at
+	case WaAckRead:
+		s.ReadAt = &at
+	case WaAckPlayed:
+		s.PlayedAt = &at
+	}
+
+	return true
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// WaRateLimitOverflow tells what is done with the messages over the rate limit
+type WaRateLimitOverflow string
+
+const (
+	// WaRateLimitReject refuses the message, the client retries after RetryAfter
+	WaRateLimitReject WaRateLimitOverflow = "reject"
+	// WaRateLimitQueue queues the message in the send queue
+	WaRateLimitQueue WaRateLimitOverflow = "queue"
+)
+
+// WaRateLimitError is returned when sending a message would go over one of
+// the limits: per-minute, recipient or daily.
+type WaRateLimitError struct {
+	Limit      string
+	RetryAfter time.Duration
+}
+
+func (e *WaRateLimitError) Error() string {
+	return fmt.Sprintf("%s rate limit reached, retry after %s", e.Limit, e.RetryAfter.Round(time.Second))
+}
+
+type WaRateLimitRepository interface {
+	// PairedAt returns when the number of the session was paired, nil when
+	// it is not known
+	PairedAt(sessionID string) (*time.Time, error)
+	SetPairedAt(sessionID string, at time.Time) error
+	// DailyCount returns the messages sent by the session on day (2006-01-02)
+	DailyCount(sessionID, day string) (int, error)
+	IncrementDailyCount(sessionID, day string) error
+}
+
+// WaRateLimiter throttles the messages sent by the sessions so the numbers
+// are not banned for spamming.
+type WaRateLimiter interface {
+	// Reserve takes a slot to send a message of the session to jid, the
+	// message is sent once the returned delay is elapsed. A *WaRateLimitError
+	// is returned when no slot is left.
+	Reserve(sessionID, jid string) (time.Duration, error)
+	// Done ends a slot taken by Reserve: a message sent is added to the daily
+	// count of the session, a message not sent gives its daily quota back.
+	Done(sessionID string, sent bool) error
+	// Paired starts the daily warm-up of a newly paired session
+	Paired(sessionID string) error
+	Overflow() WaRateLimitOverflow
+}
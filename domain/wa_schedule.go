@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"context"
+	"mime/multipart"
+	"time"
+)
+
+// WaScheduleStatus is the status of a scheduled message
+type WaScheduleStatus string
+
+const (
+	WaScheduleActive    WaScheduleStatus = "active"
+	WaScheduleCompleted WaScheduleStatus = "completed"
+	WaScheduleCancelled WaScheduleStatus = "cancelled"
+)
+
+// WaScheduleCatchUp is what a schedule does with the runs missed while the
+// service was down
+type WaScheduleCatchUp string
+
+const (
+	// WaScheduleCatchUpSkip drops the missed runs
+	WaScheduleCatchUpSkip WaScheduleCatchUp = "skip"
+	// WaScheduleCatchUpOnce sends the message once for all the missed runs
+	WaScheduleCatchUpOnce WaScheduleCatchUp = "once"
+	// WaScheduleCatchUpAll sends the message for every missed run
+	WaScheduleCatchUpAll WaScheduleCatchUp = "all"
+)
+
+// WaSchedule is a message sent once at SendAt, or at every time of the Cron
+// expression, in Timezone. Each run queues the message in the send queue,
+// LastJobID is the send job of the last run. Skipped counts the missed runs
+// dropped by the catch-up policy.
+type WaSchedule struct {
+	ID        string            `json:"id"`
+	SessionID string            `json:"session_id"`
+	Request   WaSendRequest     `json:"request"`
+	SendAt    *time.Time        `json:"send_at,omitempty"`
+	Cron      string            `json:"cron,omitempty" example:"0 9 * * MON"`
+	Timezone  string            `json:"timezone" example:"Asia/Jakarta"`
+	CatchUp   WaScheduleCatchUp `json:"catch_up" example:"once"`
+	Status    WaScheduleStatus  `json:"status"`
+	NextRunAt *time.Time        `json:"next_run_at,omitempty"`
+	LastRunAt *time.Time        `json:"last_run_at,omitempty"`
+	Runs      int               `json:"runs"`
+	Skipped   int               `json:"skipped"`
+	LastJobID string            `json:"last_job_id,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// WaScheduleForm schedules a message. SendAt is RFC3339, or a local date time
+// in Timezone without the offset. CatchUp defaults to the configured policy.
+type WaScheduleForm struct {
+	SendAt   string
+	Cron     string
+	Timezone string
+	CatchUp  WaScheduleCatchUp
+}
+
+// WaScheduleFilter filters the scheduled messages, zero values match
+// everything
+type WaScheduleFilter struct {
+	SessionID string
+	Status    WaScheduleStatus
+	Page      int
+	PerPage   int
+}
+
+type WaScheduleRepository interface {
+	Store(schedule WaSchedule) error
+	Update(schedule WaSchedule) error
+	GetByID(id string) (WaSchedule, error)
+	Fetch(filter WaScheduleFilter) (schedules []WaSchedule, total int, err error)
+	FetchDue(now time.Time, limit int) ([]WaSchedule, error)
+}
+
+type WaScheduleUsecase interface {
+	// Schedule schedules the message to be sent by the session, file is
+	// stored in the media library first.
+	Schedule(sessionID string, request WaSendRequest, file *multipart.FileHeader, form WaScheduleForm) (WaSchedule, error)
+	Get(sessionID, id string) (WaSchedule, error)
+	Fetch(filter WaScheduleFilter) (schedules []WaSchedule, meta JSONResultMeta, err error)
+	// Cancel stops an active schedule, the messages already queued are not
+	// cancelled.
+	Cancel(sessionID, id string) (WaSchedule, error)
+
+	// Start starts queuing the due messages in the background
+	Start()
+	// Shutdown stops the scheduler until ctx is done
+	Shutdown(ctx context.Context) error
+}
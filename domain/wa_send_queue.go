@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"context"
+	"mime/multipart"
+	"time"
+)
+
+// WaSendRequest is a message of any type to be sent later, as kept by the
+// send queue. Text is the caption of the media, MediaID the media library
+// file of the image, document, audio and video messages.
+type WaSendRequest struct {
+	Type          string          `json:"type" example:"text"`
+	Msisdn        string          `json:"msisdn" example:"6281234567890"`
+	Text          string          `json:"text,omitempty"`
+	Latitude      float64         `json:"latitude,omitempty"`
+	Longitude     float64         `json:"longitude,omitempty"`
+	Contacts      []WaContactCard `json:"contacts,omitempty"`
+	MediaID       string          `json:"media_id,omitempty"`
+	MsgQuotedID   string          `json:"msg_quoted_id,omitempty"`
+	MsgQuoted     string          `json:"msg_quoted,omitempty"`
+	TypingSeconds int             `json:"typing_seconds,omitempty"`
+}
+
+// NewTextRequest returns the request of a text message.
+func NewTextRequest(form WaSendTextForm) WaSendRequest {
+	return WaSendRequest{
+		Type:          "text",
+		Msisdn:        form.Msisdn,
+		Text:          form.Text,
+		MsgQuotedID:   form.MsgQuotedID,
+		MsgQuoted:     form.MsgQuoted,
+		TypingSeconds: form.TypingSeconds,
+	}
+}
+
+// NewLocationRequest returns the request of a location message.
+func NewLocationRequest(form WaSendLocationForm) WaSendRequest {
+	return WaSendRequest{
+		Type:        "location",
+		Msisdn:      form.Msisdn,
+		Latitude:    form.Latitude,
+		Longitude:   form.Longitude,
+		MsgQuotedID: form.MsgQuotedID,
+		MsgQuoted:   form.MsgQuoted,
+	}
+}
+
+// NewContactRequest returns the request of a contact message.
+func NewContactRequest(form WaSendContactForm) WaSendRequest {
+	return WaSendRequest{
+		Type:        "contact",
+		Msisdn:      form.Msisdn,
+		Contacts:    form.Contacts,
+		MsgQuotedID: form.MsgQuotedID,
+		MsgQuoted:   form.MsgQuoted,
+	}
+}
+
+// NewFileRequest returns the request of a media message of fileType, the
+// MediaID is set once the file is stored.
+func NewFileRequest(form WaSendFileForm, fileType string) WaSendRequest {
+	return WaSendRequest{
+		Type:        fileType,
+		Msisdn:      form.Msisdn,
+		Text:        form.Message,
+		MsgQuotedID: form.MsgQuotedID,
+		MsgQuoted:   form.MsgQuoted,
+	}
+}
+
+// WaSendJobStatus is the status of a queued message
+type WaSendJobStatus string
+
+const (
+	WaSendJobQueued    WaSendJobStatus = "queued"
+	WaSendJobSending   WaSendJobStatus = "sending"
+	WaSendJobSent      WaSendJobStatus = "sent"
+	WaSendJobFailed    WaSendJobStatus = "failed"
+	WaSendJobCancelled WaSendJobStatus = "cancelled"
+)
+
+// WaSendJob is a message queued to be sent by a session, retried until it is
+// sent or runs out of attempts. MessageID is the id of the message sent.
+type WaSendJob struct {
+	ID            string          `json:"id"`
+	SessionID     string          `json:"session_id"`
+	Request       WaSendRequest   `json:"request"`
+	Status        WaSendJobStatus `json:"status"`
+	Attempts      int             `json:"attempts"`
+	MaxAttempts   int             `json:"max_attempts"`
+	MessageID     string          `json:"message_id,omitempty"`
+	LastError     string          `json:"last_error,omitempty"`
+	NextAttemptAt *time.Time      `json:"next_attempt_at,omitempty"`
+	SentAt        *time.Time      `json:"sent_at,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	AttemptLog    []WaSendAttempt `json:"attempt_log,omitempty"`
+}
+
+// WaSendAttempt is one attempt to send a queued message, Error is empty when
+// the message was sent.
+type WaSendAttempt struct {
+	JobID      string    `json:"job_id"`
+	Attempt    int       `json:"attempt"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// WaSendJobFilter filters the queued messages, zero values match everything
+type WaSendJobFilter struct {
+	SessionID string
+	Status    WaSendJobStatus
+	Page      int
+	PerPage   int
+}
+
+type WaSendQueueRepository interface {
+	Store(job WaSendJob) error
+	Update(job WaSendJob) error
+	GetByID(id string) (WaSendJob, error)
+	Fetch(filter WaSendJobFilter) (jobs []WaSendJob, total int, err error)
+	FetchDue(now time.Time, limit int) ([]WaSendJob, error)
+	// Requeue queues again the jobs left sending by a stop
+	Requeue() error
+
+	StoreAttempt(attempt WaSendAttempt) error
+	FetchAttempts(jobID string) ([]WaSendAttempt, error)
+}
+
+type WaSendQueueUsecase interface {
+	// Enqueue queues the message to be sent by the session, file is stored in
+	// the media library first.
+	Enqueue(sessionID string, request WaSendRequest, file *multipart.FileHeader) (WaSendJob, error)
+	// Get returns the job with its attempts
+	Get(sessionID, id string) (WaSendJob, error)
+	Fetch(filter WaSendJobFilter) (jobs []WaSendJob, meta JSONResultMeta, err error)
+	// Cancel drops a queued job, a job being sent or already sent can not be
+	// cancelled.
+	Cancel(sessionID, id string) (WaSendJob, error)
+	// Retry queues a failed or cancelled job again, with a new set of attempts
+	Retry(sessionID, id string) (WaSendJob, error)
+
+	// Start starts sending the queued messages in the background
+	Start()
+	// Shutdown stops the send workers, waiting for the messages being sent
+	// until ctx is done. The queued messages are sent on the next start.
+	Shutdown(ctx context.Context) error
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// WebhookDeliveryStatus is the status of a webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending WebhookDeliveryStatus = "pending"
+	WebhookDeliverySuccess WebhookDeliveryStatus = "success"
+	WebhookDeliveryFailed  WebhookDeliveryStatus = "failed"
+)
+
+// Webhook is a subscription to the events of the sessions. Empty filters
+// match everything.
+type Webhook struct {
+	ID         string        `json:"id"`
+	URL        string        `json:"url"`
+	Secret     string        `json:"-"`
+	SessionID  string        `json:"session_id,omitempty"`
+	EventTypes []WaEventType `json:"event_types"`
+	Jids       []string      `json:"jids"`
+	Active     bool          `json:"active"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+// WebhookCreated is a created webhook with its signing secret, the secret is
+// never answered again.
+type WebhookCreated struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+// Match reports whether the event is delivered to the webhook.
+func (w Webhook) Match(event WaEvent) bool {
+	if !w.Active {
+		return false
+	}
+
+	return WaEventFilter{SessionID: w.SessionID, Types: w.EventTypes, Jids: w.Jids}.Match(event)
+}
+
+// WebhookForm creates or updates a webhook
+type WebhookForm struct {
+	URL        string        `json:"url" form:"url" validate:"required,url" example:"https://example.com/whatsapp/webhook"`
+	Secret     string        `json:"secret" form:"secret" example:"my-signing-secret"`
+	SessionID  string        `json:"session_id" form:"session_id" example:"default"`
+	EventTypes []WaEventType `json:"event_types" form:"event_types" example:"message.text,message.image"`
+	Jids       []string      `json:"jids" form:"jids" example:"6281234567890@s.whatsapp.net"`
+	Active     *bool         `json:"active" form:"active"`
+}
+
+// WebhookDelivery is one event sent to one webhook, retried until it is
+// accepted or runs out of attempts.
+type WebhookDelivery struct {
+	ID             string                `json:"id"`
+	WebhookID      string                `json:"webhook_id"`
+	EventID        string                `json:"event_id"`
+	EventType      WaEventType           `json:"event_type"`
+	Payload        string                `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	ResponseStatus int                   `json:"response_status,omitempty"`
+	LastError      string                `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time            `json:"next_attempt_at,omitempty"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+}
+
+// WebhookDeliveryFilter filters the webhook delivery log
+type WebhookDeliveryFilter struct {
+	WebhookID string
+	Status    WebhookDeliveryStatus
+	Page      int
+	PerPage   int
+}
+
+type WebhookRepository interface {
+	Store(webhook Webhook) error
+	Update(webhook Webhook) error
+	Delete(id string) error
+	GetByID(id string) (Webhook, error)
+	Fetch() ([]Webhook, error)
+
+	StoreDelivery(delivery WebhookDelivery) error
+	UpdateDelivery(delivery WebhookDelivery) error
+	GetDeliveryByID(id string) (WebhookDelivery, error)
+	FetchDeliveries(filter WebhookDeliveryFilter) (deliveries []WebhookDelivery, total int, err error)
+	FetchDueDeliveries(now time.Time, limit int) ([]WebhookDelivery, error)
+}
+
+type WebhookUsecase interface {
+	Create(form WebhookForm) (Webhook, error)
+	Update(id string, form WebhookForm) (Webhook, error)
+	Delete(id string) error
+	Get(id string) (Webhook, error)
+	Fetch() ([]Webhook, error)
+
+	Deliveries(filter WebhookDeliveryFilter) (deliveries []WebhookDelivery, meta JSONResultMeta, err error)
+	Redeliver(deliveryID string) (WebhookDelivery, error)
+
+	// HandleEvent queues a delivery of the event for every matching webhook
+	HandleEvent(event WaEvent)
+	// Start starts delivering the queued deliveries in the background
+	Start()
+	// Shutdown stops the delivery workers, waiting for the running deliveries
+	// until ctx is done. Undelivered events are retried on the next start.
+	Shutdown(ctx context.Context) error
+}
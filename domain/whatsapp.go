@@ -1,8 +1,10 @@
 package domain
 
 import (
+	"context"
 	"encoding/json"
 	"mime/multipart"
+	"time"
 )
 
 type WaSendTextForm struct {
@@ -10,6 +12,8 @@ type WaSendTextForm struct {
 	Text        string `json:"text" validate:"required"`
 	MsgQuotedID string `json:"msg_quoted_id"`
 	MsgQuoted   string `json:"msg_quoted"`
+	// TypingSeconds shows the session typing in the chat before the text is sent
+	TypingSeconds int `json:"typing_seconds" validate:"min=0,max=60"`
 }
 
 type WaSendLocationForm struct {
@@ -29,6 +33,25 @@ type WaSendFileForm struct {
 	FileHeader  *multipart.FileHeader
 }
 
+// WaContactCard is a contact shared as a vCard. Vcard is a raw vCard, the
+// other fields are ignored when it is set.
+type WaContactCard struct {
+	Name         string   `json:"name,omitempty" example:"Budi Santoso"`
+	Phones       []string `json:"phones,omitempty" example:"6281234567890"`
+	Emails       []string `json:"emails,omitempty" example:"budi@example.com"`
+	Organization string   `json:"organization,omitempty" example:"Cooljar"`
+	Vcard        string   `json:"vcard,omitempty"`
+}
+
+// WaSendContactForm sends the contacts, several contacts are sent at once in
+// a single message.
+type WaSendContactForm struct {
+	Msisdn      string          `json:"msisdn" validate:"required"`
+	Contacts    []WaContactCard `json:"contacts" validate:"required,min=1,max=20"`
+	MsgQuotedID string          `json:"msg_quoted_id"`
+	MsgQuoted   string          `json:"msg_quoted"`
+}
+
 type WaWebServer struct {
 	Version struct {
 		Major int
@@ -70,14 +93,122 @@ func (w *WhatsappWeb) ToJSON() []byte {
 	return str
 }
 
+// WaConnectionState is the state of a session connection
+type WaConnectionState string
+
+const (
+	WaStateDisconnected WaConnectionState = "disconnected"
+	WaStateConnected    WaConnectionState = "connected"
+	WaStateReconnecting WaConnectionState = "reconnecting"
+	WaStateLoggedOut    WaConnectionState = "logged-out"
+)
+
+// WaConnectionStatus is the connection state of a session as kept by its supervisor
+type WaConnectionStatus struct {
+	SessionID            string            `json:"session_id"`
+	State                WaConnectionState `json:"state"`
+	ReconnectAttempts    int               `json:"reconnect_attempts"`
+	MaxReconnectAttempts int               `json:"max_reconnect_attempts"`
+	LastError            string            `json:"last_error,omitempty"`
+	DisconnectedAt       *time.Time        `json:"disconnected_at,omitempty"`
+	NextAttemptAt        *time.Time        `json:"next_attempt_at,omitempty"`
+}
+
+// WaBattery is the phone battery as last reported by the phone
+type WaBattery struct {
+	Percentage int       `json:"percentage"`
+	Plugged    bool      `json:"plugged"`
+	Powersave  bool      `json:"powersave"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// WaStatus is the state of a session and of the phone behind it
+type WaStatus struct {
+	SessionID     string             `json:"session_id"`
+	Connection    WaConnectionStatus `json:"connection"`
+	Connected     bool               `json:"connected"`
+	LoggedIn      bool               `json:"logged_in"`
+	Jid           string             `json:"jid,omitempty"`
+	PushName      string             `json:"push_name,omitempty"`
+	LastPingAt    *time.Time         `json:"last_ping_at,omitempty"`
+	LastPingError string             `json:"last_ping_error,omitempty"`
+	Battery       *WaBattery         `json:"battery,omitempty"`
+}
+
+// Ready reports whether the session can send messages.
+func (s WaStatus) Ready() bool {
+	return s.Connected && s.LoggedIn
+}
+
+// WaPresence is the presence of a session, seen by its contacts
+type WaPresence string
+
+const (
+	WaPresenceAvailable   WaPresence = "available"
+	WaPresenceUnavailable WaPresence = "unavailable"
+)
+
+// Valid reports whether p is a presence a session can set.
+func (p WaPresence) Valid() bool {
+	return p == WaPresenceAvailable || p == WaPresenceUnavailable
+}
+
+// WaChatState is what a session is doing in a chat, seen by the chat
+type WaChatState string
+
+const (
+	WaChatComposing WaChatState = "composing"
+	WaChatRecording WaChatState = "recording"
+	WaChatPaused    WaChatState = "paused"
+)
+
+// Valid reports whether s is a chat state a session can send.
+func (s WaChatState) Valid() bool {
+	return s == WaChatComposing || s == WaChatRecording || s == WaChatPaused
+}
+
+// DefaultSessionID is the session served by the routes without a session_id
+const DefaultSessionID = "default"
+
 // WhatsappUsecase represent the whatsapp's use cases
 type WhatsappUsecase interface {
+	SessionID() string
 	RestoreSession() error
-	Login(vMajor, vMinor, vBuild, timeout, reconnect int, clientNameShort, clientNameLong string) (qrCode string, err error)
+	Login(vMajor, vMinor, vBuild, timeout, reconnect, loginTimeout int, clientNameShort, clientNameLong string) (attempt WaLoginAttempt, err error)
+	LoginAttempt(id string) (attempt WaLoginAttempt, err error)
+	SubscribeLoginAttempt(id string) (updates <-chan WaLoginAttempt, cancel func(), err error)
 	GetInfo() (info WaWeb, err error)
 	SendText(form WaSendTextForm) (msgId string, err error)
 	SendLocation(form WaSendLocationForm) (msgId string, err error)
 	SendFile(form WaSendFileForm, fileType string) (msgId string, err error)
+	SendContacts(form WaSendContactForm) (msgId string, err error)
 	Logout() (err error)
 	Groups(jid string) (g string, err error)
+	Chats(filter WaChatFilter) (chats []WaChat, meta JSONResultMeta, err error)
+	// ChatMessages loads from the phone the count messages of the chat jid
+	// sent before the message before, the latest ones when it is empty.
+	ChatMessages(jid, before string, count int) (messages []WaMessage, meta WaChatHistoryMeta, err error)
+	// MarkRead marks the chat jid read up to the message messageID, its
+	// latest message when it is empty, and returns the message marked.
+	MarkRead(jid, messageID string) (string, error)
+	SetPresence(presence WaPresence) error
+	SendChatState(jid string, state WaChatState) error
+	ConnectionStatus() WaConnectionStatus
+	Status() WaStatus
+	// StopSending stops accepting messages, the ones waiting for a rate
+	// limiter slot fail with ErrShuttingDown. The connection is kept.
+	StopSending()
+	Shutdown(ctx context.Context) error
+}
+
+// WhatsappSessionManager represent the registry of named whatsapp sessions
+type WhatsappSessionManager interface {
+	Get(sessionID string) (WhatsappUsecase, error)
+	GetOrCreate(sessionID string) (WhatsappUsecase, error)
+	List() []string
+	RestoreAll() error
+	// StopSending stops every session from sending, before the send queue
+	// and the campaigns are drained.
+	StopSending()
+	Shutdown(ctx context.Context) error
 }
@@ -0,0 +1,178 @@
+package http
+
+import (
+	"errors"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AutoReplyHandler struct {
+	AutoReplyUsecase domain.AutoReplyUsecase
+	Validate         *validator.Validate
+}
+
+func NewAutoReplyHandler(autoReplyUsecase domain.AutoReplyUsecase, rPublic, rPrivate fiber.Router) {
+	handler := &AutoReplyHandler{
+		AutoReplyUsecase: autoReplyUsecase,
+		Validate:         utils.NewValidator(),
+	}
+
+	rAutoReply := rPublic.Group("/auto-replies")
+	rAutoReply.Get("/", handler.Fetch)
+	rAutoReply.Post("/", handler.Create)
+	rAutoReply.Get("/:id", handler.Get)
+	rAutoReply.Put("/:id", handler.Update)
+	rAutoReply.Delete("/:id", handler.Delete)
+}
+
+// Fetch func for list auto-reply rules.
+// @Summary list auto-reply rules
+// @Description List the auto-reply rules by priority.
+// @Tags Auto Reply
+// @Produce json
+// @Success 200 {object} domain.JSONResult{data=[]domain.AutoReplyRule,message=string} "Description"
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/auto-replies [get]
+func (h *AutoReplyHandler) Fetch(c *fiber.Ctx) error {
+	rules, err := h.AutoReplyUsecase.Fetch()
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    rules,
+		Message: "Success",
+	})
+}
+
+// Create func for create an auto-reply rule.
+// @Summary create auto-reply rule
+// @Description Answer the received text messages matching the pattern: exact (whole text), contains or regex. The first active rule
+// @Description matching, lowest priority first, answers. Empty jids match every chat, a jid matches the chat or the sender in groups.
+// @Description time_start and time_end (HH:MM) and days (0 is Sunday) limit when the rule answers, in timezone (default to UTC).
+// @Description cooldown_seconds is the time a contact waits before the rule answers it again.
+// @Description The response is a text, a location or a media of the media library (image, document, audio, video) with text as caption.
+// @Tags Auto Reply
+// @Accept json
+// @Produce json
+// @Param rule body domain.AutoReplyRuleForm true "Rule"
+// @Success 201 {object} domain.JSONResult{data=domain.AutoReplyRule,message=string} "Description"
+// @Failure 422 {object} []domain.HTTPErrorValidation
+// @Failure 400 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/auto-replies [post]
+func (h *AutoReplyHandler) Create(c *fiber.Ctx) error {
+	var form domain.AutoReplyRuleForm
+	err := c.BodyParser(&form)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	err = h.Validate.Struct(&form)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ValidatorErrors(err))
+	}
+
+	rule, err := h.AutoReplyUsecase.Create(form)
+	if err != nil {
+		return autoReplyError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(domain.JSONResult{
+		Data:    rule,
+		Message: "Success",
+	})
+}
+
+// Get func for get an auto-reply rule.
+// @Summary get auto-reply rule
+// @Description Get an auto-reply rule.
+// @Tags Auto Reply
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Success 200 {object} domain.JSONResult{data=domain.AutoReplyRule,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/auto-replies/{id} [get]
+func (h *AutoReplyHandler) Get(c *fiber.Ctx) error {
+	rule, err := h.AutoReplyUsecase.Get(c.Params("id"))
+	if err != nil {
+		return autoReplyError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    rule,
+		Message: "Success",
+	})
+}
+
+// Update func for update an auto-reply rule.
+// @Summary update auto-reply rule
+// @Description Replace an auto-reply rule, active is kept when omitted.
+// @Tags Auto Reply
+// @Accept json
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Param rule body domain.AutoReplyRuleForm true "Rule"
+// @Success 200 {object} domain.JSONResult{data=domain.AutoReplyRule,message=string} "Description"
+// @Failure 422 {object} []domain.HTTPErrorValidation
+// @Failure 400 {object} domain.HTTPError
+// @Failure 404 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/auto-replies/{id} [put]
+func (h *AutoReplyHandler) Update(c *fiber.Ctx) error {
+	var form domain.AutoReplyRuleForm
+	err := c.BodyParser(&form)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	err = h.Validate.Struct(&form)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ValidatorErrors(err))
+	}
+
+	rule, err := h.AutoReplyUsecase.Update(c.Params("id"), form)
+	if err != nil {
+		return autoReplyError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    rule,
+		Message: "Success",
+	})
+}
+
+// Delete func for delete an auto-reply rule.
+// @Summary delete auto-reply rule
+// @Description Delete an auto-reply rule.
+// @Tags Auto Reply
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Success 200 {object} domain.JSONResult{data=object,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/auto-replies/{id} [delete]
+func (h *AutoReplyHandler) Delete(c *fiber.Ctx) error {
+	err := h.AutoReplyUsecase.Delete(c.Params("id"))
+	if err != nil {
+		return autoReplyError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Message: "Success",
+	})
+}
+
+func autoReplyError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrAutoReplyRuleNotFound):
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	case errors.Is(err, domain.ErrInvalidAutoReplyRule):
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+}
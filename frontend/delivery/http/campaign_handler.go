@@ -0,0 +1,292 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"mime"
+	"time"
+)
+
+type CampaignHandler struct {
+	CampaignUsecase domain.CampaignUsecase
+	Validate        *validator.Validate
+}
+
+func NewCampaignHandler(campaignUsecase domain.CampaignUsecase, rPublic, rPrivate fiber.Router) {
+	handler := &CampaignHandler{
+		CampaignUsecase: campaignUsecase,
+		Validate:        utils.NewValidator(),
+	}
+
+	// Like the whatsapp endpoints, campaigns are served for the default
+	// session and, under /sessions/:session_id, for any named session.
+	rWa := rPublic.Group("/whatsapp")
+	for _, r := range []fiber.Router{rWa, rWa.Group("/sessions/:session_id")} {
+		r.Get("/campaigns", handler.Fetch)
+		r.Post("/campaigns", handler.Create)
+		r.Get("/campaigns/:id", handler.Get)
+		r.Get("/campaigns/:id/recipients", handler.Recipients)
+		r.Get("/campaigns/:id/report", handler.Report)
+		r.Post("/campaigns/:id/pause", handler.Pause)
+		r.Post("/campaigns/:id/resume", handler.Resume)
+		r.Post("/campaigns/:id/cancel", handler.Cancel)
+	}
+}
+
+// Fetch func for list campaigns.
+// @Summary list campaigns
+// @Description List the broadcast campaigns of the session, newest first, with their progress.
+// @Tags Campaign
+// @Produce json
+// @Param page query int false "Page, default 1"
+// @Param per_page query int false "Campaigns per page, default 20, max 100"
+// @Success 200 {object} domain.JSONResult{data=[]domain.Campaign,meta=domain.JSONResultMeta,message=string} "Description"
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/whatsapp/campaigns [get]
+func (h *CampaignHandler) Fetch(c *fiber.Ctx) error {
+	campaigns, meta, err := h.CampaignUsecase.Fetch(c.Params("session_id", domain.DefaultSessionID),
+		queryInt(c, "page", 1), queryInt(c, "per_page", 20))
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    campaigns,
+		Meta:    meta,
+		Message: "Success",
+	})
+}
+
+// Create func for create a campaign.
+// @Summary create campaign
+// @Description Broadcast a message to the recipients of a CSV file. The CSV header names the columns, msisdn is required
+// @Description and every column is a variable: {{name}} in the text is replaced with the name column of the recipient.
+// @Description A media campaign sends the uploaded file, or the media_id of the media library, with the text as caption.
+// @Description The campaign starts at scheduled_at, right away without it, and its messages are sent one at a time
+// @Description within the rate limits of the session.
+// @Tags Campaign
+// @Accept mpfd
+// @Produce json
+// @Param name formData string true "Campaign name"
+// @Param type formData string true "Message type" Enums(text, image, document, audio, video)
+// @Param text formData string false "Message text or caption, eg: Hello {{name}}, your order {{order}} is ready"
+// @Param recipients formData file true "Recipients CSV"
+// @Param file formData file false "Media file"
+// @Param media_id formData string false "Media library ID, instead of the file"
+// @Param scheduled_at formData string false "Start time, RFC3339, eg: 2021-07-01T09:00:00+07:00"
+// @Success 201 {object} domain.JSONResult{data=domain.Campaign,message=string} "Description"
+// @Failure 422 {object} []domain.HTTPErrorValidation
+// @Failure 400 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/whatsapp/campaigns [post]
+func (h *CampaignHandler) Create(c *fiber.Ctx) error {
+	var form domain.CampaignForm
+	form.Name = c.FormValue("name")
+	form.Type = c.FormValue("type")
+	form.Text = c.FormValue("text")
+	form.MediaID = c.FormValue("media_id")
+
+	if v := c.FormValue("scheduled_at"); v != "" {
+		scheduledAt, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+		}
+		form.ScheduledAt = &scheduledAt
+	}
+
+	err := h.Validate.Struct(&form)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ValidatorErrors(err))
+	}
+
+	recipientsHeader, err := c.FormFile("recipients")
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	recipients, err := recipientsHeader.Open()
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+	defer recipients.Close()
+
+	// The media file is optional
+	file, _ := c.FormFile("file")
+
+	campaign, err := h.CampaignUsecase.Create(c.Params("session_id", domain.DefaultSessionID), form, recipients, file)
+	if err != nil {
+		return campaignError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(domain.JSONResult{
+		Data:    campaign,
+		Message: "Success",
+	})
+}
+
+// Get func for get a campaign.
+// @Summary get campaign
+// @Description Get a campaign with its progress: the recipients queued, sent, failed and cancelled.
+// @Tags Campaign
+// @Produce json
+// @Param id path string true "Campaign ID"
+// @Success 200 {object} domain.JSONResult{data=domain.Campaign,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/whatsapp/campaigns/{id} [get]
+func (h *CampaignHandler) Get(c *fiber.Ctx) error {
+	campaign, err := h.CampaignUsecase.Get(c.Params("session_id", domain.DefaultSessionID), c.Params("id"))
+	if err != nil {
+		return campaignError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    campaign,
+		Message: "Success",
+	})
+}
+
+// Recipients func for list the recipients of a campaign.
+// @Summary list campaign recipients
+// @Description List the recipients of a campaign in the CSV order, with the status, message id or error of their message.
+// @Tags Campaign
+// @Produce json
+// @Param id path string true "Campaign ID"
+// @Param status query string false "Recipient status" Enums(queued, sending, sent, failed, cancelled)
+// @Param page query int false "Page, default 1"
+// @Param per_page query int false "Recipients per page, default 20, max 100"
+// @Success 200 {object} domain.JSONResult{data=[]domain.CampaignRecipient,meta=domain.JSONResultMeta,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/whatsapp/campaigns/{id}/recipients [get]
+func (h *CampaignHandler) Recipients(c *fiber.Ctx) error {
+	filter := domain.CampaignRecipientFilter{
+		CampaignID: c.Params("id"),
+		Status:     domain.CampaignRecipientStatus(c.Query("status")),
+		Page:       queryInt(c, "page", 1),
+		PerPage:    queryInt(c, "per_page", 20),
+	}
+
+	recipients, meta, err := h.CampaignUsecase.Recipients(c.Params("session_id", domain.DefaultSessionID), filter)
+	if err != nil {
+		return campaignError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    recipients,
+		Meta:    meta,
+		Message: "Success",
+	})
+}
+
+// Report func for export the result of a campaign.
+// @Summary export campaign report
+// @Description Export the result of every recipient as CSV: row, msisdn, status, message_id, error and sent_at.
+// @Tags Campaign
+// @Produce text/csv
+// @Param id path string true "Campaign ID"
+// @Success 200 {file} file "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/whatsapp/campaigns/{id}/report [get]
+func (h *CampaignHandler) Report(c *fiber.Ctx) error {
+	var report bytes.Buffer
+	err := h.CampaignUsecase.Report(c.Params("session_id", domain.DefaultSessionID), c.Params("id"), &report)
+	if err != nil {
+		return campaignError(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{
+		"filename": "campaign-" + c.Params("id") + ".csv",
+	}))
+
+	return c.Send(report.Bytes())
+}
+
+// Pause func for pause a campaign.
+// @Summary pause campaign
+// @Description Pause a running or scheduled campaign, the message being sent is still sent.
+// @Tags Campaign
+// @Produce json
+// @Param id path string true "Campaign ID"
+// @Success 200 {object} domain.JSONResult{data=domain.Campaign,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 409 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/whatsapp/campaigns/{id}/pause [post]
+func (h *CampaignHandler) Pause(c *fiber.Ctx) error {
+	campaign, err := h.CampaignUsecase.Pause(c.Params("session_id", domain.DefaultSessionID), c.Params("id"))
+	if err != nil {
+		return campaignError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    campaign,
+		Message: "Success",
+	})
+}
+
+// Resume func for resume a campaign.
+// @Summary resume campaign
+// @Description Resume a paused campaign, it waits for its scheduled_at when it is not reached yet.
+// @Tags Campaign
+// @Produce json
+// @Param id path string true "Campaign ID"
+// @Success 200 {object} domain.JSONResult{data=domain.Campaign,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 409 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/whatsapp/campaigns/{id}/resume [post]
+func (h *CampaignHandler) Resume(c *fiber.Ctx) error {
+	campaign, err := h.CampaignUsecase.Resume(c.Params("session_id", domain.DefaultSessionID), c.Params("id"))
+	if err != nil {
+		return campaignError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    campaign,
+		Message: "Success",
+	})
+}
+
+// Cancel func for cancel a campaign.
+// @Summary cancel campaign
+// @Description Cancel a campaign, the recipients not sent yet are cancelled.
+// @Tags Campaign
+// @Produce json
+// @Param id path string true "Campaign ID"
+// @Success 200 {object} domain.JSONResult{data=domain.Campaign,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 409 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/whatsapp/campaigns/{id}/cancel [post]
+func (h *CampaignHandler) Cancel(c *fiber.Ctx) error {
+	campaign, err := h.CampaignUsecase.Cancel(c.Params("session_id", domain.DefaultSessionID), c.Params("id"))
+	if err != nil {
+		return campaignError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    campaign,
+		Message: "Success",
+	})
+}
+
+func campaignError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrCampaignNotFound):
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	case errors.Is(err, domain.ErrCampaignState):
+		return domain.NewHttpError(c, fiber.StatusConflict, err)
+	case errors.Is(err, domain.ErrInvalidCampaign), errors.Is(err, domain.ErrMediaNotFound),
+		errors.Is(err, domain.ErrMediaTooLarge):
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+}
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+)
+
+type ContactHandler struct {
+	ContactUsecase domain.WaContactUsecase
+}
+
+func NewContactHandler(contactUsecase domain.WaContactUsecase, rPublic, rPrivate fiber.Router) {
+	handler := &ContactHandler{
+		ContactUsecase: contactUsecase,
+	}
+
+	// Like the messages, contacts are served for the default session and,
+	// under /sessions/:session_id, for any named session.
+	rWa := rPublic.Group("/whatsapp")
+	for _, r := range []fiber.Router{rWa, rWa.Group("/sessions/:session_id")} {
+		r.Get("/contacts", handler.Fetch)
+		r.Get("/contacts/:jid", handler.Get)
+	}
+}
+
+// Fetch func for list and search the contacts.
+// @Summary list contacts
+// @Description List the contacts and groups known by the session, by display name. The directory is filled from the phone contacts,
+// @Description the chats and the push names of the messages received.
+// @Tags Contact
+// @Produce json
+// @Param q query string false "Search in the jid and the names"
+// @Param business query bool false "Only the business accounts, or only the others"
+// @Param page query int false "Page, default 1"
+// @Param per_page query int false "Contacts per page, default 20, max 100"
+// @Success 200 {object} domain.JSONResult{data=[]domain.WaContact,meta=domain.JSONResultMeta,message=string} "Description"
+// @Failure 400 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/whatsapp/contacts [get]
+func (h *ContactHandler) Fetch(c *fiber.Ctx) error {
+	filter := domain.WaContactFilter{
+		SessionID: c.Params("session_id", domain.DefaultSessionID),
+		Query:     c.Query("q"),
+		Page:      queryInt(c, "page", 1),
+		PerPage:   queryInt(c, "per_page", 20),
+	}
+
+	if v := c.Query("business"); v != "" {
+		business, err := strconv.ParseBool(v)
+		if err != nil {
+			return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+		}
+		filter.Business = &business
+	}
+
+	contacts, meta, err := h.ContactUsecase.Fetch(filter)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    contacts,
+		Meta:    meta,
+		Message: "Success",
+	})
+}
+
+// Get func for get a contact.
+// @Summary get contact
+// @Description Get a contact, or a group, by jid or msisdn.
+// @Tags Contact
+// @Produce json
+// @Param jid path string true "JID or msisdn, eg: 6281234567890@s.whatsapp.net"
+// @Success 200 {object} domain.JSONResult{data=domain.WaContact,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/whatsapp/contacts/{jid} [get]
+func (h *ContactHandler) Get(c *fiber.Ctx) error {
+	contact, err := h.ContactUsecase.Get(c.Params("session_id", domain.DefaultSessionID), c.Params("jid"))
+	if err == domain.ErrContactNotFound {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    contact,
+		Message: "Success",
+	})
+}
@@ -0,0 +1,152 @@
+package http
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+	"strings"
+	"time"
+)
+
+type EventHandler struct {
+	EventStream domain.WaEventStream
+}
+
+func NewEventHandler(eventStream domain.WaEventStream, rPublic, rPrivate fiber.Router) {
+	handler := &EventHandler{
+		EventStream: eventStream,
+	}
+
+	rPrivate.Get("/events", handler.Events)
+}
+
+// Events func for stream the events of the sessions.
+// @Summary stream events
+// @Description Stream the events of the sessions live, over a WebSocket when the request is an upgrade and as Server-Sent Events otherwise.
+// @Description Each WebSocket text message or SSE data line is a domain.WaEvent as JSON, the SSE id is the event id.
+// @Description To resume after a disconnect pass the id of the last event received in last_event_id or, for SSE, the Last-Event-ID header
+// @Description browsers send on their own. Only the latest events are kept for resuming. The JWT can be passed in the access_token query
+// @Description parameter, browsers can not set the Authorization header on EventSource and WebSocket requests.
+// @Tags Event
+// @Produce text/event-stream
+// @Param session_id query string false "Only the events of this session"
+// @Param types query string false "Comma separated event types, eg: message.text,connection"
+// @Param jids query string false "Comma separated chat JIDs"
+// @Param last_event_id query string false "Resume after this event"
+// @Param access_token query string false "JWT, instead of the Authorization header"
+// @Success 200 {object} domain.WaEvent "Description"
+// @Failure 400 {object} domain.HTTPError
+// @Failure 401 {object} domain.HTTPError
+// @Security ApiKeyAuth
+// @Router /v1/auth/events [get]
+func (h *EventHandler) Events(c *fiber.Ctx) error {
+	filter := domain.WaEventFilter{
+		SessionID: c.Query("session_id"),
+		Jids:      splitQuery(c.Query("jids")),
+	}
+	for _, t := range splitQuery(c.Query("types")) {
+		eventType := domain.WaEventType(t)
+		if !eventType.Valid() {
+			return domain.NewHttpError(c, fiber.StatusBadRequest, fmt.Errorf("%w: %s", domain.ErrInvalidEventType, t))
+		}
+		filter.Types = append(filter.Types, eventType)
+	}
+
+	lastEventID := c.Query("last_event_id", c.Get("Last-Event-ID"))
+
+	if websocket.IsWebSocketUpgrade(c) {
+		return websocket.New(func(conn *websocket.Conn) {
+			backlog, events, cancel := h.EventStream.Subscribe(lastEventID)
+			defer cancel()
+
+			// Reading detects the client going away, the messages are ignored
+			closed := make(chan struct{})
+			go func() {
+				defer close(closed)
+				for {
+					if _, _, err := conn.ReadMessage(); err != nil {
+						return
+					}
+				}
+			}()
+
+			streamEvents(filter, backlog, events, closed, func(event domain.WaEvent) error {
+				return conn.WriteJSON(event)
+			}, func() error {
+				return conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(sseKeepAlive))
+			})
+		})(c)
+	}
+
+	backlog, events, cancel := h.EventStream.Subscribe(lastEventID)
+
+	setSSEHeaders(c)
+	c.Context().SetBodyStreamWriter(func(bw *bufio.Writer) {
+		defer cancel()
+
+		streamEvents(filter, backlog, events, nil, func(event domain.WaEvent) error {
+			return writeSSE(bw, event.ID, "", event)
+		}, func() error {
+			return writeSSEKeepAlive(bw)
+		})
+	})
+
+	return nil
+}
+
+// streamEvents writes the backlog then the events matching the filter until
+// the stream ends, closed is closed or a write fails.
+func streamEvents(filter domain.WaEventFilter, backlog []domain.WaEvent, events <-chan domain.WaEvent, closed <-chan struct{},
+	write func(event domain.WaEvent) error, keepAlive func() error) {
+	// Nothing reaches the client before the first flush, not even the headers
+	if err := keepAlive(); err != nil {
+		return
+	}
+
+	for _, event := range backlog {
+		if !filter.Match(event) {
+			continue
+		}
+		if err := write(event); err != nil {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(sseKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if !filter.Match(event) {
+				continue
+			}
+			if err := write(event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := keepAlive(); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+
+// splitQuery splits a comma separated query parameter, nil when it is empty.
+func splitQuery(v string) []string {
+	var values []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			values = append(values, s)
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,236 @@
+package http
+
+import (
+	"errors"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"gopkg.in/yaml.v2"
+	"strings"
+)
+
+type FlowHandler struct {
+	FlowUsecase domain.FlowUsecase
+	Validate    *validator.Validate
+}
+
+func NewFlowHandler(flowUsecase domain.FlowUsecase, rPublic, rPrivate fiber.Router) {
+	handler := &FlowHandler{
+		FlowUsecase: flowUsecase,
+		Validate:    utils.NewValidator(),
+	}
+
+	rFlow := rPublic.Group("/flows")
+	rFlow.Get("/", handler.Fetch)
+	rFlow.Post("/", handler.Create)
+	rFlow.Delete("/conversations/:conversation_id", handler.EndConversation)
+	rFlow.Get("/:id", handler.Get)
+	rFlow.Put("/:id", handler.Update)
+	rFlow.Delete("/:id", handler.Delete)
+	rFlow.Get("/:id/conversations", handler.Conversations)
+}
+
+// Fetch func for list flows.
+// @Summary list flows
+// @Description List the conversational flows by priority.
+// @Tags Flow
+// @Produce json
+// @Success 200 {object} domain.JSONResult{data=[]domain.Flow,message=string} "Description"
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/flows [get]
+func (h *FlowHandler) Fetch(c *fiber.Ctx) error {
+	flows, err := h.FlowUsecase.Fetch()
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    flows,
+		Message: "Success",
+	})
+}
+
+// Create func for create a flow.
+// @Summary create flow
+// @Description Create a multi-step conversation, sent as JSON or as YAML with a yaml content type (e.g. application/x-yaml).
+// @Description A received text matching the trigger starts the flow at the start state for the contact. Entering a state sends its
+// @Description prompt, the answer must match validate, is saved in the variable named save and moves the conversation to the next
+// @Description state of the first matching branch, or to next. A state without branches and next ends the conversation.
+// @Description Prompts can use {{variable}}, push_name is set when the flow starts. A contact not answering in timeout_seconds
+// @Description moves to timeout_next, or leaves the flow. While a contact is in a flow its messages are not auto-replied.
+// @Tags Flow
+// @Accept json,application/x-yaml
+// @Produce json
+// @Param flow body domain.FlowForm true "Flow"
+// @Success 201 {object} domain.JSONResult{data=domain.Flow,message=string} "Description"
+// @Failure 422 {object} []domain.HTTPErrorValidation
+// @Failure 400 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/flows [post]
+func (h *FlowHandler) Create(c *fiber.Ctx) error {
+	var form domain.FlowForm
+	err := parseFlowForm(c, &form)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	err = h.Validate.Struct(&form)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ValidatorErrors(err))
+	}
+
+	flow, err := h.FlowUsecase.Create(form)
+	if err != nil {
+		return flowError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(domain.JSONResult{
+		Data:    flow,
+		Message: "Success",
+	})
+}
+
+// Get func for get a flow.
+// @Summary get flow
+// @Description Get a flow.
+// @Tags Flow
+// @Produce json
+// @Param id path string true "Flow ID"
+// @Success 200 {object} domain.JSONResult{data=domain.Flow,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/flows/{id} [get]
+func (h *FlowHandler) Get(c *fiber.Ctx) error {
+	flow, err := h.FlowUsecase.Get(c.Params("id"))
+	if err != nil {
+		return flowError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    flow,
+		Message: "Success",
+	})
+}
+
+// Update func for update a flow.
+// @Summary update flow
+// @Description Replace a flow, as JSON or YAML, active is kept when omitted. The conversations in a removed state leave the flow.
+// @Tags Flow
+// @Accept json,application/x-yaml
+// @Produce json
+// @Param id path string true "Flow ID"
+// @Param flow body domain.FlowForm true "Flow"
+// @Success 200 {object} domain.JSONResult{data=domain.Flow,message=string} "Description"
+// @Failure 422 {object} []domain.HTTPErrorValidation
+// @Failure 400 {object} domain.HTTPError
+// @Failure 404 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/flows/{id} [put]
+func (h *FlowHandler) Update(c *fiber.Ctx) error {
+	var form domain.FlowForm
+	err := parseFlowForm(c, &form)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	err = h.Validate.Struct(&form)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ValidatorErrors(err))
+	}
+
+	flow, err := h.FlowUsecase.Update(c.Params("id"), form)
+	if err != nil {
+		return flowError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    flow,
+		Message: "Success",
+	})
+}
+
+// Delete func for delete a flow.
+// @Summary delete flow
+// @Description Delete a flow and end its conversations.
+// @Tags Flow
+// @Produce json
+// @Param id path string true "Flow ID"
+// @Success 200 {object} domain.JSONResult{data=object,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/flows/{id} [delete]
+func (h *FlowHandler) Delete(c *fiber.Ctx) error {
+	err := h.FlowUsecase.Delete(c.Params("id"))
+	if err != nil {
+		return flowError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Message: "Success",
+	})
+}
+
+// Conversations func for list the conversations of a flow.
+// @Summary list flow conversations
+// @Description List the contacts in a flow, their state and saved variables.
+// @Tags Flow
+// @Produce json
+// @Param id path string true "Flow ID"
+// @Success 200 {object} domain.JSONResult{data=[]domain.FlowConversation,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/flows/{id}/conversations [get]
+func (h *FlowHandler) Conversations(c *fiber.Ctx) error {
+	conversations, err := h.FlowUsecase.Conversations(c.Params("id"))
+	if err != nil {
+		return flowError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    conversations,
+		Message: "Success",
+	})
+}
+
+// EndConversation func for end a flow conversation.
+// @Summary end flow conversation
+// @Description Take a contact out of its flow, its next message can trigger a flow again.
+// @Tags Flow
+// @Produce json
+// @Param conversation_id path string true "Conversation ID"
+// @Success 200 {object} domain.JSONResult{data=object,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/flows/conversations/{conversation_id} [delete]
+func (h *FlowHandler) EndConversation(c *fiber.Ctx) error {
+	err := h.FlowUsecase.EndConversation(c.Params("conversation_id"))
+	if err != nil {
+		return flowError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Message: "Success",
+	})
+}
+
+// parseFlowForm decodes the body as YAML when its content type says so, as
+// JSON otherwise.
+func parseFlowForm(c *fiber.Ctx, form *domain.FlowForm) error {
+	if strings.Contains(string(c.Request().Header.ContentType()), "yaml") {
+		return yaml.UnmarshalStrict(c.Body(), form)
+	}
+
+	return c.BodyParser(form)
+}
+
+func flowError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrFlowNotFound), errors.Is(err, domain.ErrFlowConversationNotFound):
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	case errors.Is(err, domain.ErrInvalidFlow):
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+}
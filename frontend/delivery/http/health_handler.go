@@ -0,0 +1,59 @@
+package http
+
+import (
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+type HealthHandler struct {
+	SessionManager domain.WhatsappSessionManager
+}
+
+// NewHealthHandler registers the probes at the root of the app, outside of
+// /api, so orchestrators can reach them without knowing the api version.
+func NewHealthHandler(app *fiber.App, sessionManager domain.WhatsappSessionManager) {
+	handler := &HealthHandler{
+		SessionManager: sessionManager,
+	}
+
+	app.Get("/healthz", handler.Healthz)
+	app.Get("/readyz", handler.Readyz)
+}
+
+// Healthz func for liveness probe, always succeed while the process is up.
+// It is served outside of /api, so it is left out of the swagger docs and
+// documented in the README.
+func (h *HealthHandler) Healthz(c *fiber.Ctx) error {
+	return c.JSON(domain.JSONResult{
+		Data:    "ok",
+		Message: "Success",
+	})
+}
+
+// Readyz func for readiness probe, succeed when the session given by the
+// session_id query, default to the default session, is connected and logged in.
+// A session not created yet is not ready. Like Healthz it is only documented
+// in the README.
+func (h *HealthHandler) Readyz(c *fiber.Ctx) error {
+	sessionID := c.Query("session_id", domain.DefaultSessionID)
+
+	status := domain.WaStatus{
+		SessionID:  sessionID,
+		Connection: domain.WaConnectionStatus{SessionID: sessionID, State: domain.WaStateDisconnected},
+	}
+	if wu, err := h.SessionManager.Get(sessionID); err == nil {
+		status = wu.Status()
+	}
+	if !status.Ready() {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(domain.JSONResult{
+			Code:    fiber.StatusServiceUnavailable,
+			Data:    status,
+			Message: "Not ready",
+		})
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    status,
+		Message: "Success",
+	})
+}
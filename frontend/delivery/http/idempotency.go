@@ -0,0 +1,169 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils/log"
+	"github.com/gofiber/fiber/v2"
+	"hash"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	headerIdempotencyKey      = "Idempotency-Key"
+	headerIdempotentReplayed  = "Idempotent-Replayed"
+	idempotencyKeyMaxLength   = 255
+	idempotencyMessageIDField = "message_id"
+	// sessionRoutePrefix is the path of the endpoints served per session
+	sessionRoutePrefix = "/sessions/:session_id"
+)
+
+// idempotent answers the requests with an Idempotency-Key header already
+// answered with the response kept for the key, instead of calling handler
+// again. A key used for a different request is answered 409 Conflict. Only
+// the successful responses are kept, a failed request can be retried with
+// the same key.
+func idempotent(idempotency domain.IdempotencyUsecase, handler fiber.Handler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(headerIdempotencyKey)
+		if key == "" {
+			return handler(c)
+		}
+		if len(key) > idempotencyKeyMaxLength {
+			return domain.NewHttpError(c, fiber.StatusBadRequest, domain.ErrInvalidIdempotencyKey)
+		}
+
+		requestHash, err := hashRequest(c)
+		if err != nil {
+			return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+		}
+
+		// A retry sent while the request is still being answered waits for
+		// its response
+		sessionID := c.Params("session_id", domain.DefaultSessionID)
+		unlock := idempotency.Lock(sessionID, key)
+		defer unlock()
+
+		record, err := idempotency.Get(sessionID, key)
+		switch {
+		case err == nil && record.RequestHash != requestHash:
+			return domain.NewHttpError(c, fiber.StatusConflict, domain.ErrIdempotencyKeyReused)
+		case err == nil:
+			c.Set(headerIdempotentReplayed, "true")
+			c.Set(fiber.HeaderContentType, record.ContentType)
+			return c.Status(record.Status).Send(record.Body)
+		case !errors.Is(err, domain.ErrIdempotencyKeyNotFound):
+			return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+		}
+
+		err = handler(c)
+		if err != nil {
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		if status < 200 || status > 299 {
+			return nil
+		}
+
+		record = domain.IdempotencyRecord{
+			SessionID:   sessionID,
+			Key:         key,
+			RequestHash: requestHash,
+			Status:      status,
+			ContentType: string(c.Response().Header.ContentType()),
+			Body:        append([]byte(nil), c.Response().Body()...),
+		}
+		record.MessageID = responseMessageID(record.Body)
+
+		// The message is sent already, answer it even when the key is lost
+		err = idempotency.Store(record)
+		if err != nil {
+			log.Println(log.LogLevelError, "idempotency", key+": "+err.Error())
+		}
+
+		return nil
+	}
+}
+
+// hashRequest returns the hash of the endpoint and the form of the request,
+// the files by name and content. The order of the fields, the multipart
+// boundary and the session path of the endpoint do not change it, the key is
+// already per session.
+func hashRequest(c *fiber.Ctx) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", c.Method(), strings.Replace(c.Route().Path, sessionRoutePrefix, "", 1))
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		// Not a multipart request, the url encoded form
+		values := make(map[string][]string)
+		c.Request().PostArgs().VisitAll(func(key, value []byte) {
+			values[string(key)] = append(values[string(key)], string(value))
+		})
+		hashValues(h, values)
+
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	hashValues(h, form.Value)
+
+	names := make([]string, 0, len(form.File))
+	for name := range form.File {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, fileHeader := range form.File[name] {
+			fmt.Fprintf(h, "%q:%q:%d\n", name, fileHeader.Filename, fileHeader.Size)
+
+			file, err := fileHeader.Open()
+			if err != nil {
+				return "", err
+			}
+			_, err = io.Copy(h, file)
+			file.Close()
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashValues(h hash.Hash, values map[string][]string) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, value := range values[key] {
+			fmt.Fprintf(h, "%q=%q\n", key, value)
+		}
+	}
+}
+
+// responseMessageID returns the message_id of a send response, empty for the
+// queued and scheduled messages.
+func responseMessageID(body []byte) string {
+	var response struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if json.Unmarshal(body, &response) != nil {
+		return ""
+	}
+
+	id, _ := response.Data[idempotencyMessageIDField].(string)
+
+	return id
+}
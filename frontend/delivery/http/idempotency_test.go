@@ -0,0 +1,278 @@
+package http
+
+import (
+	"bytes"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/gofiber/fiber/v2"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// formPart is a field, or a file when fileName is set, of a test request
+type formPart struct {
+	name, value, fileName string
+}
+
+func newMultipartRequest(t *testing.T, path, boundary string, parts ...formPart) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.SetBoundary(boundary); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, part := range parts {
+		if part.fileName == "" {
+			if err := writer.WriteField(part.name, part.value); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+
+		w, err := writer.CreateFormFile(part.name, part.fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = w.Write([]byte(part.value)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(fiber.MethodPost, path, &body)
+	req.Header.Set(fiber.HeaderContentType, writer.FormDataContentType())
+
+	return req
+}
+
+func newURLEncodedRequest(path, body string) *http.Request {
+	req := httptest.NewRequest(fiber.MethodPost, path, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationForm)
+
+	return req
+}
+
+// requestHash returns the hash of req, answered by an app serving the send
+// endpoints with and without a session.
+func requestHash(t *testing.T, req *http.Request) string {
+	t.Helper()
+
+	app := fiber.New()
+	hash := func(c *fiber.Ctx) error {
+		h, err := hashRequest(c)
+		if err != nil {
+			return err
+		}
+		return c.SendString(h)
+	}
+	for _, r := range []fiber.Router{app, app.Group("/sessions/:session_id")} {
+		r.Post("/send-text", hash)
+		r.Post("/send-image", hash)
+	}
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status %d: %s", resp.StatusCode, b)
+	}
+
+	return string(b)
+}
+
+func TestHashRequest(t *testing.T) {
+	text := []formPart{{name: "msisdn", value: "6281234567890"}, {name: "text", value: "hello"}}
+	image := []formPart{{name: "msisdn", value: "6281234567890"}, {name: "image_file", value: "\x89PNG", fileName: "a.png"}}
+
+	tests := []struct {
+		name string
+		a, b *http.Request
+		same bool
+	}{
+		{
+			name: "fields in another order",
+			a:    newMultipartRequest(t, "/send-text", "boundary-a", text[0], text[1]),
+			b:    newMultipartRequest(t, "/send-text", "boundary-a", text[1], text[0]),
+			same: true,
+		},
+		{
+			name: "another multipart boundary",
+			a:    newMultipartRequest(t, "/send-text", "boundary-a", text...),
+			b:    newMultipartRequest(t, "/send-text", "boundary-b", text...),
+			same: true,
+		},
+		{
+			name: "url encoded fields in another order",
+			a:    newURLEncodedRequest("/send-text", "msisdn=6281234567890&text=hello"),
+			b:    newURLEncodedRequest("/send-text", "text=hello&msisdn=6281234567890"),
+			same: true,
+		},
+		{
+			name: "with and without the session path",
+			a:    newMultipartRequest(t, "/send-text", "boundary-a", text...),
+			b:    newMultipartRequest(t, "/sessions/default/send-text", "boundary-a", text...),
+			same: true,
+		},
+		{
+			name: "another value",
+			a:    newMultipartRequest(t, "/send-text", "boundary-a", text...),
+			b:    newMultipartRequest(t, "/send-text", "boundary-a", text[0], formPart{name: "text", value: "hello!"}),
+		},
+		{
+			name: "a value moved to another field",
+			a:    newURLEncodedRequest("/send-text", "msisdn=1&text=2"),
+			b:    newURLEncodedRequest("/send-text", "msisdn=2&text=1"),
+		},
+		{
+			name: "another endpoint",
+			a:    newMultipartRequest(t, "/send-text", "boundary-a", text...),
+			b:    newMultipartRequest(t, "/send-image", "boundary-a", text...),
+		},
+		{
+			name: "another file content",
+			a:    newMultipartRequest(t, "/send-image", "boundary-a", image...),
+			b:    newMultipartRequest(t, "/send-image", "boundary-a", image[0], formPart{name: "image_file", value: "\x89PNG!", fileName: "a.png"}),
+		},
+		{
+			name: "another file name",
+			a:    newMultipartRequest(t, "/send-image", "boundary-a", image...),
+			b:    newMultipartRequest(t, "/send-image", "boundary-a", image[0], formPart{name: "image_file", value: "\x89PNG", fileName: "b.png"}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := requestHash(t, tt.a), requestHash(t, tt.b)
+			if (a == b) != tt.same {
+				t.Errorf("hashes %s and %s, want same %v", a, b, tt.same)
+			}
+		})
+	}
+}
+
+// memoryIdempotency keeps the idempotency records in memory
+type memoryIdempotency struct {
+	mu      sync.Mutex
+	records map[string]domain.IdempotencyRecord
+}
+
+func (m *memoryIdempotency) Lock(sessionID, key string) (unlock func()) {
+	return func() {}
+}
+
+func (m *memoryIdempotency) Get(sessionID, key string) (domain.IdempotencyRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, ok := m.records[sessionID+"|"+key]
+	if !ok {
+		return record, domain.ErrIdempotencyKeyNotFound
+	}
+
+	return record, nil
+}
+
+func (m *memoryIdempotency) Store(record domain.IdempotencyRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.records[record.SessionID+"|"+record.Key] = record
+
+	return nil
+}
+
+func TestIdempotent(t *testing.T) {
+	idempotency := &memoryIdempotency{records: make(map[string]domain.IdempotencyRecord)}
+	sent := 0
+
+	app := fiber.New()
+	send := idempotent(idempotency, func(c *fiber.Ctx) error {
+		sent++
+		if c.FormValue("text") == "fail" {
+			return domain.NewHttpError(c, fiber.StatusBadRequest, domain.ErrInvalidSendType)
+		}
+		return c.JSON(domain.JSONResult{Data: map[string]string{"message_id": "ABC"}, Message: "Success"})
+	})
+	app.Post("/send-text", send)
+	app.Post("/sessions/:session_id/send-text", send)
+
+	tests := []struct {
+		name       string
+		path       string
+		key        string
+		body       string
+		wantStatus int
+		wantSent   int
+		replayed   bool
+	}{
+		{name: "first", path: "/send-text", key: "k1", body: "text=hello", wantStatus: 200, wantSent: 1},
+		{name: "retry", path: "/send-text", key: "k1", body: "text=hello", wantStatus: 200, wantSent: 1, replayed: true},
+		{name: "retry with the session path", path: "/sessions/default/send-text", key: "k1", body: "text=hello", wantStatus: 200, wantSent: 1, replayed: true},
+		{name: "key of another session", path: "/sessions/sales/send-text", key: "k1", body: "text=hello", wantStatus: 200, wantSent: 2},
+		{name: "key reused", path: "/send-text", key: "k1", body: "text=other", wantStatus: 409, wantSent: 2},
+		{name: "without key", path: "/send-text", body: "text=hello", wantStatus: 200, wantSent: 3},
+		{name: "key too long", path: "/send-text", key: strings.Repeat("k", idempotencyKeyMaxLength+1), body: "text=hello", wantStatus: 400, wantSent: 3},
+		{name: "failed", path: "/send-text", key: "k2", body: "text=fail", wantStatus: 400, wantSent: 4},
+		{name: "failed retried", path: "/send-text", key: "k2", body: "text=fail", wantStatus: 400, wantSent: 5},
+	}
+
+	for _, tt := range tests {
+		req := newURLEncodedRequest(tt.path, tt.body)
+		if tt.key != "" {
+			req.Header.Set(headerIdempotencyKey, tt.key)
+		}
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%s: status %d, want %d", tt.name, resp.StatusCode, tt.wantStatus)
+		}
+		if sent != tt.wantSent {
+			t.Errorf("%s: sent %d times, want %d", tt.name, sent, tt.wantSent)
+		}
+		if replayed := resp.Header.Get(headerIdempotentReplayed) == "true"; replayed != tt.replayed {
+			t.Errorf("%s: replayed %v, want %v", tt.name, replayed, tt.replayed)
+		}
+	}
+
+	record, err := idempotency.Get(domain.DefaultSessionID, "k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.MessageID != "ABC" {
+		t.Errorf("stored message id %q, want %q", record.MessageID, "ABC")
+	}
+}
+
+func TestResponseMessageID(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{body: `{"data":{"message_id":"ABC"},"message":"Success"}`, want: "ABC"},
+		{body: `{"data":{"id":"job"},"message":"Success"}`},
+		{body: `{"data":"ok"}`},
+		{body: `not json`},
+	}
+
+	for _, tt := range tests {
+		if got := responseMessageID([]byte(tt.body)); got != tt.want {
+			t.Errorf("responseMessageID(%s) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package http
+
+import (
+	"errors"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/gofiber/fiber/v2"
+	"io/ioutil"
+	"mime"
+	"strconv"
+)
+
+type MediaHandler struct {
+	MediaUsecase domain.WaMediaUsecase
+}
+
+func NewMediaHandler(mediaUsecase domain.WaMediaUsecase, rPublic, rPrivate fiber.Router) {
+	handler := &MediaHandler{
+		MediaUsecase: mediaUsecase,
+	}
+
+	rPrivate.Post("/media", handler.Upload)
+	rPublic.Get("/media/:id", handler.Get)
+}
+
+// Upload func for upload a media.
+// @Summary upload media
+// @Description Store a file in the media library, e.g. to be sent by the auto-reply rules with its id.
+// @Description The size limit is MEDIA_MAX_SIZE_MB, or MEDIA_MAX_SIZE_MB_UPLOAD when set.
+// @Tags Message
+// @Accept mpfd
+// @Produce json
+// @Param file formData file true "File"
+// @Success 201 {object} domain.JSONResult{data=domain.WaMedia,message=string} "Description"
+// @Failure 400 {object} domain.HTTPError
+// @Failure 413 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Security ApiKeyAuth
+// @Router /v1/auth/media [post]
+func (h *MediaHandler) Upload(c *fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	media, err := h.MediaUsecase.Save("upload", fileHeader.Header.Get(fiber.HeaderContentType), fileHeader.Filename,
+		uint64(fileHeader.Size), func() ([]byte, error) {
+			f, err := fileHeader.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+
+			return ioutil.ReadAll(f)
+		})
+	if errors.Is(err, domain.ErrMediaTooLarge) {
+		return domain.NewHttpError(c, fiber.StatusRequestEntityTooLarge, err)
+	}
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(domain.JSONResult{
+		Data:    media,
+		Message: "Success",
+	})
+}
+
+// inlineMediaTypes are the media types served inline, raster images, audios
+// and videos. Any other type, like text/html or image/svg+xml, could run a
+// script on the API origin.
+var inlineMediaTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+	"audio/ogg":  true,
+	"audio/mpeg": true,
+	"audio/mp4":  true,
+	"audio/aac":  true,
+	"audio/amr":  true,
+	"video/mp4":  true,
+	"video/3gpp": true,
+}
+
+// Get func for download a received media.
+// @Summary download media
+// @Description Download the media of a received message, the media id is the media_id of the message.
+// @Description Images, audios and videos are served inline, add download=true to get them as an attachment.
+// @Description The other media are always served as an attachment.
+// @Tags Message
+// @Produce octet-stream
+// @Param id path string true "Media ID"
+// @Param download query bool false "Serve as an attachment"
+// @Success 200 {file} file "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/media/{id} [get]
+func (h *MediaHandler) Get(c *fiber.Ctx) error {
+	media, content, err := h.MediaUsecase.Get(c.Params("id"))
+	if err == domain.ErrMediaNotFound {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+	}
+
+	// The type is set by the sender, only the media a browser can not run
+	// are served inline
+	disposition := "attachment"
+	mediaType, _, _ := mime.ParseMediaType(media.MimeType)
+	if inlineMediaTypes[mediaType] && c.Query("download") != "true" {
+		disposition = "inline"
+	}
+
+	c.Set(fiber.HeaderContentType, media.MimeType)
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	c.Set(fiber.HeaderContentSecurityPolicy, "sandbox")
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType(disposition, map[string]string{"filename": media.FileName}))
+	// The content never changes, its id is its hash
+	c.Set(fiber.HeaderCacheControl, "private, max-age=31536000, immutable")
+	c.Set(fiber.HeaderETag, strconv.Quote(media.ID))
+
+	return c.SendStream(content, int(media.Size))
+}
@@ -0,0 +1,127 @@
+package http
+
+import (
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+type MessageHandler struct {
+	MessageUsecase domain.WaMessageUsecase
+}
+
+func NewMessageHandler(messageUsecase domain.WaMessageUsecase, rPublic, rPrivate fiber.Router) {
+	handler := &MessageHandler{
+		MessageUsecase: messageUsecase,
+	}
+
+	// Like the whatsapp endpoints, messages are served for the default session
+	// and, under /sessions/:session_id, for any named session.
+	rWa := rPublic.Group("/whatsapp")
+	for _, r := range []fiber.Router{rWa, rWa.Group("/sessions/:session_id")} {
+		r.Get("/messages", handler.Fetch)
+		r.Get("/messages/:id", handler.Get)
+		r.Get("/messages/:id/status", handler.Status)
+	}
+}
+
+// Fetch func for list the stored messages.
+// @Summary list messages
+// @Description List the messages sent and received by the session, newest first.
+// @Tags Message
+// @Produce json
+// @Param jid query string false "Chat JID, eg: 6281234567890@s.whatsapp.net"
+// @Param direction query string false "Direction" Enums(inbound, outbound)
+// @Param type query string false "Message type" Enums(text, image, document, audio, video, location, contact)
+// @Param from query string false "Oldest message time, RFC3339, eg: 2021-07-01T00:00:00+07:00"
+// @Param to query string false "Newest message time, RFC3339"
+// @Param q query string false "Search in the text, caption, file name and contact name"
+// @Param page query int false "Page, default 1"
+// @Param per_page query int false "Messages per page, default 20, max 100"
+// @Success 200 {object} domain.JSONResult{data=[]domain.WaMessage,meta=domain.JSONResultMeta,message=string} "Description"
+// @Failure 400 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/whatsapp/messages [get]
+func (h *MessageHandler) Fetch(c *fiber.Ctx) error {
+	filter := domain.WaMessageFilter{
+		SessionID: c.Params("session_id", domain.DefaultSessionID),
+		Jid:       c.Query("jid"),
+		Direction: domain.WaMessageDirection(c.Query("direction")),
+		Type:      c.Query("type"),
+		Query:     c.Query("q"),
+		Page:      queryInt(c, "page", 1),
+		PerPage:   queryInt(c, "per_page", 20),
+	}
+
+	var err error
+	filter.From, err = queryTime(c, "from")
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	filter.To, err = queryTime(c, "to")
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	messages, meta, err := h.MessageUsecase.Fetch(filter)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    messages,
+		Meta:    meta,
+		Message: "Success",
+	})
+}
+
+// Get func for get a stored message.
+// @Summary get message
+// @Description Get a message sent or received by the session.
+// @Tags Message
+// @Produce json
+// @Param id path string true "Message ID"
+// @Success 200 {object} domain.JSONResult{data=domain.WaMessage,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/whatsapp/messages/{id} [get]
+func (h *MessageHandler) Get(c *fiber.Ctx) error {
+	message, err := h.MessageUsecase.GetByID(c.Params("session_id", domain.DefaultSessionID), c.Params("id"))
+	if err == domain.ErrMessageNotFound {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    message,
+		Message: "Success",
+	})
+}
+
+// Status func for get the delivery status of a sent message.
+// @Summary get message status
+// @Description Get the delivery status of a message sent by the session: pending, server-ack, delivered, read or played,
+// @Description with the time each status was reached. Every change is published as a message.status event.
+// @Tags Message
+// @Produce json
+// @Param id path string true "Message ID"
+// @Success 200 {object} domain.JSONResult{data=domain.WaMessageStatus,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/whatsapp/messages/{id}/status [get]
+func (h *MessageHandler) Status(c *fiber.Ctx) error {
+	status, err := h.MessageUsecase.Status(c.Params("session_id", domain.DefaultSessionID), c.Params("id"))
+	if err == domain.ErrMessageStatusNotFound {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    status,
+		Message: "Success",
+	})
+}
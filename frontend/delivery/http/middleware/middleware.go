@@ -19,7 +19,7 @@ func (m *GoMiddleware) CORS() fiber.Handler {
 	return cors.New(cors.Config{
 		AllowOrigins: "*",
 		//AllowOrigins: "https://gofiber.io, https://gofiber.net",
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
+		AllowHeaders: "Origin, Content-Type, Accept, Authorization, Idempotency-Key",
 		AllowMethods: "GET, HEAD, PUT, PATCH, POST, DELETE",
 	})
 }
@@ -36,6 +36,8 @@ func (m *GoMiddleware) JWT() fiber.Handler {
 		SigningKey:   []byte(os.Getenv("JWT_SECRET_KEY")),
 		ContextKey:   "jwt", // used in private routes
 		ErrorHandler: jwtError,
+		// Browsers can not set headers on EventSource and WebSocket requests
+		TokenLookup: "header:Authorization,query:access_token",
 	}
 
 	return jwtMiddleware.New(config)
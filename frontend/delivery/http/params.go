@@ -0,0 +1,48 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+	"time"
+)
+
+// queryInt returns the query parameter as int, or def when it is missing or
+// not a number.
+func queryInt(c *fiber.Ctx, key string, def int) int {
+	v, err := strconv.Atoi(c.Query(key))
+	if err != nil {
+		return def
+	}
+
+	return v
+}
+
+// queryTime parses the RFC3339 query parameter, nil when it is missing.
+func queryTime(c *fiber.Ctx, key string) (*time.Time, error) {
+	v := c.Query(key)
+	if v == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// formValues returns every value of the form field, of a multipart or an url
+// encoded form.
+func formValues(c *fiber.Ctx, key string) []string {
+	if form, err := c.MultipartForm(); err == nil {
+		return form.Value[key]
+	}
+
+	var values []string
+	for _, v := range c.Request().PostArgs().PeekMulti(key) {
+		values = append(values, string(v))
+	}
+
+	return values
+}
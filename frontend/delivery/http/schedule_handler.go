@@ -0,0 +1,116 @@
+package http
+
+import (
+	"errors"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ScheduleHandler struct {
+	Scheduler domain.WaScheduleUsecase
+}
+
+func NewScheduleHandler(scheduler domain.WaScheduleUsecase, rPublic, rPrivate fiber.Router) {
+	handler := &ScheduleHandler{
+		Scheduler: scheduler,
+	}
+
+	// Like the whatsapp endpoints, schedules are served for the default
+	// session and, under /sessions/:session_id, for any named session.
+	rWa := rPublic.Group("/whatsapp")
+	for _, r := range []fiber.Router{rWa, rWa.Group("/sessions/:session_id")} {
+		r.Get("/schedules", handler.Fetch)
+		r.Get("/schedules/:id", handler.Get)
+		r.Post("/schedules/:id/cancel", handler.Cancel)
+	}
+}
+
+// Fetch func for list the scheduled messages.
+// @Summary list schedules
+// @Description List the messages scheduled with send_at or cron by the session, newest first.
+// @Tags Schedule
+// @Produce json
+// @Param status query string false "Schedule status" Enums(active, completed, cancelled)
+// @Param page query int false "Page, default 1"
+// @Param per_page query int false "Schedules per page, default 20, max 100"
+// @Success 200 {object} domain.JSONResult{data=[]domain.WaSchedule,meta=domain.JSONResultMeta,message=string} "Description"
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/whatsapp/schedules [get]
+func (h *ScheduleHandler) Fetch(c *fiber.Ctx) error {
+	filter := domain.WaScheduleFilter{
+		SessionID: c.Params("session_id", domain.DefaultSessionID),
+		Status:    domain.WaScheduleStatus(c.Query("status")),
+		Page:      queryInt(c, "page", 1),
+		PerPage:   queryInt(c, "per_page", 20),
+	}
+
+	schedules, meta, err := h.Scheduler.Fetch(filter)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    schedules,
+		Meta:    meta,
+		Message: "Success",
+	})
+}
+
+// Get func for get a scheduled message.
+// @Summary get schedule
+// @Description Get a scheduled message with its next run. Each run queues the message, last_job_id is the send job of the last run.
+// @Tags Schedule
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Success 200 {object} domain.JSONResult{data=domain.WaSchedule,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/whatsapp/schedules/{id} [get]
+func (h *ScheduleHandler) Get(c *fiber.Ctx) error {
+	schedule, err := h.Scheduler.Get(c.Params("session_id", domain.DefaultSessionID), c.Params("id"))
+	if err != nil {
+		return scheduleError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    schedule,
+		Message: "Success",
+	})
+}
+
+// Cancel func for cancel a scheduled message.
+// @Summary cancel schedule
+// @Description Cancel an active schedule, the messages already queued by its runs are not cancelled.
+// @Tags Schedule
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Success 200 {object} domain.JSONResult{data=domain.WaSchedule,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 409 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/whatsapp/schedules/{id}/cancel [post]
+func (h *ScheduleHandler) Cancel(c *fiber.Ctx) error {
+	schedule, err := h.Scheduler.Cancel(c.Params("session_id", domain.DefaultSessionID), c.Params("id"))
+	if err != nil {
+		return scheduleError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    schedule,
+		Message: "Success",
+	})
+}
+
+func scheduleError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrScheduleNotFound):
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	case errors.Is(err, domain.ErrScheduleNotActive):
+		return domain.NewHttpError(c, fiber.StatusConflict, err)
+	case errors.Is(err, domain.ErrInvalidSchedule), errors.Is(err, domain.ErrInvalidSendType),
+		errors.Is(err, domain.ErrMediaTooLarge):
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+}
@@ -0,0 +1,139 @@
+package http
+
+import (
+	"errors"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+type SendQueueHandler struct {
+	SendQueue domain.WaSendQueueUsecase
+}
+
+func NewSendQueueHandler(sendQueue domain.WaSendQueueUsecase, rPublic, rPrivate fiber.Router) {
+	handler := &SendQueueHandler{
+		SendQueue: sendQueue,
+	}
+
+	// Like the whatsapp endpoints, jobs are served for the default session
+	// and, under /sessions/:session_id, for any named session.
+	rWa := rPublic.Group("/whatsapp")
+	for _, r := range []fiber.Router{rWa, rWa.Group("/sessions/:session_id")} {
+		r.Get("/jobs", handler.Fetch)
+		r.Get("/jobs/:id", handler.Get)
+		r.Post("/jobs/:id/cancel", handler.Cancel)
+		r.Post("/jobs/:id/retry", handler.Retry)
+	}
+}
+
+// Fetch func for list the send jobs.
+// @Summary list send jobs
+// @Description List the messages queued with async=true by the session, newest first.
+// @Tags Send Queue
+// @Produce json
+// @Param status query string false "Job status" Enums(queued, sending, sent, failed, cancelled)
+// @Param page query int false "Page, default 1"
+// @Param per_page query int false "Jobs per page, default 20, max 100"
+// @Success 200 {object} domain.JSONResult{data=[]domain.WaSendJob,meta=domain.JSONResultMeta,message=string} "Description"
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/whatsapp/jobs [get]
+func (h *SendQueueHandler) Fetch(c *fiber.Ctx) error {
+	filter := domain.WaSendJobFilter{
+		SessionID: c.Params("session_id", domain.DefaultSessionID),
+		Status:    domain.WaSendJobStatus(c.Query("status")),
+		Page:      queryInt(c, "page", 1),
+		PerPage:   queryInt(c, "per_page", 20),
+	}
+
+	jobs, meta, err := h.SendQueue.Fetch(filter)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    jobs,
+		Meta:    meta,
+		Message: "Success",
+	})
+}
+
+// Get func for get a send job.
+// @Summary get send job
+// @Description Get a queued message with the error of each send attempt. A sent job holds the message_id of the message.
+// @Tags Send Queue
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} domain.JSONResult{data=domain.WaSendJob,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/whatsapp/jobs/{id} [get]
+func (h *SendQueueHandler) Get(c *fiber.Ctx) error {
+	job, err := h.SendQueue.Get(c.Params("session_id", domain.DefaultSessionID), c.Params("id"))
+	if err != nil {
+		return sendQueueError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    job,
+		Message: "Success",
+	})
+}
+
+// Cancel func for cancel a send job.
+// @Summary cancel send job
+// @Description Cancel a queued message, a message being sent or already sent can not be cancelled.
+// @Tags Send Queue
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} domain.JSONResult{data=domain.WaSendJob,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 409 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/whatsapp/jobs/{id}/cancel [post]
+func (h *SendQueueHandler) Cancel(c *fiber.Ctx) error {
+	job, err := h.SendQueue.Cancel(c.Params("session_id", domain.DefaultSessionID), c.Params("id"))
+	if err != nil {
+		return sendQueueError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    job,
+		Message: "Success",
+	})
+}
+
+// Retry func for retry a send job.
+// @Summary retry send job
+// @Description Queue a failed or cancelled message again, with a new set of attempts.
+// @Tags Send Queue
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} domain.JSONResult{data=domain.WaSendJob,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 409 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/whatsapp/jobs/{id}/retry [post]
+func (h *SendQueueHandler) Retry(c *fiber.Ctx) error {
+	job, err := h.SendQueue.Retry(c.Params("session_id", domain.DefaultSessionID), c.Params("id"))
+	if err != nil {
+		return sendQueueError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    job,
+		Message: "Success",
+	})
+}
+
+func sendQueueError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrSendJobNotFound):
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	case errors.Is(err, domain.ErrSendJobBusy), errors.Is(err, domain.ErrSendJobNotFailed):
+		return domain.NewHttpError(c, fiber.StatusConflict, err)
+	case errors.Is(err, domain.ErrInvalidSendType):
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+}
@@ -0,0 +1,46 @@
+package http
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"time"
+)
+
+// sseKeepAlive is how often a comment is written to idle event streams, it
+// keeps proxies from closing them and detects gone clients.
+const sseKeepAlive = 15 * time.Second
+
+// setSSEHeaders prepares c for a Server-Sent Events response.
+func setSSEHeaders(c *fiber.Ctx) {
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+}
+
+// writeSSE writes one event and flushes it to the client.
+func writeSSE(bw *bufio.Writer, id, event string, data interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if id != "" {
+		fmt.Fprintf(bw, "id: %s\n", id)
+	}
+	if event != "" {
+		fmt.Fprintf(bw, "event: %s\n", event)
+	}
+	fmt.Fprintf(bw, "data: %s\n\n", b)
+
+	return bw.Flush()
+}
+
+// writeSSEKeepAlive writes a comment line, ignored by the clients.
+func writeSSEKeepAlive(bw *bufio.Writer) error {
+	fmt.Fprint(bw, ": keep-alive\n\n")
+
+	return bw.Flush()
+}
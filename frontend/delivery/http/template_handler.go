@@ -0,0 +1,209 @@
+package http
+
+import (
+	"errors"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type TemplateHandler struct {
+	TemplateUsecase domain.TemplateUsecase
+	Validate        *validator.Validate
+}
+
+func NewTemplateHandler(templateUsecase domain.TemplateUsecase, rPublic, rPrivate fiber.Router) {
+	handler := &TemplateHandler{
+		TemplateUsecase: templateUsecase,
+		Validate:        utils.NewValidator(),
+	}
+
+	rTemplate := rPublic.Group("/templates")
+	rTemplate.Get("/", handler.Fetch)
+	rTemplate.Post("/", handler.Create)
+	rTemplate.Get("/:id", handler.Get)
+	rTemplate.Put("/:id", handler.Update)
+	rTemplate.Delete("/:id", handler.Delete)
+	rTemplate.Post("/:id/render", handler.Render)
+}
+
+// Fetch func for list message templates.
+// @Summary list templates
+// @Description List the message templates by name.
+// @Tags Template
+// @Produce json
+// @Success 200 {object} domain.JSONResult{data=[]domain.Template,message=string} "Description"
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/templates [get]
+func (h *TemplateHandler) Fetch(c *fiber.Ctx) error {
+	templates, err := h.TemplateUsecase.Fetch()
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    templates,
+		Message: "Success",
+	})
+}
+
+// Create func for create a message template.
+// @Summary create template
+// @Description Store a message text with {{variable}} placeholders. defaults are the values of the variables not given
+// @Description when it is rendered, variants the text in other languages by language code, eg: {"id": "Halo {{name}}"}.
+// @Description A template is sent with the template_id and variables of the send text and send file endpoints.
+// @Tags Template
+// @Accept json
+// @Produce json
+// @Param template body domain.TemplateForm true "Template"
+// @Success 201 {object} domain.JSONResult{data=domain.Template,message=string} "Description"
+// @Failure 422 {object} []domain.HTTPErrorValidation
+// @Failure 400 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/templates [post]
+func (h *TemplateHandler) Create(c *fiber.Ctx) error {
+	var form domain.TemplateForm
+	err := c.BodyParser(&form)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	err = h.Validate.Struct(&form)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ValidatorErrors(err))
+	}
+
+	template, err := h.TemplateUsecase.Create(form)
+	if err != nil {
+		return templateError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(domain.JSONResult{
+		Data:    template,
+		Message: "Success",
+	})
+}
+
+// Get func for get a message template.
+// @Summary get template
+// @Description Get a message template with its placeholders.
+// @Tags Template
+// @Produce json
+// @Param id path string true "Template ID"
+// @Success 200 {object} domain.JSONResult{data=domain.Template,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/templates/{id} [get]
+func (h *TemplateHandler) Get(c *fiber.Ctx) error {
+	template, err := h.TemplateUsecase.Get(c.Params("id"))
+	if err != nil {
+		return templateError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    template,
+		Message: "Success",
+	})
+}
+
+// Update func for update a message template.
+// @Summary update template
+// @Description Replace a message template.
+// @Tags Template
+// @Accept json
+// @Produce json
+// @Param id path string true "Template ID"
+// @Param template body domain.TemplateForm true "Template"
+// @Success 200 {object} domain.JSONResult{data=domain.Template,message=string} "Description"
+// @Failure 422 {object} []domain.HTTPErrorValidation
+// @Failure 400 {object} domain.HTTPError
+// @Failure 404 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/templates/{id} [put]
+func (h *TemplateHandler) Update(c *fiber.Ctx) error {
+	var form domain.TemplateForm
+	err := c.BodyParser(&form)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	err = h.Validate.Struct(&form)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ValidatorErrors(err))
+	}
+
+	template, err := h.TemplateUsecase.Update(c.Params("id"), form)
+	if err != nil {
+		return templateError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    template,
+		Message: "Success",
+	})
+}
+
+// Delete func for delete a message template.
+// @Summary delete template
+// @Description Delete a message template.
+// @Tags Template
+// @Produce json
+// @Param id path string true "Template ID"
+// @Success 200 {object} domain.JSONResult{data=object,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/templates/{id} [delete]
+func (h *TemplateHandler) Delete(c *fiber.Ctx) error {
+	err := h.TemplateUsecase.Delete(c.Params("id"))
+	if err != nil {
+		return templateError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Message: "Success",
+	})
+}
+
+// Render func for preview a message template.
+// @Summary render template
+// @Description Render a template with the variables, in the language variant when there is one, without sending it.
+// @Description A placeholder with neither a variable nor a default fails.
+// @Tags Template
+// @Accept json
+// @Produce json
+// @Param id path string true "Template ID"
+// @Param render body domain.TemplateRenderForm true "Variables"
+// @Success 200 {object} domain.JSONResult{data=domain.TemplateRender,message=string} "Description"
+// @Failure 400 {object} domain.HTTPError
+// @Failure 404 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/templates/{id}/render [post]
+func (h *TemplateHandler) Render(c *fiber.Ctx) error {
+	var form domain.TemplateRenderForm
+	err := c.BodyParser(&form)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	render, err := h.TemplateUsecase.Render(c.Params("id"), form)
+	if err != nil {
+		return templateError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    render,
+		Message: "Success",
+	})
+}
+
+func templateError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrTemplateNotFound):
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	case errors.Is(err, domain.ErrInvalidTemplate), errors.Is(err, domain.ErrTemplateVariableMissing):
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+}
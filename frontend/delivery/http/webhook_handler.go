@@ -0,0 +1,242 @@
+package http
+
+import (
+	"errors"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type WebhookHandler struct {
+	WebhookUsecase domain.WebhookUsecase
+	Validate       *validator.Validate
+}
+
+func NewWebhookHandler(webhookUsecase domain.WebhookUsecase, rPublic, rPrivate fiber.Router) {
+	handler := &WebhookHandler{
+		WebhookUsecase: webhookUsecase,
+		Validate:       utils.NewValidator(),
+	}
+
+	rWebhook := rPrivate.Group("/webhooks")
+	rWebhook.Get("/", handler.Fetch)
+	rWebhook.Post("/", handler.Create)
+	rWebhook.Get("/deliveries", handler.Deliveries)
+	rWebhook.Post("/deliveries/:delivery_id/redeliver", handler.Redeliver)
+	rWebhook.Get("/:id", handler.Get)
+	rWebhook.Put("/:id", handler.Update)
+	rWebhook.Delete("/:id", handler.Delete)
+	rWebhook.Get("/:id/deliveries", handler.Deliveries)
+}
+
+// Fetch func for list webhooks.
+// @Summary list webhooks
+// @Description List the webhook subscriptions.
+// @Tags Webhook
+// @Produce json
+// @Success 200 {object} domain.JSONResult{data=[]domain.Webhook,message=string} "Description"
+// @Failure 500 {object} domain.HTTPError
+// @Security ApiKeyAuth
+// @Router /v1/auth/webhooks [get]
+func (h *WebhookHandler) Fetch(c *fiber.Ctx) error {
+	webhooks, err := h.WebhookUsecase.Fetch()
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    webhooks,
+		Message: "Success",
+	})
+}
+
+// Create func for create a webhook.
+// @Summary create webhook
+// @Description Subscribe an URL to the events of the sessions. The events are posted as JSON, the body is signed with the secret
+// @Description using HMAC-SHA256 and the hex signature sent in the X-Webhook-Signature header as "sha256=<signature>".
+// @Description A secret is generated when none is given, it is only answered here.
+// @Description Deliveries failing or answered with a non 2xx status are retried with an exponential backoff.
+// @Description Empty event_types, jids or session_id match every event. Event types: message.text, message.image, message.document,
+// @Description message.audio, message.video, message.contact, message.location, battery, json and connection.
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param webhook body domain.WebhookForm true "Webhook"
+// @Success 201 {object} domain.JSONResult{data=domain.WebhookCreated,message=string} "Description"
+// @Failure 422 {object} []domain.HTTPErrorValidation
+// @Failure 400 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Security ApiKeyAuth
+// @Router /v1/auth/webhooks [post]
+func (h *WebhookHandler) Create(c *fiber.Ctx) error {
+	var form domain.WebhookForm
+	err := c.BodyParser(&form)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	err = h.Validate.Struct(&form)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ValidatorErrors(err))
+	}
+
+	webhook, err := h.WebhookUsecase.Create(form)
+	if err != nil {
+		return webhookError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(domain.JSONResult{
+		Data:    domain.WebhookCreated{Webhook: webhook, Secret: webhook.Secret},
+		Message: "Success",
+	})
+}
+
+// Get func for get a webhook.
+// @Summary get webhook
+// @Description Get a webhook subscription.
+// @Tags Webhook
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} domain.JSONResult{data=domain.Webhook,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Security ApiKeyAuth
+// @Router /v1/auth/webhooks/{id} [get]
+func (h *WebhookHandler) Get(c *fiber.Ctx) error {
+	webhook, err := h.WebhookUsecase.Get(c.Params("id"))
+	if err != nil {
+		return webhookError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    webhook,
+		Message: "Success",
+	})
+}
+
+// Update func for update a webhook.
+// @Summary update webhook
+// @Description Replace the url, secret and filters of a webhook, the secret and active are kept when omitted.
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param webhook body domain.WebhookForm true "Webhook"
+// @Success 200 {object} domain.JSONResult{data=domain.Webhook,message=string} "Description"
+// @Failure 422 {object} []domain.HTTPErrorValidation
+// @Failure 400 {object} domain.HTTPError
+// @Failure 404 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Security ApiKeyAuth
+// @Router /v1/auth/webhooks/{id} [put]
+func (h *WebhookHandler) Update(c *fiber.Ctx) error {
+	var form domain.WebhookForm
+	err := c.BodyParser(&form)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	err = h.Validate.Struct(&form)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ValidatorErrors(err))
+	}
+
+	webhook, err := h.WebhookUsecase.Update(c.Params("id"), form)
+	if err != nil {
+		return webhookError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    webhook,
+		Message: "Success",
+	})
+}
+
+// Delete func for delete a webhook.
+// @Summary delete webhook
+// @Description Delete a webhook subscription and its delivery log.
+// @Tags Webhook
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} domain.JSONResult{data=object,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Security ApiKeyAuth
+// @Router /v1/auth/webhooks/{id} [delete]
+func (h *WebhookHandler) Delete(c *fiber.Ctx) error {
+	err := h.WebhookUsecase.Delete(c.Params("id"))
+	if err != nil {
+		return webhookError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Message: "Success",
+	})
+}
+
+// Deliveries func for list webhook deliveries.
+// @Summary list webhook deliveries
+// @Description List the delivery log, newest first. Under /v1/webhooks/{id}/deliveries only the deliveries of that webhook are listed.
+// @Tags Webhook
+// @Produce json
+// @Param status query string false "Delivery status" Enums(pending, success, failed)
+// @Param page query int false "Page, default 1"
+// @Param per_page query int false "Deliveries per page, default 20, max 100"
+// @Success 200 {object} domain.JSONResult{data=[]domain.WebhookDelivery,meta=domain.JSONResultMeta,message=string} "Description"
+// @Failure 500 {object} domain.HTTPError
+// @Security ApiKeyAuth
+// @Router /v1/auth/webhooks/deliveries [get]
+func (h *WebhookHandler) Deliveries(c *fiber.Ctx) error {
+	filter := domain.WebhookDeliveryFilter{
+		WebhookID: c.Params("id"),
+		Status:    domain.WebhookDeliveryStatus(c.Query("status")),
+		Page:      queryInt(c, "page", 1),
+		PerPage:   queryInt(c, "per_page", 20),
+	}
+
+	deliveries, meta, err := h.WebhookUsecase.Deliveries(filter)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    deliveries,
+		Meta:    meta,
+		Message: "Success",
+	})
+}
+
+// Redeliver func for redeliver a webhook delivery.
+// @Summary redeliver webhook delivery
+// @Description Queue a delivery again, e.g. a failed one once the receiver is fixed.
+// @Tags Webhook
+// @Produce json
+// @Param delivery_id path string true "Delivery ID"
+// @Success 200 {object} domain.JSONResult{data=domain.WebhookDelivery,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 500 {object} domain.HTTPError
+// @Security ApiKeyAuth
+// @Router /v1/auth/webhooks/deliveries/{delivery_id}/redeliver [post]
+func (h *WebhookHandler) Redeliver(c *fiber.Ctx) error {
+	delivery, err := h.WebhookUsecase.Redeliver(c.Params("delivery_id"))
+	if err != nil {
+		return webhookError(c, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    delivery,
+		Message: "Success",
+	})
+}
+
+func webhookError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrWebhookNotFound), errors.Is(err, domain.ErrWebhookDeliveryNotFound):
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	case errors.Is(err, domain.ErrInvalidEventType):
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+}
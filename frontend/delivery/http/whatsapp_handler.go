@@ -1,61 +1,139 @@
 package http
 
 import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/cooljar/go-whatsapp-fiber/domain"
 	"github.com/cooljar/go-whatsapp-fiber/utils"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
-	"github.com/skip2/go-qrcode"
+	"github.com/gofiber/websocket/v2"
+	"io/ioutil"
+	"math"
+	"mime/multipart"
 	"os"
 	"strconv"
+	"time"
 )
 
+// vcardFileMaxSize is the largest vcard_file accepted, in bytes
+const vcardFileMaxSize = 1 << 20
+
 type WhatsappHandler struct {
-	WhatsappUsecase domain.WhatsappUsecase
-	Validate *validator.Validate
+	SessionManager domain.WhatsappSessionManager
+	SendQueue      domain.WaSendQueueUsecase
+	RateLimiter    domain.WaRateLimiter
+	Templates      domain.TemplateUsecase
+	Scheduler      domain.WaScheduleUsecase
+	Idempotency    domain.IdempotencyUsecase
+	Validate       *validator.Validate
 }
 
-func NewWhatsappHandler(whatsappUsecase domain.WhatsappUsecase, rPublic, rPrivate fiber.Router) {
+func NewWhatsappHandler(sessionManager domain.WhatsappSessionManager, sendQueue domain.WaSendQueueUsecase, rateLimiter domain.WaRateLimiter, templates domain.TemplateUsecase, scheduler domain.WaScheduleUsecase, idempotency domain.IdempotencyUsecase, rPublic, rPrivate fiber.Router) {
 	handler := &WhatsappHandler{
-		WhatsappUsecase: whatsappUsecase,
-		Validate: utils.NewValidator(),
+		SessionManager: sessionManager,
+		SendQueue:      sendQueue,
+		RateLimiter:    rateLimiter,
+		Templates:      templates,
+		Scheduler:      scheduler,
+		Idempotency:    idempotency,
+		Validate:       utils.NewValidator(),
 	}
 
 	rWa := rPublic.Group("/whatsapp")
-	rWa.Post("/login", handler.Login)
-	rWa.Get("/info", handler.GetInfo)
-	rWa.Post("/send-text", handler.SendText)
-	rWa.Post("/send-location", handler.SendLocation)
-	rWa.Post("/send-document", handler.SendDocument)
-	rWa.Post("/send-image", handler.SendImage)
-	rWa.Post("/send-audio", handler.SendAudio)
-	rWa.Post("/send-video", handler.SendVideo)
-	rWa.Get("/groups/:jid", handler.Groups)
-	rWa.Post("/logout", handler.Logout)
+	rWa.Get("/sessions", handler.Sessions)
+
+	// Every endpoint is served for the default session and, under
+	// /sessions/:session_id, for any named session.
+	handler.routes(rWa)
+	handler.routes(rWa.Group("/sessions/:session_id"))
+}
+
+func (w *WhatsappHandler) routes(rWa fiber.Router) {
+	rWa.Post("/login", w.Login)
+	rWa.Get("/login/:attempt_id", w.LoginAttempt)
+	rWa.Get("/login/:attempt_id/qr", w.LoginQrCode)
+	rWa.Get("/login/:attempt_id/events", w.LoginEvents)
+	rWa.Get("/login/:attempt_id/ws", w.LoginWebsocket)
+	rWa.Get("/info", w.GetInfo)
+	rWa.Get("/connection", w.Connection)
+	rWa.Get("/status", w.Status)
+
+	// A send retried with the same Idempotency-Key header is answered with
+	// the response of the first one
+	rWa.Post("/send-text", idempotent(w.Idempotency, w.SendText))
+	rWa.Post("/send-location", idempotent(w.Idempotency, w.SendLocation))
+	rWa.Post("/send-contact", idempotent(w.Idempotency, w.SendContact))
+	rWa.Post("/send-contacts", idempotent(w.Idempotency, w.SendContacts))
+	rWa.Post("/send-document", idempotent(w.Idempotency, w.SendDocument))
+	rWa.Post("/send-image", idempotent(w.Idempotency, w.SendImage))
+	rWa.Post("/send-audio", idempotent(w.Idempotency, w.SendAudio))
+	rWa.Post("/send-video", idempotent(w.Idempotency, w.SendVideo))
+	rWa.Get("/groups/:jid", w.Groups)
+	rWa.Get("/chats", w.Chats)
+	rWa.Get("/chats/:jid/messages", w.ChatMessages)
+	rWa.Post("/chats/:jid/read", w.MarkRead)
+	rWa.Post("/chats/:jid/state", w.ChatState)
+	rWa.Post("/presence", w.Presence)
+	rWa.Post("/logout", w.Logout)
+}
+
+// session returns the usecase of the session addressed by the request.
+func (w *WhatsappHandler) session(c *fiber.Ctx) (domain.WhatsappUsecase, error) {
+	return w.SessionManager.Get(c.Params("session_id", domain.DefaultSessionID))
+}
+
+// Sessions func for list whatsapp sessions.
+// @Summary list sessions
+// @Description List the registered whatsapp sessions. Every whatsapp endpoint is also available for a named session under /v1/whatsapp/sessions/{session_id}/..., login creates the session when it does not exist yet.
+// @Tags Whatsapp
+// @Produce json
+// @Success 200 {object} domain.JSONResult{data=[]string,message=string} "Description"
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/whatsapp/sessions [get]
+func (w *WhatsappHandler) Sessions(c *fiber.Ctx) error {
+	return c.JSON(domain.JSONResult{
+		Data:    w.SessionManager.List(),
+		Message: "Success",
+	})
 }
 
 // Login func login whatsapp web.
-// @Description Login to whatsapp web by scanning a QR Code.
+// @Description Login to whatsapp web by scanning a QR Code. The response is the first QR code, the login attempt keeps
+// @Description requesting a new QR code when the previous one expires until it is scanned or login_timeout is reached.
+// @Description The attempt id is returned in the X-Login-Attempt-Id header, use it to follow the attempt and receive the new QR codes.
+// @Description The QR code format is chosen with the format field or, without it, from the Accept header
+// @Description (image/png, application/json, image/svg+xml or text/plain for the terminal form). The json and base64
+// @Description formats return a domain.WaLoginQrCode, base64 holds the PNG as a data URI.
 // @Summary login whatsapp web
 // @Tags Whatsapp
 // @Accept mpfd
-// @Produce png
-// @Param reconnect formData int false "Reconnect, default to 50"
+// @Produce png,json,image/svg+xml,plain
+// @Param reconnect formData int false "Maximum reconnect attempts after the connection is lost, default to 50"
 // @Param timeout formData int false "QR Scan timeout in second, default 20"
+// @Param login_timeout formData int false "Seconds the QR code is refreshed before the attempt times out, default 120"
+// @Param format formData string false "QR code format: png, base64, json, svg or terminal" Enums(png, base64, json, svg, terminal)
+// @Param size formData int false "Image size in pixel for png, base64 and svg, default 256"
+// @Param recovery_level formData string false "QR code recovery level, default medium" Enums(low, medium, high, highest)
 // @Param client_name_long formData string false "Long client name, default: Go Whatsapp REST Api Fiber"
 // @Param client_name_short formData string false "Short client name, default: Go Whatsapp"
 // @Param client_version_major formData int false "Whatsapp Client major version, default: 2"
 // @Param client_version_minor formData int false "Whatsapp Client minor version, default: 2126"
 // @Param client_version_build formData int false "Whatsapp Client build version, default: 11"
 // @Success 200 {file} file "Description"
+// @Header 200 {string} X-Login-Attempt-Id "Login attempt ID"
 // @Failure 422 {object} []domain.HTTPErrorValidation
 // @Failure 400 {object} domain.HTTPError
+// @Failure 403 {object} domain.HTTPError "A new session past WHATSAPP_MAX_SESSIONS"
 // @Failure 404 {object} domain.HTTPError
 // @Failure 500 {object} domain.HTTPError
 // @Router /v1/whatsapp/login [post]
 func (w *WhatsappHandler) Login(c *fiber.Ctx) error {
 	reconnect := c.FormValue("reconnect", "50")
 	timeout := c.FormValue("timeout", "20")
+	loginTimeout := c.FormValue("login_timeout", "120")
 	clientNameLong := c.FormValue("client_name_long", "Cooljar Whatsapp REST Api")
 	clientNameShort := c.FormValue("client_name_short", "Cooljar Whatsapp")
 	reqVersionClientMajor := c.FormValue("client_version_major", os.Getenv("WHATSAPP_CLIENT_VERSION_MAJOR"))
@@ -72,6 +150,11 @@ func (w *WhatsappHandler) Login(c *fiber.Ctx) error {
 		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
 	}
 
+	reqLoginTimeout, err := strconv.Atoi(loginTimeout)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
 	reqVersionClientMajorInt, err := strconv.Atoi(reqVersionClientMajor)
 	if err != nil {
 		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
@@ -87,14 +170,237 @@ func (w *WhatsappHandler) Login(c *fiber.Ctx) error {
 		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
 	}
 
-	qrCodeStr, err := w.WhatsappUsecase.Login(reqVersionClientMajorInt, reqVersionClientMinorInt, reqVersionClientBuildInt, reqTimeout, reqReconnect, clientNameShort, clientNameLong)
+	wu, err := w.SessionManager.GetOrCreate(c.Params("session_id", domain.DefaultSessionID))
+	if err == domain.ErrTooManySessions {
+		return domain.NewHttpError(c, fiber.StatusForbidden, err)
+	}
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	attempt, err := wu.Login(reqVersionClientMajorInt, reqVersionClientMinorInt, reqVersionClientBuildInt, reqTimeout, reqReconnect, reqLoginTimeout, clientNameShort, clientNameLong)
 	if err != nil {
 		return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
 	}
 
-	qrCodePng, _ := qrcode.Encode(qrCodeStr, qrcode.Medium, 256)
-	c.Set("content-type", "image/png")
-	return c.Send(qrCodePng)
+	c.Set("X-Login-Attempt-Id", attempt.ID)
+	return sendQrCode(c, attempt)
+}
+
+// LoginQrCode func for get the current QR code of a login attempt.
+// @Summary get login QR code
+// @Description Get the current QR code of a pending login attempt, in the same formats as login.
+// @Tags Whatsapp
+// @Produce png,json,image/svg+xml,plain
+// @Param attempt_id path string true "Login attempt ID"
+// @Param format query string false "QR code format: png, base64, json, svg or terminal" Enums(png, base64, json, svg, terminal)
+// @Param size query int false "Image size in pixel for png, base64 and svg, default 256"
+// @Param recovery_level query string false "QR code recovery level, default medium" Enums(low, medium, high, highest)
+// @Success 200 {file} file "Description"
+// @Failure 400 {object} domain.HTTPError
+// @Failure 404 {object} domain.HTTPError
+// @Failure 409 {object} domain.HTTPError
+// @Router /v1/whatsapp/login/{attempt_id}/qr [get]
+func (w *WhatsappHandler) LoginQrCode(c *fiber.Ctx) error {
+	wu, err := w.session(c)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
+
+	attempt, err := wu.LoginAttempt(c.Params("attempt_id"))
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
+
+	if attempt.QrCode == "" {
+		return domain.NewHttpError(c, fiber.StatusConflict, fmt.Errorf("login attempt is %s, no QR code to show", attempt.Status))
+	}
+
+	return sendQrCode(c, attempt)
+}
+
+// sendQrCode writes the QR code of attempt in the format asked by the request.
+func sendQrCode(c *fiber.Ctx, attempt domain.WaLoginAttempt) error {
+	size, err := strconv.Atoi(c.FormValue("size", "256"))
+	if err != nil || size < 64 || size > 2048 {
+		return domain.NewHttpError(c, fiber.StatusBadRequest, errors.New("size must be between 64 and 2048"))
+	}
+
+	level, err := utils.ParseQrRecoveryLevel(c.FormValue("recovery_level"))
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	format := c.FormValue("format")
+	if format == "" {
+		switch c.Accepts("image/png", "application/json", "image/svg+xml", "text/plain") {
+		case "application/json":
+			format = utils.QrFormatJSON
+		case "image/svg+xml":
+			format = utils.QrFormatSVG
+		case "text/plain":
+			format = utils.QrFormatTerminal
+		default:
+			format = utils.QrFormatPNG
+		}
+	}
+
+	switch format {
+	case utils.QrFormatPNG:
+		qrCodePng, err := utils.QrPNG(attempt.QrCode, level, size)
+		if err != nil {
+			return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+		}
+
+		c.Set("content-type", "image/png")
+		return c.Send(qrCodePng)
+	case utils.QrFormatJSON, utils.QrFormatBase64:
+		qr := domain.WaLoginQrCode{
+			AttemptID: attempt.ID,
+			QrCode:    attempt.QrCode,
+			ExpiresAt: attempt.ExpiresAt,
+		}
+
+		if format == utils.QrFormatBase64 {
+			qr.Image, err = utils.QrBase64PNG(attempt.QrCode, level, size)
+			if err != nil {
+				return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+			}
+		}
+
+		return c.JSON(domain.JSONResult{
+			Data:    qr,
+			Message: "Success",
+		})
+	case utils.QrFormatSVG:
+		qrCodeSvg, err := utils.QrSVG(attempt.QrCode, level, size)
+		if err != nil {
+			return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+		}
+
+		c.Set("content-type", "image/svg+xml")
+		return c.Send(qrCodeSvg)
+	case utils.QrFormatTerminal:
+		qrCodeText, err := utils.QrTerminal(attempt.QrCode, level)
+		if err != nil {
+			return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+		}
+
+		c.Set("content-type", "text/plain; charset=utf-8")
+		return c.SendString(qrCodeText)
+	}
+
+	return domain.NewHttpError(c, fiber.StatusBadRequest, errors.New("invalid format, use png, base64, json, svg or terminal"))
+}
+
+// LoginAttempt func for get a login attempt.
+// @Summary get login attempt
+// @Description Get the status of a login attempt: pending, success, timeout or failed. A pending attempt
+// @Description holds the current QR code, a successful one holds the session JID.
+// @Tags Whatsapp
+// @Produce json
+// @Param attempt_id path string true "Login attempt ID"
+// @Success 200 {object} domain.JSONResult{data=domain.WaLoginAttempt,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Router /v1/whatsapp/login/{attempt_id} [get]
+func (w *WhatsappHandler) LoginAttempt(c *fiber.Ctx) error {
+	wu, err := w.session(c)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
+
+	attempt, err := wu.LoginAttempt(c.Params("attempt_id"))
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    attempt,
+		Message: "Success",
+	})
+}
+
+// LoginEvents func for stream a login attempt.
+// @Summary stream login attempt
+// @Description Stream the login attempt as Server-Sent Events. Every event is a "login" event holding the attempt,
+// @Description a new one is sent each time the QR code is refreshed or the status changes. The stream ends with the attempt.
+// @Tags Whatsapp
+// @Produce text/event-stream
+// @Param attempt_id path string true "Login attempt ID"
+// @Success 200 {object} domain.WaLoginAttempt "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Router /v1/whatsapp/login/{attempt_id}/events [get]
+func (w *WhatsappHandler) LoginEvents(c *fiber.Ctx) error {
+	wu, err := w.session(c)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
+
+	updates, cancel, err := wu.SubscribeLoginAttempt(c.Params("attempt_id"))
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
+
+	setSSEHeaders(c)
+	c.Context().SetBodyStreamWriter(func(bw *bufio.Writer) {
+		defer cancel()
+
+		keepAlive := time.NewTicker(sseKeepAlive)
+		defer keepAlive.Stop()
+
+		for {
+			select {
+			case attempt, ok := <-updates:
+				if !ok {
+					return
+				}
+				if err := writeSSE(bw, "", "login", attempt); err != nil {
+					return
+				}
+			case <-keepAlive.C:
+				if err := writeSSEKeepAlive(bw); err != nil {
+					return
+				}
+			}
+		}
+	})
+
+	return nil
+}
+
+// LoginWebsocket func for stream a login attempt over websocket.
+// @Summary stream login attempt over websocket
+// @Description Same as the login events stream, over a WebSocket. Each text message is the attempt as JSON.
+// @Tags Whatsapp
+// @Param attempt_id path string true "Login attempt ID"
+// @Success 101 {object} domain.WaLoginAttempt "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Failure 426 {object} domain.HTTPError
+// @Router /v1/whatsapp/login/{attempt_id}/ws [get]
+func (w *WhatsappHandler) LoginWebsocket(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return domain.NewHttpError(c, fiber.StatusUpgradeRequired, errors.New("websocket upgrade required"))
+	}
+
+	wu, err := w.session(c)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
+
+	updates, cancel, err := wu.SubscribeLoginAttempt(c.Params("attempt_id"))
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
+
+	return websocket.New(func(conn *websocket.Conn) {
+		defer cancel()
+
+		for attempt := range updates {
+			if err := conn.WriteJSON(attempt); err != nil {
+				return
+			}
+		}
+	})(c)
 }
 
 // GetInfo func for get info metadata.
@@ -109,7 +415,12 @@ func (w *WhatsappHandler) Login(c *fiber.Ctx) error {
 // @Failure 500 {object} domain.HTTPError
 // @Router /v1/whatsapp/info [get]
 func (w *WhatsappHandler) GetInfo(c *fiber.Ctx) error {
-	info, err := w.WhatsappUsecase.GetInfo()
+	wu, err := w.session(c)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
+
+	info, err := wu.GetInfo()
 	if err != nil {
 		return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
 	}
@@ -120,6 +431,47 @@ func (w *WhatsappHandler) GetInfo(c *fiber.Ctx) error {
 	})
 }
 
+// Connection func for get the connection state.
+// @Summary get connection state
+// @Description Get the connection state kept by the session supervisor: connected, reconnecting, logged-out or disconnected.
+// @Tags Info
+// @Produce json
+// @Success 200 {object} domain.JSONResult{data=domain.WaConnectionStatus,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Router /v1/whatsapp/connection [get]
+func (w *WhatsappHandler) Connection(c *fiber.Ctx) error {
+	wu, err := w.session(c)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    wu.ConnectionStatus(),
+		Message: "Success",
+	})
+}
+
+// Status func for get the session and phone status.
+// @Summary get session status
+// @Description Get the connection state, the session JID, the last successful phone ping and the phone battery.
+// @Description The phone is pinged in background every WHATSAPP_PING_INTERVAL seconds.
+// @Tags Info
+// @Produce json
+// @Success 200 {object} domain.JSONResult{data=domain.WaStatus,message=string} "Description"
+// @Failure 404 {object} domain.HTTPError
+// @Router /v1/whatsapp/status [get]
+func (w *WhatsappHandler) Status(c *fiber.Ctx) error {
+	wu, err := w.session(c)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    wu.Status(),
+		Message: "Success",
+	})
+}
+
 // SendText func for send text.
 // @Summary send text message
 // @Description Send text message.
@@ -127,32 +479,69 @@ func (w *WhatsappHandler) GetInfo(c *fiber.Ctx) error {
 // @Accept mpfd
 // @Produce json
 // @Param msisdn formData string true "Destination number. eg: 6281255423 or group_creator-timstamp_created -> 6281271471566-1619679643 for group"
-// @Param text formData string true "Message text"
+// @Param text formData string false "Message text, required without template_id"
+// @Param template_id formData string false "Template ID, sends the rendered template instead of the text"
+// @Param variables formData string false "Template variables, JSON object. eg: {\"name\": \"Budi\"}"
+// @Param language formData string false "Template language variant. eg: id"
 // @Param msg_quoted_id formData string false "Message Quoted ID"
 // @Param msg_quoted formData string false "Message Quoted"
+// @Param typing_seconds formData int false "Seconds the session is shown typing before the text is sent, max 60"
+// @Param Idempotency-Key header string false "Answer a retry with this key with the response of the first request, instead of sending the message again"
+// @Param async formData bool false "Queue the message and return the send job instead of waiting for it to be sent"
+// @Param send_at formData string false "Send the message at this time, RFC3339 or YYYY-MM-DD HH:MM in the timezone, the response is the schedule"
+// @Param cron formData string false "Send the message at every time of this cron expression, eg: 0 9 * * MON, the response is the schedule"
+// @Param timezone formData string false "Timezone of send_at and cron, default UTC. eg: Asia/Jakarta"
+// @Param catch_up formData string false "Runs missed during a downtime: skip them, send once or send all, default to SCHEDULE_CATCH_UP" Enums(skip, once, all)
 // @Success 200 {object} domain.JSONResult{data=object,message=string} "Description"
+// @Success 202 {object} domain.JSONResult{data=domain.WaSendJob,message=string} "Queued when async is true"
 // @Failure 422 {object} []domain.HTTPErrorValidation
 // @Failure 400 {object} domain.HTTPError
 // @Failure 404 {object} domain.HTTPError
+// @Failure 409 {object} domain.HTTPError "Idempotency-Key already used for a different request"
+// @Failure 429 {object} domain.HTTPError "Over the rate limit, retry after the Retry-After header seconds"
 // @Failure 500 {object} domain.HTTPError
 // @Router /v1/whatsapp/send-text [post]
 func (w *WhatsappHandler) SendText(c *fiber.Ctx) error {
+	wu, err := w.session(c)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
+
 	// Instantiate new Book struct
 	var form domain.WaSendTextForm
 	form.Msisdn = c.FormValue("msisdn")
-	form.Text = c.FormValue("text")
 	form.MsgQuotedID = c.FormValue("msg_quoted_id")
 	form.MsgQuoted = c.FormValue("msg_quoted")
 
+	form.Text, err = w.templateText(c, c.FormValue("text"))
+	if err != nil {
+		return templateError(c, err)
+	}
+
+	if v := c.FormValue("typing_seconds"); v != "" {
+		form.TypingSeconds, err = strconv.Atoi(v)
+		if err != nil {
+			return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+		}
+	}
+
 	// Validate form input
-	err := w.Validate.Struct(&form)
+	err = w.Validate.Struct(&form)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ValidatorErrors(err))
 	}
 
-	msgId, err := w.WhatsappUsecase.SendText(form)
+	if schedule, ok := scheduleForm(c); ok {
+		return w.schedule(c, domain.NewTextRequest(form), nil, schedule)
+	}
+
+	if c.FormValue("async") == "true" {
+		return w.enqueue(c, domain.NewTextRequest(form), nil)
+	}
+
+	msgId, err := wu.SendText(form)
 	if err != nil {
-		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+		return w.sendError(c, fiber.StatusBadRequest, err, domain.NewTextRequest(form), nil)
 	}
 
 	return c.JSON(domain.JSONResult{
@@ -172,14 +561,26 @@ func (w *WhatsappHandler) SendText(c *fiber.Ctx) error {
 // @Param longitude formData number false "Longitude. eg: 105.2937439"
 // @Param msg_quoted_id formData string false "Message Quoted ID"
 // @Param msg_quoted formData string false "Message Quoted"
+// @Param Idempotency-Key header string false "Answer a retry with this key with the response of the first request, instead of sending the message again"
+// @Param async formData bool false "Queue the message and return the send job instead of waiting for it to be sent"
+// @Param send_at formData string false "Send the message at this time, RFC3339 or YYYY-MM-DD HH:MM in the timezone, the response is the schedule"
+// @Param cron formData string false "Send the message at every time of this cron expression, eg: 0 9 * * MON, the response is the schedule"
+// @Param timezone formData string false "Timezone of send_at and cron, default UTC. eg: Asia/Jakarta"
+// @Param catch_up formData string false "Runs missed during a downtime: skip them, send once or send all, default to SCHEDULE_CATCH_UP" Enums(skip, once, all)
 // @Success 200 {object} domain.JSONResult{data=object,message=string} "Description"
+// @Success 202 {object} domain.JSONResult{data=domain.WaSendJob,message=string} "Queued when async is true"
 // @Failure 422 {object} []domain.HTTPErrorValidation
 // @Failure 400 {object} domain.HTTPError
 // @Failure 404 {object} domain.HTTPError
+// @Failure 409 {object} domain.HTTPError "Idempotency-Key already used for a different request"
+// @Failure 429 {object} domain.HTTPError "Over the rate limit, retry after the Retry-After header seconds"
 // @Failure 500 {object} domain.HTTPError
 // @Router /v1/whatsapp/send-location [post]
 func (w *WhatsappHandler) SendLocation(c *fiber.Ctx) error {
-	var err error
+	wu, err := w.session(c)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
 
 	var form domain.WaSendLocationForm
 	form.Msisdn = c.FormValue("msisdn")
@@ -202,7 +603,18 @@ func (w *WhatsappHandler) SendLocation(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ValidatorErrors(err))
 	}
 
-	msgId, err := w.WhatsappUsecase.SendLocation(form)
+	if schedule, ok := scheduleForm(c); ok {
+		return w.schedule(c, domain.NewLocationRequest(form), nil, schedule)
+	}
+
+	if c.FormValue("async") == "true" {
+		return w.enqueue(c, domain.NewLocationRequest(form), nil)
+	}
+
+	msgId, err := wu.SendLocation(form)
+	if err != nil {
+		return w.sendError(c, fiber.StatusBadRequest, err, domain.NewLocationRequest(form), nil)
+	}
 
 	return c.JSON(domain.JSONResult{
 		Data: map[string]string{"message_id": msgId},
@@ -210,6 +622,151 @@ func (w *WhatsappHandler) SendLocation(c *fiber.Ctx) error {
 	})
 }
 
+// SendContact func for send a contact.
+// @Summary send contact message
+// @Description Send a contact card. The vCard 3.0 is built from the name, phones, emails and organization, or read from
+// @Description the uploaded vcard_file instead. A vcard_file holding several cards sends them all in one message.
+// @Tags Messaging
+// @Accept mpfd
+// @Produce json
+// @Param msisdn formData string true "Destination number. eg: 6281255423 or group_creator-timstamp_created -> 6281271471566-1619679643 for group"
+// @Param name formData string false "Contact name, required without vcard_file"
+// @Param phones formData []string false "Contact phone numbers, at least one without vcard_file. eg: 6281234567890" collectionFormat(multi)
+// @Param emails formData []string false "Contact emails" collectionFormat(multi)
+// @Param organization formData string false "Contact organization"
+// @Param vcard_file formData file false "vCard file, instead of the contact fields"
+// @Param msg_quoted_id formData string false "Message Quoted ID"
+// @Param msg_quoted formData string false "Message Quoted"
+// @Param Idempotency-Key header string false "Answer a retry with this key with the response of the first request, instead of sending the message again"
+// @Param async formData bool false "Queue the message and return the send job instead of waiting for it to be sent"
+// @Param send_at formData string false "Send the message at this time, RFC3339 or YYYY-MM-DD HH:MM in the timezone, the response is the schedule"
+// @Param cron formData string false "Send the message at every time of this cron expression, eg: 0 9 * * MON, the response is the schedule"
+// @Param timezone formData string false "Timezone of send_at and cron, default UTC. eg: Asia/Jakarta"
+// @Param catch_up formData string false "Runs missed during a downtime: skip them, send once or send all, default to SCHEDULE_CATCH_UP" Enums(skip, once, all)
+// @Success 200 {object} domain.JSONResult{data=object,message=string} "Description"
+// @Success 202 {object} domain.JSONResult{data=domain.WaSendJob,message=string} "Queued when async is true"
+// @Failure 422 {object} []domain.HTTPErrorValidation
+// @Failure 400 {object} domain.HTTPError
+// @Failure 404 {object} domain.HTTPError
+// @Failure 409 {object} domain.HTTPError "Idempotency-Key already used for a different request"
+// @Failure 429 {object} domain.HTTPError "Over the rate limit, retry after the Retry-After header seconds"
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/whatsapp/send-contact [post]
+func (w *WhatsappHandler) SendContact(c *fiber.Ctx) error {
+	wu, err := w.session(c)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
+
+	var form domain.WaSendContactForm
+	form.Msisdn = c.FormValue("msisdn")
+	form.MsgQuotedID = c.FormValue("msg_quoted_id")
+	form.MsgQuoted = c.FormValue("msg_quoted")
+
+	contact := domain.WaContactCard{
+		Name:         c.FormValue("name"),
+		Phones:       formValues(c, "phones"),
+		Emails:       formValues(c, "emails"),
+		Organization: c.FormValue("organization"),
+	}
+	contact.Vcard, err = vcardFile(c)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+	form.Contacts = []domain.WaContactCard{contact}
+
+	// Validate form input
+	err = w.Validate.Struct(&form)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ValidatorErrors(err))
+	}
+
+	return w.sendContacts(c, wu, form)
+}
+
+// SendContacts func for send several contacts.
+// @Summary send contacts message
+// @Description Send several contact cards at once, in a single message. contacts is a JSON array of
+// @Description {"name", "phones", "emails", "organization"} or {"vcard"} objects, the cards of the uploaded vcard_file are added to them.
+// @Tags Messaging
+// @Accept mpfd
+// @Produce json
+// @Param msisdn formData string true "Destination number. eg: 6281255423 or group_creator-timstamp_created -> 6281271471566-1619679643 for group"
+// @Param contacts formData string false "Contacts, JSON array. eg: [{\"name\": \"Budi\", \"phones\": [\"6281234567890\"]}]"
+// @Param vcard_file formData file false "vCard file holding one or several cards"
+// @Param msg_quoted_id formData string false "Message Quoted ID"
+// @Param msg_quoted formData string false "Message Quoted"
+// @Param Idempotency-Key header string false "Answer a retry with this key with the response of the first request, instead of sending the message again"
+// @Param async formData bool false "Queue the message and return the send job instead of waiting for it to be sent"
+// @Param send_at formData string false "Send the message at this time, RFC3339 or YYYY-MM-DD HH:MM in the timezone, the response is the schedule"
+// @Param cron formData string false "Send the message at every time of this cron expression, eg: 0 9 * * MON, the response is the schedule"
+// @Param timezone formData string false "Timezone of send_at and cron, default UTC. eg: Asia/Jakarta"
+// @Param catch_up formData string false "Runs missed during a downtime: skip them, send once or send all, default to SCHEDULE_CATCH_UP" Enums(skip, once, all)
+// @Success 200 {object} domain.JSONResult{data=object,message=string} "Description"
+// @Success 202 {object} domain.JSONResult{data=domain.WaSendJob,message=string} "Queued when async is true"
+// @Failure 422 {object} []domain.HTTPErrorValidation
+// @Failure 400 {object} domain.HTTPError
+// @Failure 404 {object} domain.HTTPError
+// @Failure 409 {object} domain.HTTPError "Idempotency-Key already used for a different request"
+// @Failure 429 {object} domain.HTTPError "Over the rate limit, retry after the Retry-After header seconds"
+// @Failure 500 {object} domain.HTTPError
+// @Router /v1/whatsapp/send-contacts [post]
+func (w *WhatsappHandler) SendContacts(c *fiber.Ctx) error {
+	wu, err := w.session(c)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
+
+	var form domain.WaSendContactForm
+	form.Msisdn = c.FormValue("msisdn")
+	form.MsgQuotedID = c.FormValue("msg_quoted_id")
+	form.MsgQuoted = c.FormValue("msg_quoted")
+
+	if v := c.FormValue("contacts"); v != "" {
+		err = json.Unmarshal([]byte(v), &form.Contacts)
+		if err != nil {
+			return domain.NewHttpError(c, fiber.StatusBadRequest, fmt.Errorf("%w: contacts must be a JSON array", domain.ErrInvalidContact))
+		}
+	}
+
+	vcard, err := vcardFile(c)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+	if vcard != "" {
+		form.Contacts = append(form.Contacts, domain.WaContactCard{Vcard: vcard})
+	}
+
+	// Validate form input
+	err = w.Validate.Struct(&form)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ValidatorErrors(err))
+	}
+
+	return w.sendContacts(c, wu, form)
+}
+
+// sendContacts sends, queues or schedules the contacts of a validated form.
+func (w *WhatsappHandler) sendContacts(c *fiber.Ctx, wu domain.WhatsappUsecase, form domain.WaSendContactForm) error {
+	if schedule, ok := scheduleForm(c); ok {
+		return w.schedule(c, domain.NewContactRequest(form), nil, schedule)
+	}
+
+	if c.FormValue("async") == "true" {
+		return w.enqueue(c, domain.NewContactRequest(form), nil)
+	}
+
+	msgId, err := wu.SendContacts(form)
+	if err != nil {
+		return w.sendError(c, fiber.StatusBadRequest, err, domain.NewContactRequest(form), nil)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    map[string]string{"message_id": msgId},
+		Message: "Success",
+	})
+}
+
 // SendImage func for send image.
 // @Summary send image message
 // @Description Send image message.
@@ -221,20 +778,38 @@ func (w *WhatsappHandler) SendLocation(c *fiber.Ctx) error {
 // @Param msg_quoted_id formData string false "Message Quoted ID"
 // @Param msg_quoted formData string false "Message Quoted"
 // @Param message formData string false "Message to include"
+// @Param template_id formData string false "Template ID, sends the rendered template instead of the message"
+// @Param variables formData string false "Template variables, JSON object. eg: {\"name\": \"Budi\"}"
+// @Param language formData string false "Template language variant. eg: id"
+// @Param Idempotency-Key header string false "Answer a retry with this key with the response of the first request, instead of sending the message again"
+// @Param async formData bool false "Queue the message and return the send job instead of waiting for it to be sent"
+// @Param send_at formData string false "Send the message at this time, RFC3339 or YYYY-MM-DD HH:MM in the timezone, the response is the schedule"
+// @Param cron formData string false "Send the message at every time of this cron expression, eg: 0 9 * * MON, the response is the schedule"
+// @Param timezone formData string false "Timezone of send_at and cron, default UTC. eg: Asia/Jakarta"
+// @Param catch_up formData string false "Runs missed during a downtime: skip them, send once or send all, default to SCHEDULE_CATCH_UP" Enums(skip, once, all)
 // @Success 200 {object} domain.JSONResult{data=object,message=string} "Description"
+// @Success 202 {object} domain.JSONResult{data=domain.WaSendJob,message=string} "Queued when async is true"
 // @Failure 422 {object} []domain.HTTPErrorValidation
 // @Failure 400 {object} domain.HTTPError
 // @Failure 404 {object} domain.HTTPError
+// @Failure 409 {object} domain.HTTPError "Idempotency-Key already used for a different request"
+// @Failure 429 {object} domain.HTTPError "Over the rate limit, retry after the Retry-After header seconds"
 // @Failure 500 {object} domain.HTTPError
 // @Router /v1/whatsapp/send-image [post]
 func (w *WhatsappHandler) SendImage(c *fiber.Ctx) error {
-	var err error
+	wu, err := w.session(c)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
 
 	var form domain.WaSendFileForm
 	form.Msisdn = c.FormValue("msisdn")
 	form.MsgQuotedID = c.FormValue("msg_quoted_id")
 	form.MsgQuoted = c.FormValue("msg_quoted")
-	form.Message = c.FormValue("message")
+	form.Message, err = w.templateText(c, c.FormValue("message"))
+	if err != nil {
+		return templateError(c, err)
+	}
 
 	form.FileHeader, err = c.FormFile("image_file")
 	if err != nil {
@@ -248,9 +823,17 @@ func (w *WhatsappHandler) SendImage(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ValidatorErrors(err))
 	}
 
-	msgId, err := w.WhatsappUsecase.SendFile(form, "image")
+	if schedule, ok := scheduleForm(c); ok {
+		return w.schedule(c, domain.NewFileRequest(form, "image"), form.FileHeader, schedule)
+	}
+
+	if c.FormValue("async") == "true" {
+		return w.enqueue(c, domain.NewFileRequest(form, "image"), form.FileHeader)
+	}
+
+	msgId, err := wu.SendFile(form, "image")
 	if err != nil {
-		return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+		return w.sendError(c, fiber.StatusInternalServerError, err, domain.NewFileRequest(form, "image"), form.FileHeader)
 	}
 
 	return c.JSON(domain.JSONResult{
@@ -270,20 +853,38 @@ func (w *WhatsappHandler) SendImage(c *fiber.Ctx) error {
 // @Param msg_quoted_id formData string false "Message Quoted ID"
 // @Param msg_quoted formData string false "Message Quoted"
 // @Param message formData string false "Message to include"
+// @Param template_id formData string false "Template ID, sends the rendered template instead of the message"
+// @Param variables formData string false "Template variables, JSON object. eg: {\"name\": \"Budi\"}"
+// @Param language formData string false "Template language variant. eg: id"
+// @Param Idempotency-Key header string false "Answer a retry with this key with the response of the first request, instead of sending the message again"
+// @Param async formData bool false "Queue the message and return the send job instead of waiting for it to be sent"
+// @Param send_at formData string false "Send the message at this time, RFC3339 or YYYY-MM-DD HH:MM in the timezone, the response is the schedule"
+// @Param cron formData string false "Send the message at every time of this cron expression, eg: 0 9 * * MON, the response is the schedule"
+// @Param timezone formData string false "Timezone of send_at and cron, default UTC. eg: Asia/Jakarta"
+// @Param catch_up formData string false "Runs missed during a downtime: skip them, send once or send all, default to SCHEDULE_CATCH_UP" Enums(skip, once, all)
 // @Success 200 {object} domain.JSONResult{data=object,message=string} "Description"
+// @Success 202 {object} domain.JSONResult{data=domain.WaSendJob,message=string} "Queued when async is true"
 // @Failure 422 {object} []domain.HTTPErrorValidation
 // @Failure 400 {object} domain.HTTPError
 // @Failure 404 {object} domain.HTTPError
+// @Failure 409 {object} domain.HTTPError "Idempotency-Key already used for a different request"
+// @Failure 429 {object} domain.HTTPError "Over the rate limit, retry after the Retry-After header seconds"
 // @Failure 500 {object} domain.HTTPError
 // @Router /v1/whatsapp/send-audio [post]
 func (w *WhatsappHandler) SendAudio(c *fiber.Ctx) error {
-	var err error
+	wu, err := w.session(c)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
 
 	var form domain.WaSendFileForm
 	form.Msisdn = c.FormValue("msisdn")
 	form.MsgQuotedID = c.FormValue("msg_quoted_id")
 	form.MsgQuoted = c.FormValue("msg_quoted")
-	form.Message = c.FormValue("message")
+	form.Message, err = w.templateText(c, c.FormValue("message"))
+	if err != nil {
+		return templateError(c, err)
+	}
 
 	form.FileHeader, err = c.FormFile("audio_file")
 	if err != nil {
@@ -297,9 +898,17 @@ func (w *WhatsappHandler) SendAudio(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ValidatorErrors(err))
 	}
 
-	msgId, err := w.WhatsappUsecase.SendFile(form, "audio")
+	if schedule, ok := scheduleForm(c); ok {
+		return w.schedule(c, domain.NewFileRequest(form, "audio"), form.FileHeader, schedule)
+	}
+
+	if c.FormValue("async") == "true" {
+		return w.enqueue(c, domain.NewFileRequest(form, "audio"), form.FileHeader)
+	}
+
+	msgId, err := wu.SendFile(form, "audio")
 	if err != nil {
-		return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+		return w.sendError(c, fiber.StatusInternalServerError, err, domain.NewFileRequest(form, "audio"), form.FileHeader)
 	}
 
 	return c.JSON(domain.JSONResult{
@@ -319,20 +928,38 @@ func (w *WhatsappHandler) SendAudio(c *fiber.Ctx) error {
 // @Param msg_quoted_id formData string false "Message Quoted ID"
 // @Param msg_quoted formData string false "Message Quoted"
 // @Param message formData string false "Message to include"
+// @Param template_id formData string false "Template ID, sends the rendered template instead of the message"
+// @Param variables formData string false "Template variables, JSON object. eg: {\"name\": \"Budi\"}"
+// @Param language formData string false "Template language variant. eg: id"
+// @Param Idempotency-Key header string false "Answer a retry with this key with the response of the first request, instead of sending the message again"
+// @Param async formData bool false "Queue the message and return the send job instead of waiting for it to be sent"
+// @Param send_at formData string false "Send the message at this time, RFC3339 or YYYY-MM-DD HH:MM in the timezone, the response is the schedule"
+// @Param cron formData string false "Send the message at every time of this cron expression, eg: 0 9 * * MON, the response is the schedule"
+// @Param timezone formData string false "Timezone of send_at and cron, default UTC. eg: Asia/Jakarta"
+// @Param catch_up formData string false "Runs missed during a downtime: skip them, send once or send all, default to SCHEDULE_CATCH_UP" Enums(skip, once, all)
 // @Success 200 {object} domain.JSONResult{data=object,message=string} "Description"
+// @Success 202 {object} domain.JSONResult{data=domain.WaSendJob,message=string} "Queued when async is true"
 // @Failure 422 {object} []domain.HTTPErrorValidation
 // @Failure 400 {object} domain.HTTPError
 // @Failure 404 {object} domain.HTTPError
+// @Failure 409 {object} domain.HTTPError "Idempotency-Key already used for a different request"
+// @Failure 429 {object} domain.HTTPError "Over the rate limit, retry after the Retry-After header seconds"
 // @Failure 500 {object} domain.HTTPError
 // @Router /v1/whatsapp/send-video [post]
 func (w *WhatsappHandler) SendVideo(c *fiber.Ctx) error {
-	var err error
+	wu, err := w.session(c)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
 
 	var form domain.WaSendFileForm
 	form.Msisdn = c.FormValue("msisdn")
 	form.MsgQuotedID = c.FormValue("msg_quoted_id")
 	form.MsgQuoted = c.FormValue("msg_quoted")
-	form.Message = c.FormValue("message")
+	form.Message, err = w.templateText(c, c.FormValue("message"))
+	if err != nil {
+		return templateError(c, err)
+	}
 
 	form.FileHeader, err = c.FormFile("video_file")
 	if err != nil {
@@ -346,9 +973,17 @@ func (w *WhatsappHandler) SendVideo(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ValidatorErrors(err))
 	}
 
-	msgId, err := w.WhatsappUsecase.SendFile(form, "video")
+	if schedule, ok := scheduleForm(c); ok {
+		return w.schedule(c, domain.NewFileRequest(form, "video"), form.FileHeader, schedule)
+	}
+
+	if c.FormValue("async") == "true" {
+		return w.enqueue(c, domain.NewFileRequest(form, "video"), form.FileHeader)
+	}
+
+	msgId, err := wu.SendFile(form, "video")
 	if err != nil {
-		return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+		return w.sendError(c, fiber.StatusInternalServerError, err, domain.NewFileRequest(form, "video"), form.FileHeader)
 	}
 
 	return c.JSON(domain.JSONResult{
@@ -368,20 +1003,38 @@ func (w *WhatsappHandler) SendVideo(c *fiber.Ctx) error {
 // @Param msg_quoted_id formData string false "Message Quoted ID"
 // @Param msg_quoted formData string false "Message Quoted"
 // @Param message formData string false "Message to include"
+// @Param template_id formData string false "Template ID, sends the rendered template instead of the message"
+// @Param variables formData string false "Template variables, JSON object. eg: {\"name\": \"Budi\"}"
+// @Param language formData string false "Template language variant. eg: id"
+// @Param Idempotency-Key header string false "Answer a retry with this key with the response of the first request, instead of sending the message again"
+// @Param async formData bool false "Queue the message and return the send job instead of waiting for it to be sent"
+// @Param send_at formData string false "Send the message at this time, RFC3339 or YYYY-MM-DD HH:MM in the timezone, the response is the schedule"
+// @Param cron formData string false "Send the message at every time of this cron expression, eg: 0 9 * * MON, the response is the schedule"
+// @Param timezone formData string false "Timezone of send_at and cron, default UTC. eg: Asia/Jakarta"
+// @Param catch_up formData string false "Runs missed during a downtime: skip them, send once or send all, default to SCHEDULE_CATCH_UP" Enums(skip, once, all)
 // @Success 200 {object} domain.JSONResult{data=object,message=string} "Description"
+// @Success 202 {object} domain.JSONResult{data=domain.WaSendJob,message=string} "Queued when async is true"
 // @Failure 422 {object} []domain.HTTPErrorValidation
 // @Failure 400 {object} domain.HTTPError
 // @Failure 404 {object} domain.HTTPError
+// @Failure 409 {object} domain.HTTPError "Idempotency-Key already used for a different request"
+// @Failure 429 {object} domain.HTTPError "Over the rate limit, retry after the Retry-After header seconds"
 // @Failure 500 {object} domain.HTTPError
 // @Router /v1/whatsapp/send-document [post]
 func (w *WhatsappHandler) SendDocument(c *fiber.Ctx) error {
-	var err error
+	wu, err := w.session(c)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
 
 	var form domain.WaSendFileForm
 	form.Msisdn = c.FormValue("msisdn")
 	form.MsgQuotedID = c.FormValue("msg_quoted_id")
 	form.MsgQuoted = c.FormValue("msg_quoted")
-	form.Message = c.FormValue("message")
+	form.Message, err = w.templateText(c, c.FormValue("message"))
+	if err != nil {
+		return templateError(c, err)
+	}
 
 	form.FileHeader, err = c.FormFile("document_file")
 	if err != nil {
@@ -395,9 +1048,17 @@ func (w *WhatsappHandler) SendDocument(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ValidatorErrors(err))
 	}
 
-	msgId, err := w.WhatsappUsecase.SendFile(form, "document")
+	if schedule, ok := scheduleForm(c); ok {
+		return w.schedule(c, domain.NewFileRequest(form, "document"), form.FileHeader, schedule)
+	}
+
+	if c.FormValue("async") == "true" {
+		return w.enqueue(c, domain.NewFileRequest(form, "document"), form.FileHeader)
+	}
+
+	msgId, err := wu.SendFile(form, "document")
 	if err != nil {
-		return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
+		return w.sendError(c, fiber.StatusInternalServerError, err, domain.NewFileRequest(form, "document"), form.FileHeader)
 	}
 
 	return c.JSON(domain.JSONResult{
@@ -406,6 +1067,116 @@ func (w *WhatsappHandler) SendDocument(c *fiber.Ctx) error {
 	})
 }
 
+// enqueue queues the message of a send request with async=true, the response
+// is the send job to follow with /jobs/{id}.
+func (w *WhatsappHandler) enqueue(c *fiber.Ctx, request domain.WaSendRequest, file *multipart.FileHeader) error {
+	job, err := w.SendQueue.Enqueue(c.Params("session_id", domain.DefaultSessionID), request, file)
+	if err != nil {
+		return sendQueueError(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(domain.JSONResult{
+		Data:    job,
+		Message: "Success",
+	})
+}
+
+// templateText returns text, or the template_id template rendered with the
+// variables and language form values when it is set.
+func (w *WhatsappHandler) templateText(c *fiber.Ctx, text string) (string, error) {
+	templateID := c.FormValue("template_id")
+	if templateID == "" {
+		return text, nil
+	}
+	if text != "" {
+		return "", fmt.Errorf("%w: send either a text or a template_id", domain.ErrInvalidTemplate)
+	}
+
+	form := domain.TemplateRenderForm{Language: c.FormValue("language")}
+	if v := c.FormValue("variables"); v != "" {
+		err := json.Unmarshal([]byte(v), &form.Variables)
+		if err != nil {
+			return "", fmt.Errorf("%w: variables must be a JSON object of strings", domain.ErrInvalidTemplate)
+		}
+	}
+
+	render, err := w.Templates.Render(templateID, form)
+	if err != nil {
+		return "", err
+	}
+
+	return render.Text, nil
+}
+
+// vcardFile returns the content of the uploaded vcard_file, empty without it.
+func vcardFile(c *fiber.Ctx) (string, error) {
+	fileHeader, err := c.FormFile("vcard_file")
+	if err != nil {
+		return "", nil
+	}
+	if fileHeader.Size > vcardFileMaxSize {
+		return "", fmt.Errorf("%w: vcard_file is larger than %d bytes", domain.ErrInvalidContact, vcardFileMaxSize)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	b, err := ioutil.ReadAll(file)
+
+	return string(b), err
+}
+
+// schedule schedules the message of a send request with send_at or cron, the
+// response is the schedule to follow with /schedules/{id}.
+func (w *WhatsappHandler) schedule(c *fiber.Ctx, request domain.WaSendRequest, file *multipart.FileHeader, form domain.WaScheduleForm) error {
+	schedule, err := w.Scheduler.Schedule(c.Params("session_id", domain.DefaultSessionID), request, file, form)
+	if err != nil {
+		return scheduleError(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(domain.JSONResult{
+		Data:    schedule,
+		Message: "Success",
+	})
+}
+
+// scheduleForm returns the schedule of a send request, ok is false when the
+// message is to be sent now.
+func scheduleForm(c *fiber.Ctx) (form domain.WaScheduleForm, ok bool) {
+	form = domain.WaScheduleForm{
+		SendAt:   c.FormValue("send_at"),
+		Cron:     c.FormValue("cron"),
+		Timezone: c.FormValue("timezone"),
+		CatchUp:  domain.WaScheduleCatchUp(c.FormValue("catch_up")),
+	}
+
+	return form, form.SendAt != "" || form.Cron != ""
+}
+
+// sendError answers a failed send. Over the rate limit the message is queued
+// or refused with a Retry-After header, as configured by RATE_LIMIT_OVERFLOW.
+func (w *WhatsappHandler) sendError(c *fiber.Ctx, status int, err error, request domain.WaSendRequest, file *multipart.FileHeader) error {
+	var limited *domain.WaRateLimitError
+	if !errors.As(err, &limited) {
+		return domain.NewHttpError(c, status, err)
+	}
+
+	if w.RateLimiter.Overflow() == domain.WaRateLimitQueue {
+		return w.enqueue(c, request, file)
+	}
+
+	retryAfter := int(math.Ceil(limited.RetryAfter.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+	return domain.NewHttpError(c, fiber.StatusTooManyRequests, err)
+}
+
 // Groups func for get group metadata.
 // @Summary get group metadata
 // @Description Get group metadata by phone number.
@@ -419,12 +1190,17 @@ func (w *WhatsappHandler) SendDocument(c *fiber.Ctx) error {
 // @Failure 500 {object} domain.HTTPError
 // @Router /v1/whatsapp/groups/{jid} [get]
 func (w *WhatsappHandler) Groups(c *fiber.Ctx) error {
+	wu, err := w.session(c)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
+
 	jid := c.Params("jid")
 	if len(jid) == 0 {
 		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"message": "invalid jid"})
 	}
 
-	groupMd, err := w.WhatsappUsecase.Groups(jid)
+	groupMd, err := wu.Groups(jid)
 	if err != nil {
 		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
 	}
@@ -441,6 +1217,173 @@ func (w *WhatsappHandler) Groups(c *fiber.Ctx) error {
 	})
 }
 
+// Chats func for list the chats.
+// @Summary list chats
+// @Description List the chats of the phone, pinned first then by last message, with their unread count, archived and muted flags
+// @Description and the last message stored by the service.
+// @Tags Chat
+// @Produce json
+// @Param q query string false "Search in the jid and the name"
+// @Param archived query bool false "Only the archived chats, or only the others"
+// @Param unread query bool false "Only the chats with unread messages"
+// @Param page query int false "Page, default 1"
+// @Param per_page query int false "Chats per page, default 20, max 100"
+// @Success 200 {object} domain.JSONResult{data=[]domain.WaChat,meta=domain.JSONResultMeta,message=string} "Description"
+// @Failure 400 {object} domain.HTTPError
+// @Failure 404 {object} domain.HTTPError
+// @Router /v1/whatsapp/chats [get]
+func (w *WhatsappHandler) Chats(c *fiber.Ctx) error {
+	wu, err := w.session(c)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
+
+	filter := domain.WaChatFilter{
+		Query:   c.Query("q"),
+		Unread:  c.Query("unread") == "true",
+		Page:    queryInt(c, "page", 1),
+		PerPage: queryInt(c, "per_page", 20),
+	}
+
+	if v := c.Query("archived"); v != "" {
+		archived, err := strconv.ParseBool(v)
+		if err != nil {
+			return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+		}
+		filter.Archived = &archived
+	}
+
+	chats, meta, err := wu.Chats(filter)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    chats,
+		Meta:    meta,
+		Message: "Success",
+	})
+}
+
+// ChatMessages func for page through the history of a chat.
+// @Summary chat history
+// @Description Load the history of a chat from the phone, newest first, in the same shape as the stored messages and the webhooks.
+// @Description Page backwards by passing meta.before as the before of the next request.
+// @Tags Chat
+// @Produce json
+// @Param jid path string true "JID or msisdn, eg: 6281234567890@s.whatsapp.net"
+// @Param before query string false "Load the messages sent before this message ID, default to the latest messages"
+// @Param count query int false "Messages to load, default 20, max 100"
+// @Success 200 {object} domain.JSONResult{data=[]domain.WaMessage,meta=domain.WaChatHistoryMeta,message=string} "Description"
+// @Failure 400 {object} domain.HTTPError
+// @Failure 404 {object} domain.HTTPError
+// @Router /v1/whatsapp/chats/{jid}/messages [get]
+func (w *WhatsappHandler) ChatMessages(c *fiber.Ctx) error {
+	wu, err := w.session(c)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
+
+	messages, meta, err := wu.ChatMessages(c.Params("jid"), c.Query("before"), queryInt(c, "count", 20))
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    messages,
+		Meta:    meta,
+		Message: "Success",
+	})
+}
+
+// MarkRead func for mark a chat as read.
+// @Summary mark chat as read
+// @Description Mark a chat as read on the phone, up to the given message or up to its latest message.
+// @Tags Chat
+// @Accept mpfd
+// @Produce json
+// @Param jid path string true "JID or msisdn, eg: 6281234567890@s.whatsapp.net"
+// @Param message_id formData string false "Mark as read up to this message ID, default to the latest received message of the chat"
+// @Success 200 {object} domain.JSONResult{data=object,message=string} "Description"
+// @Failure 400 {object} domain.HTTPError
+// @Failure 404 {object} domain.HTTPError
+// @Router /v1/whatsapp/chats/{jid}/read [post]
+func (w *WhatsappHandler) MarkRead(c *fiber.Ctx) error {
+	wu, err := w.session(c)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
+
+	msgId, err := wu.MarkRead(c.Params("jid"), c.FormValue("message_id"))
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    map[string]string{"message_id": msgId},
+		Message: "Success",
+	})
+}
+
+// ChatState func for send a chat state.
+// @Summary send chat state
+// @Description Show the chat that the session is typing (composing), recording an audio or stopped (paused).
+// @Tags Chat
+// @Accept mpfd
+// @Produce json
+// @Param jid path string true "JID or msisdn, eg: 6281234567890@s.whatsapp.net"
+// @Param state formData string true "Chat state" Enums(composing, recording, paused)
+// @Success 200 {object} domain.JSONResult{data=string,message=string} "Description"
+// @Failure 400 {object} domain.HTTPError
+// @Failure 404 {object} domain.HTTPError
+// @Router /v1/whatsapp/chats/{jid}/state [post]
+func (w *WhatsappHandler) ChatState(c *fiber.Ctx) error {
+	wu, err := w.session(c)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
+
+	state := domain.WaChatState(c.FormValue("state"))
+	err = wu.SendChatState(c.Params("jid"), state)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    state,
+		Message: "Success",
+	})
+}
+
+// Presence func for set the presence of the session.
+// @Summary set presence
+// @Description Set the presence of the session seen by its contacts: available (online) or unavailable.
+// @Tags Chat
+// @Accept mpfd
+// @Produce json
+// @Param presence formData string true "Presence" Enums(available, unavailable)
+// @Success 200 {object} domain.JSONResult{data=string,message=string} "Description"
+// @Failure 400 {object} domain.HTTPError
+// @Failure 404 {object} domain.HTTPError
+// @Router /v1/whatsapp/presence [post]
+func (w *WhatsappHandler) Presence(c *fiber.Ctx) error {
+	wu, err := w.session(c)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
+
+	presence := domain.WaPresence(c.FormValue("presence"))
+	err = wu.SetPresence(presence)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusBadRequest, err)
+	}
+
+	return c.JSON(domain.JSONResult{
+		Data:    presence,
+		Message: "Success",
+	})
+}
+
 // Logout func logout whatsapp web.
 // @Description Logout from whatsapp web.
 // @Summary logout whatsapp web
@@ -453,7 +1396,12 @@ func (w *WhatsappHandler) Groups(c *fiber.Ctx) error {
 // @Failure 500 {object} domain.HTTPError
 // @Router /v1/whatsapp/logout [post]
 func (w *WhatsappHandler) Logout(c *fiber.Ctx) error {
-	err := w.WhatsappUsecase.Logout()
+	wu, err := w.session(c)
+	if err != nil {
+		return domain.NewHttpError(c, fiber.StatusNotFound, err)
+	}
+
+	err = wu.Logout()
 	if err != nil {
 		return domain.NewHttpError(c, fiber.StatusInternalServerError, err)
 	}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+)
+
+type sqliteAutoReplyRepository struct {
+	db *sql.DB
+}
+
+// NewSqliteAutoReplyRepository stores the auto-reply rules in the
+// auto_reply_rules table, the table is created when it does not exist yet.
+func NewSqliteAutoReplyRepository(db *sql.DB) (domain.AutoReplyRepository, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS auto_reply_rules (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		session_id TEXT NOT NULL,
+		match TEXT NOT NULL,
+		pattern TEXT NOT NULL,
+		case_sensitive BOOLEAN NOT NULL,
+		jids TEXT NOT NULL,
+		time_start TEXT NOT NULL,
+		time_end TEXT NOT NULL,
+		days TEXT NOT NULL,
+		timezone TEXT NOT NULL,
+		cooldown_seconds INTEGER NOT NULL,
+		priority INTEGER NOT NULL,
+		active BOOLEAN NOT NULL,
+		response TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	)`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqliteAutoReplyRepository{db: db}, nil
+}
+
+const autoReplyColumns = `id, name, session_id, match, pattern, case_sensitive, jids, time_start, time_end, days, timezone,
+	cooldown_seconds, priority, active, response, created_at, updated_at`
+
+func (r *sqliteAutoReplyRepository) Store(rule domain.AutoReplyRule) error {
+	jids, days, response, err := autoReplyJSON(rule)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`INSERT INTO auto_reply_rules (`+autoReplyColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rule.ID, rule.Name, rule.SessionID, rule.Match, rule.Pattern, rule.CaseSensitive, jids, rule.TimeStart, rule.TimeEnd,
+		days, rule.Timezone, rule.CooldownSeconds, rule.Priority, rule.Active, response,
+		rule.CreatedAt.UTC(), rule.UpdatedAt.UTC())
+
+	return err
+}
+
+func (r *sqliteAutoReplyRepository) Update(rule domain.AutoReplyRule) error {
+	jids, days, response, err := autoReplyJSON(rule)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.db.Exec(`UPDATE auto_reply_rules SET name = ?, session_id = ?, match = ?, pattern = ?, case_sensitive = ?,
+		jids = ?, time_start = ?, time_end = ?, days = ?, timezone = ?, cooldown_seconds = ?, priority = ?, active = ?,
+		response = ?, updated_at = ? WHERE id = ?`,
+		rule.Name, rule.SessionID, rule.Match, rule.Pattern, rule.CaseSensitive, jids, rule.TimeStart, rule.TimeEnd,
+		days, rule.Timezone, rule.CooldownSeconds, rule.Priority, rule.Active, response, rule.UpdatedAt.UTC(), rule.ID)
+	if err != nil {
+		return err
+	}
+
+	return affectedOne(res, domain.ErrAutoReplyRuleNotFound)
+}
+
+func (r *sqliteAutoReplyRepository) Delete(id string) error {
+	res, err := r.db.Exec(`DELETE FROM auto_reply_rules WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	return affectedOne(res, domain.ErrAutoReplyRuleNotFound)
+}
+
+func (r *sqliteAutoReplyRepository) GetByID(id string) (domain.AutoReplyRule, error) {
+	rule, err := scanAutoReplyRule(r.db.QueryRow(`SELECT `+autoReplyColumns+` FROM auto_reply_rules WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return rule, domain.ErrAutoReplyRuleNotFound
+	}
+
+	return rule, err
+}
+
+func (r *sqliteAutoReplyRepository) Fetch() ([]domain.AutoReplyRule, error) {
+	rows, err := r.db.Query(`SELECT ` + autoReplyColumns + ` FROM auto_reply_rules ORDER BY priority, created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []domain.AutoReplyRule{}
+	for rows.Next() {
+		rule, err := scanAutoReplyRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+func scanAutoReplyRule(row rowScanner) (domain.AutoReplyRule, error) {
+	var rule domain.AutoReplyRule
+	var jids, days, response string
+	err := row.Scan(&rule.ID, &rule.Name, &rule.SessionID, &rule.Match, &rule.Pattern, &rule.CaseSensitive, &jids,
+		&rule.TimeStart, &rule.TimeEnd, &days, &rule.Timezone, &rule.CooldownSeconds, &rule.Priority, &rule.Active,
+		&response, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return rule, err
+	}
+
+	if err = json.Unmarshal([]byte(jids), &rule.Jids); err != nil {
+		return rule, err
+	}
+	if err = json.Unmarshal([]byte(days), &rule.Days); err != nil {
+		return rule, err
+	}
+	err = json.Unmarshal([]byte(response), &rule.Response)
+
+	return rule, err
+}
+
+func autoReplyJSON(rule domain.AutoReplyRule) (jids, days, response string, err error) {
+	b, err := json.Marshal(rule.Jids)
+	if err != nil {
+		return
+	}
+	jids = string(b)
+
+	b, err = json.Marshal(rule.Days)
+	if err != nil {
+		return
+	}
+	days = string(b)
+
+	b, err = json.Marshal(rule.Response)
+	if err != nil {
+		return
+	}
+	response = string(b)
+
+	return
+}
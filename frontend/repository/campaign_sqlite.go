@@ -0,0 +1,303 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"time"
+)
+
+type sqliteCampaignRepository struct {
+	db *sql.DB
+}
+
+// NewSqliteCampaignRepository stores the campaigns and their recipients in
+// the campaigns and campaign_recipients tables, the tables are created when
+// they do not exist yet.
+func NewSqliteCampaignRepository(db *sql.DB) (domain.CampaignRepository, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS campaigns (
+		id TEXT PRIMARY KEY,
+		session_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		type TEXT NOT NULL,
+		text TEXT NOT NULL,
+		media_id TEXT NOT NULL,
+		status TEXT NOT NULL,
+		scheduled_at DATETIME,
+		started_at DATETIME,
+		completed_at DATETIME,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS campaigns_session ON campaigns (session_id, created_at);
+	CREATE INDEX IF NOT EXISTS campaigns_status ON campaigns (status);
+	CREATE TABLE IF NOT EXISTS campaign_recipients (
+		campaign_id TEXT NOT NULL,
+		row INTEGER NOT NULL,
+		msisdn TEXT NOT NULL,
+		variables TEXT NOT NULL,
+		status TEXT NOT NULL,
+		message_id TEXT NOT NULL,
+		error TEXT NOT NULL,
+		sent_at DATETIME,
+		updated_at DATETIME NOT NULL,
+		PRIMARY KEY (campaign_id, row)
+	);
+	CREATE INDEX IF NOT EXISTS campaign_recipients_status ON campaign_recipients (campaign_id, status, row)`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqliteCampaignRepository{db: db}, nil
+}
+
+const campaignColumns = `id, session_id, name, type, text, media_id, status, scheduled_at, started_at, completed_at,
+	created_at, updated_at`
+
+const campaignRecipientColumns = `campaign_id, row, msisdn, variables, status, message_id, error, sent_at, updated_at`
+
+func (r *sqliteCampaignRepository) Store(c domain.Campaign, recipients []domain.CampaignRecipient) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT INTO campaigns (`+campaignColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		c.ID, c.SessionID, c.Name, c.Type, c.Text, c.MediaID, c.Status, utcOrNil(c.ScheduledAt), utcOrNil(c.StartedAt),
+		utcOrNil(c.CompletedAt), c.CreatedAt.UTC(), c.UpdatedAt.UTC())
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO campaign_recipients (` + campaignRecipientColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, recipient := range recipients {
+		variables, err := json.Marshal(recipient.Variables)
+		if err != nil {
+			return err
+		}
+
+		_, err = stmt.Exec(recipient.CampaignID, recipient.Row, recipient.Msisdn, string(variables), recipient.Status,
+			recipient.MessageID, recipient.Error, utcOrNil(recipient.SentAt), recipient.UpdatedAt.UTC())
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *sqliteCampaignRepository) Update(c domain.Campaign) error {
+	res, err := r.db.Exec(`UPDATE campaigns SET name = ?, status = ?, scheduled_at = ?, started_at = ?, completed_at = ?,
+		updated_at = ? WHERE id = ?`,
+		c.Name, c.Status, utcOrNil(c.ScheduledAt), utcOrNil(c.StartedAt), utcOrNil(c.CompletedAt), c.UpdatedAt.UTC(), c.ID)
+	if err != nil {
+		return err
+	}
+
+	return affectedOne(res, domain.ErrCampaignNotFound)
+}
+
+func (r *sqliteCampaignRepository) GetByID(id string) (domain.Campaign, error) {
+	c, err := scanCampaign(r.db.QueryRow(`SELECT `+campaignColumns+` FROM campaigns WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return c, domain.ErrCampaignNotFound
+	}
+
+	return c, err
+}
+
+func (r *sqliteCampaignRepository) Fetch(sessionID string, page, perPage int) ([]domain.Campaign, int, error) {
+	var total int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM campaigns WHERE session_id = ?`, sessionID).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	campaigns, err := r.query(`SELECT `+campaignColumns+` FROM campaigns WHERE session_id = ?
+		ORDER BY created_at DESC LIMIT ? OFFSET ?`, sessionID, perPage, (page-1)*perPage)
+
+	return campaigns, total, err
+}
+
+func (r *sqliteCampaignRepository) FetchByStatus(status domain.CampaignStatus) ([]domain.Campaign, error) {
+	return r.query(`SELECT `+campaignColumns+` FROM campaigns WHERE status = ? ORDER BY created_at`, status)
+}
+
+func (r *sqliteCampaignRepository) Progress(campaignID string) (domain.CampaignProgress, error) {
+	var p domain.CampaignProgress
+
+	rows, err := r.db.Query(`SELECT status, COUNT(*) FROM campaign_recipients WHERE campaign_id = ? GROUP BY status`,
+		campaignID)
+	if err != nil {
+		return p, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status domain.CampaignRecipientStatus
+		var count int
+		if err = rows.Scan(&status, &count); err != nil {
+			return p, err
+		}
+
+		p.Total += count
+		switch status {
+		case domain.CampaignRecipientQueued:
+			p.Queued = count
+		case domain.CampaignRecipientSending:
+			p.Sending = count
+		case domain.CampaignRecipientSent:
+			p.Sent = count
+		case domain.CampaignRecipientFailed:
+			p.Failed = count
+		case domain.CampaignRecipientCancelled:
+			p.Cancelled = count
+		}
+	}
+
+	return p, rows.Err()
+}
+
+func (r *sqliteCampaignRepository) FetchRecipients(filter domain.CampaignRecipientFilter) ([]domain.CampaignRecipient, int, error) {
+	cond := ` WHERE campaign_id = ?`
+	args := []interface{}{filter.CampaignID}
+	if filter.Status != "" {
+		cond += ` AND status = ?`
+		args = append(args, filter.Status)
+	}
+
+	var total int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM campaign_recipients`+cond, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + campaignRecipientColumns + ` FROM campaign_recipients` + cond + ` ORDER BY row`
+	if filter.PerPage > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, filter.PerPage, (filter.Page-1)*filter.PerPage)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	recipients := []domain.CampaignRecipient{}
+	for rows.Next() {
+		recipient, err := scanCampaignRecipient(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		recipients = append(recipients, recipient)
+	}
+
+	return recipients, total, rows.Err()
+}
+
+func (r *sqliteCampaignRepository) NextRecipient(campaignID string) (domain.CampaignRecipient, bool, error) {
+	recipient, err := scanCampaignRecipient(r.db.QueryRow(`SELECT `+campaignRecipientColumns+` FROM campaign_recipients
+		WHERE campaign_id = ? AND status = ? ORDER BY row LIMIT 1`, campaignID, domain.CampaignRecipientQueued))
+	if err == sql.ErrNoRows {
+		return recipient, false, nil
+	}
+
+	return recipient, err == nil, err
+}
+
+func (r *sqliteCampaignRepository) UpdateRecipient(recipient domain.CampaignRecipient, from domain.CampaignRecipientStatus) (bool, error) {
+	res, err := r.db.Exec(`UPDATE campaign_recipients SET status = ?, message_id = ?, error = ?, sent_at = ?, updated_at = ?
+		WHERE campaign_id = ? AND row = ? AND status = ?`,
+		recipient.Status, recipient.MessageID, recipient.Error, utcOrNil(recipient.SentAt), recipient.UpdatedAt.UTC(),
+		recipient.CampaignID, recipient.Row, from)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+
+	return n > 0, err
+}
+
+func (r *sqliteCampaignRepository) FailSendingRecipients(reason string) error {
+	_, err := r.db.Exec(`UPDATE campaign_recipients SET status = ?, error = ?, updated_at = ? WHERE status = ?`,
+		domain.CampaignRecipientFailed, reason, time.Now().UTC(), domain.CampaignRecipientSending)
+
+	return err
+}
+
+func (r *sqliteCampaignRepository) CancelRecipients(campaignID string) error {
+	_, err := r.db.Exec(`UPDATE campaign_recipients SET status = ?, updated_at = ?
+		WHERE campaign_id = ? AND status = ?`,
+		domain.CampaignRecipientCancelled, time.Now().UTC(), campaignID, domain.CampaignRecipientQueued)
+
+	return err
+}
+
+func (r *sqliteCampaignRepository) query(query string, args ...interface{}) ([]domain.Campaign, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	campaigns := []domain.Campaign{}
+	for rows.Next() {
+		c, err := scanCampaign(rows)
+		if err != nil {
+			return nil, err
+		}
+		campaigns = append(campaigns, c)
+	}
+
+	return campaigns, rows.Err()
+}
+
+func scanCampaign(row rowScanner) (domain.Campaign, error) {
+	var c domain.Campaign
+	var scheduledAt, startedAt, completedAt sql.NullTime
+	err := row.Scan(&c.ID, &c.SessionID, &c.Name, &c.Type, &c.Text, &c.MediaID, &c.Status, &scheduledAt, &startedAt,
+		&completedAt, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return c, err
+	}
+
+	if scheduledAt.Valid {
+		c.ScheduledAt = &scheduledAt.Time
+	}
+	if startedAt.Valid {
+		c.StartedAt = &startedAt.Time
+	}
+	if completedAt.Valid {
+		c.CompletedAt = &completedAt.Time
+	}
+
+	return c, nil
+}
+
+func scanCampaignRecipient(row rowScanner) (domain.CampaignRecipient, error) {
+	var recipient domain.CampaignRecipient
+	var variables string
+	var sentAt sql.NullTime
+	err := row.Scan(&recipient.CampaignID, &recipient.Row, &recipient.Msisdn, &variables, &recipient.Status,
+		&recipient.MessageID, &recipient.Error, &sentAt, &recipient.UpdatedAt)
+	if err != nil {
+		return recipient, err
+	}
+
+	if sentAt.Valid {
+		recipient.SentAt = &sentAt.Time
+	}
+
+	err = json.Unmarshal([]byte(variables), &recipient.Variables)
+
+	return recipient, err
+}
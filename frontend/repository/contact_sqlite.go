@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"strings"
+)
+
+type sqliteContactRepository struct {
+	db *sql.DB
+}
+
+// NewSqliteContactRepository stores the contact directory in the
+// whatsapp_contacts table, the table is created when it does not exist yet.
+func NewSqliteContactRepository(db *sql.DB) (domain.WaContactRepository, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS whatsapp_contacts (
+		session_id TEXT NOT NULL,
+		jid TEXT NOT NULL,
+		name TEXT NOT NULL,
+		notify TEXT NOT NULL,
+		short TEXT NOT NULL,
+		verified_name TEXT NOT NULL,
+		business BOOLEAN NOT NULL,
+		updated_at DATETIME NOT NULL,
+		PRIMARY KEY (session_id, jid)
+	)`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqliteContactRepository{db: db}, nil
+}
+
+const contactColumns = `session_id, jid, name, notify, short, verified_name, business, updated_at`
+
+func (r *sqliteContactRepository) Save(c domain.WaContact) error {
+	_, err := r.db.Exec(`INSERT INTO whatsapp_contacts (`+contactColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(session_id, jid) DO UPDATE SET
+			name = CASE WHEN excluded.name != '' THEN excluded.name ELSE name END,
+			notify = CASE WHEN excluded.notify != '' THEN excluded.notify ELSE notify END,
+			short = CASE WHEN excluded.short != '' THEN excluded.short ELSE short END,
+			verified_name = CASE WHEN excluded.verified_name != '' THEN excluded.verified_name ELSE verified_name END,
+			business = business OR excluded.business,
+			updated_at = excluded.updated_at`,
+		c.SessionID, c.Jid, c.Name, c.Notify, c.Short, c.VerifiedName, c.Business, c.UpdatedAt.UTC())
+
+	return err
+}
+
+func (r *sqliteContactRepository) GetByJid(sessionID, jid string) (domain.WaContact, error) {
+	c, err := scanContact(r.db.QueryRow(`SELECT `+contactColumns+` FROM whatsapp_contacts WHERE session_id = ? AND jid = ?`,
+		sessionID, jid))
+	if err == sql.ErrNoRows {
+		return c, domain.ErrContactNotFound
+	}
+
+	return c, err
+}
+
+func (r *sqliteContactRepository) Fetch(filter domain.WaContactFilter) ([]domain.WaContact, int, error) {
+	where := []string{"session_id = ?"}
+	args := []interface{}{filter.SessionID}
+	if filter.Business != nil {
+		where = append(where, "business = ?")
+		args = append(args, *filter.Business)
+	}
+	if filter.Query != "" {
+		like := "%" + escapeLike(filter.Query) + "%"
+		where = append(where, `(jid LIKE ? ESCAPE '\' OR name LIKE ? ESCAPE '\' OR notify LIKE ? ESCAPE '\'
+			OR short LIKE ? ESCAPE '\' OR verified_name LIKE ? ESCAPE '\')`)
+		args = append(args, like, like, like, like, like)
+	}
+	cond := " WHERE " + strings.Join(where, " AND ")
+
+	var total int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM whatsapp_contacts`+cond, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// By display name, the contacts without any name last
+	args = append(args, filter.PerPage, (filter.Page-1)*filter.PerPage)
+	rows, err := r.db.Query(`SELECT `+contactColumns+` FROM whatsapp_contacts`+cond+`
+		ORDER BY COALESCE(NULLIF(name, ''), NULLIF(verified_name, ''), NULLIF(notify, ''), NULLIF(short, '')) IS NULL,
+			COALESCE(NULLIF(name, ''), NULLIF(verified_name, ''), NULLIF(notify, ''), NULLIF(short, '')) COLLATE NOCASE, jid
+		LIMIT ? OFFSET ?`, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	contacts := []domain.WaContact{}
+	for rows.Next() {
+		c, err := scanContact(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		contacts = append(contacts, c)
+	}
+
+	return contacts, total, rows.Err()
+}
+
+func scanContact(row rowScanner) (domain.WaContact, error) {
+	var c domain.WaContact
+	err := row.Scan(&c.SessionID, &c.Jid, &c.Name, &c.Notify, &c.Short, &c.VerifiedName, &c.Business, &c.UpdatedAt)
+	c.DisplayName = c.ResolveDisplayName()
+
+	return c, err
+}
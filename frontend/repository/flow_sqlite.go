@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"time"
+)
+
+type sqliteFlowRepository struct {
+	db *sql.DB
+}
+
+// NewSqliteFlowRepository stores the flows and the conversations of the
+// contacts in the flows and flow_conversations tables, the tables are created
+// when they do not exist yet.
+func NewSqliteFlowRepository(db *sql.DB) (domain.FlowRepository, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS flows (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		session_id TEXT NOT NULL,
+		jids TEXT NOT NULL,
+		trigger TEXT NOT NULL,
+		start TEXT NOT NULL,
+		timeout_seconds INTEGER NOT NULL,
+		priority INTEGER NOT NULL,
+		active BOOLEAN NOT NULL,
+		states TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS flow_conversations (
+		id TEXT PRIMARY KEY,
+		flow_id TEXT NOT NULL,
+		session_id TEXT NOT NULL,
+		jid TEXT NOT NULL,
+		contact_jid TEXT NOT NULL,
+		state TEXT NOT NULL,
+		variables TEXT NOT NULL,
+		retries INTEGER NOT NULL,
+		expires_at DATETIME,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		UNIQUE (session_id, jid, contact_jid)
+	);
+	CREATE INDEX IF NOT EXISTS flow_conversations_flow ON flow_conversations (flow_id);
+	CREATE INDEX IF NOT EXISTS flow_conversations_expires ON flow_conversations (expires_at)`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqliteFlowRepository{db: db}, nil
+}
+
+const flowColumns = `id, name, session_id, jids, trigger, start, timeout_seconds, priority, active, states, created_at, updated_at`
+
+func (r *sqliteFlowRepository) Store(flow domain.Flow) error {
+	jids, trigger, states, err := flowJSON(flow)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`INSERT INTO flows (`+flowColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		flow.ID, flow.Name, flow.SessionID, jids, trigger, flow.Start, flow.TimeoutSeconds, flow.Priority, flow.Active,
+		states, flow.CreatedAt.UTC(), flow.UpdatedAt.UTC())
+
+	return err
+}
+
+func (r *sqliteFlowRepository) Update(flow domain.Flow) error {
+	jids, trigger, states, err := flowJSON(flow)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.db.Exec(`UPDATE flows SET name = ?, session_id = ?, jids = ?, trigger = ?, start = ?, timeout_seconds = ?,
+		priority = ?, active = ?, states = ?, updated_at = ? WHERE id = ?`,
+		flow.Name, flow.SessionID, jids, trigger, flow.Start, flow.TimeoutSeconds, flow.Priority, flow.Active, states,
+		flow.UpdatedAt.UTC(), flow.ID)
+	if err != nil {
+		return err
+	}
+
+	return affectedOne(res, domain.ErrFlowNotFound)
+}
+
+func (r *sqliteFlowRepository) Delete(id string) error {
+	res, err := r.db.Exec(`DELETE FROM flows WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if err = affectedOne(res, domain.ErrFlowNotFound); err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`DELETE FROM flow_conversations WHERE flow_id = ?`, id)
+
+	return err
+}
+
+func (r *sqliteFlowRepository) GetByID(id string) (domain.Flow, error) {
+	flow, err := scanFlow(r.db.QueryRow(`SELECT `+flowColumns+` FROM flows WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return flow, domain.ErrFlowNotFound
+	}
+
+	return flow, err
+}
+
+func (r *sqliteFlowRepository) Fetch() ([]domain.Flow, error) {
+	rows, err := r.db.Query(`SELECT ` + flowColumns + ` FROM flows ORDER BY priority, created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	flows := []domain.Flow{}
+	for rows.Next() {
+		flow, err := scanFlow(rows)
+		if err != nil {
+			return nil, err
+		}
+		flows = append(flows, flow)
+	}
+
+	return flows, rows.Err()
+}
+
+const flowConversationColumns = `id, flow_id, session_id, jid, contact_jid, state, variables, retries, expires_at, created_at, updated_at`
+
+func (r *sqliteFlowRepository) SaveConversation(c domain.FlowConversation) error {
+	variables, err := json.Marshal(c.Variables)
+	if err != nil {
+		return err
+	}
+
+	// Replaces the conversation with the same id, or of the same contact
+	_, err = r.db.Exec(`INSERT OR REPLACE INTO flow_conversations (`+flowConversationColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		c.ID, c.FlowID, c.SessionID, c.Jid, c.ContactJid, c.State, string(variables), c.Retries, utcOrNil(c.ExpiresAt),
+		c.CreatedAt.UTC(), c.UpdatedAt.UTC())
+
+	return err
+}
+
+func (r *sqliteFlowRepository) DeleteConversation(id string) error {
+	res, err := r.db.Exec(`DELETE FROM flow_conversations WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	return affectedOne(res, domain.ErrFlowConversationNotFound)
+}
+
+func (r *sqliteFlowRepository) GetConversation(sessionID, jid, contactJid string) (domain.FlowConversation, error) {
+	c, err := scanFlowConversation(r.db.QueryRow(`SELECT `+flowConversationColumns+` FROM flow_conversations
+		WHERE session_id = ? AND jid = ? AND contact_jid = ?`, sessionID, jid, contactJid))
+	if err == sql.ErrNoRows {
+		return c, domain.ErrFlowConversationNotFound
+	}
+
+	return c, err
+}
+
+func (r *sqliteFlowRepository) FetchConversations(flowID string) ([]domain.FlowConversation, error) {
+	return r.queryConversations(`SELECT `+flowConversationColumns+` FROM flow_conversations WHERE flow_id = ?
+		ORDER BY updated_at DESC`, flowID)
+}
+
+func (r *sqliteFlowRepository) FetchExpiredConversations(now time.Time, limit int) ([]domain.FlowConversation, error) {
+	return r.queryConversations(`SELECT `+flowConversationColumns+` FROM flow_conversations
+		WHERE expires_at <= ? ORDER BY expires_at LIMIT ?`, now.UTC(), limit)
+}
+
+func (r *sqliteFlowRepository) queryConversations(query string, args ...interface{}) ([]domain.FlowConversation, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	conversations := []domain.FlowConversation{}
+	for rows.Next() {
+		c, err := scanFlowConversation(rows)
+		if err != nil {
+			return nil, err
+		}
+		conversations = append(conversations, c)
+	}
+
+	return conversations, rows.Err()
+}
+
+func scanFlow(row rowScanner) (domain.Flow, error) {
+	var flow domain.Flow
+	var jids, trigger, states string
+	err := row.Scan(&flow.ID, &flow.Name, &flow.SessionID, &jids, &trigger, &flow.Start, &flow.TimeoutSeconds, &flow.Priority,
+		&flow.Active, &states, &flow.CreatedAt, &flow.UpdatedAt)
+	if err != nil {
+		return flow, err
+	}
+
+	if err = json.Unmarshal([]byte(jids), &flow.Jids); err != nil {
+		return flow, err
+	}
+	if err = json.Unmarshal([]byte(trigger), &flow.Trigger); err != nil {
+		return flow, err
+	}
+	err = json.Unmarshal([]byte(states), &flow.States)
+
+	return flow, err
+}
+
+func scanFlowConversation(row rowScanner) (domain.FlowConversation, error) {
+	var c domain.FlowConversation
+	var variables string
+	var expiresAt sql.NullTime
+	err := row.Scan(&c.ID, &c.FlowID, &c.SessionID, &c.Jid, &c.ContactJid, &c.State, &variables, &c.Retries, &expiresAt,
+		&c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return c, err
+	}
+
+	if expiresAt.Valid {
+		c.ExpiresAt = &expiresAt.Time
+	}
+	err = json.Unmarshal([]byte(variables), &c.Variables)
+
+	return c, err
+}
+
+func flowJSON(flow domain.Flow) (jids, trigger, states string, err error) {
+	b, err := json.Marshal(flow.Jids)
+	if err != nil {
+		return
+	}
+	jids = string(b)
+
+	b, err = json.Marshal(flow.Trigger)
+	if err != nil {
+		return
+	}
+	trigger = string(b)
+
+	b, err = json.Marshal(flow.States)
+	if err != nil {
+		return
+	}
+	states = string(b)
+
+	return
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"time"
+)
+
+type sqliteIdempotencyRepository struct {
+	db *sql.DB
+}
+
+// NewSqliteIdempotencyRepository stores the responses to the requests with an
+// idempotency key in the idempotency_keys table, the table is created when it
+// does not exist yet.
+func NewSqliteIdempotencyRepository(db *sql.DB) (domain.IdempotencyRepository, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS idempotency_keys (
+		session_id TEXT NOT NULL,
+		key TEXT NOT NULL,
+		request_hash TEXT NOT NULL,
+		status INTEGER NOT NULL,
+		content_type TEXT NOT NULL,
+		body BLOB NOT NULL,
+		message_id TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		expires_at DATETIME NOT NULL,
+		PRIMARY KEY (session_id, key)
+	);
+	CREATE INDEX IF NOT EXISTS idempotency_keys_expires ON idempotency_keys (expires_at)`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqliteIdempotencyRepository{db: db}, nil
+}
+
+func (r *sqliteIdempotencyRepository) Store(record domain.IdempotencyRecord) error {
+	// An expired record of the key is replaced
+	_, err := r.db.Exec(`INSERT OR REPLACE INTO idempotency_keys (session_id, key, request_hash, status, content_type,
+		body, message_id, created_at, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		record.SessionID, record.Key, record.RequestHash, record.Status, record.ContentType, record.Body,
+		record.MessageID, record.CreatedAt.UTC(), record.ExpiresAt.UTC())
+
+	return err
+}
+
+func (r *sqliteIdempotencyRepository) Get(sessionID, key string) (record domain.IdempotencyRecord, err error) {
+	err = r.db.QueryRow(`SELECT session_id, key, request_hash, status, content_type, body, message_id, created_at,
+		expires_at FROM idempotency_keys WHERE session_id = ? AND key = ?`, sessionID, key).
+		Scan(&record.SessionID, &record.Key, &record.RequestHash, &record.Status, &record.ContentType, &record.Body,
+			&record.MessageID, &record.CreatedAt, &record.ExpiresAt)
+	if err == sql.ErrNoRows {
+		err = domain.ErrIdempotencyKeyNotFound
+	}
+
+	return
+}
+
+func (r *sqliteIdempotencyRepository) DeleteExpired(now time.Time) error {
+	_, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE expires_at <= ?`, now.UTC())
+
+	return err
+}
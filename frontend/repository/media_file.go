@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+type fileMediaRepository struct {
+	dir string
+	db  *sql.DB
+}
+
+// NewFileMediaRepository stores the media content under dir, in a file named
+// after the media id in a sub directory named after its first two characters.
+// The metadata is kept in the whatsapp_media table, created when it does not
+// exist yet.
+func NewFileMediaRepository(dir string, db *sql.DB) (domain.WaMediaRepository, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS whatsapp_media (
+		id TEXT PRIMARY KEY,
+		mime_type TEXT NOT NULL,
+		file_name TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		created_at DATETIME NOT NULL
+	)`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fileMediaRepository{dir: dir, db: db}, nil
+}
+
+func (r *fileMediaRepository) Store(media domain.WaMedia, content []byte) error {
+	path := r.path(media.ID)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		err = os.MkdirAll(filepath.Dir(path), 0700)
+		if err != nil {
+			return err
+		}
+
+		// Write to a temporary file first so a crash never leaves a truncated file
+		tmp, err := ioutil.TempFile(filepath.Dir(path), ".tmp-")
+		if err != nil {
+			return err
+		}
+		_, err = tmp.Write(content)
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Rename(tmp.Name(), path)
+		}
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+			return err
+		}
+	}
+
+	_, err := r.db.Exec(`INSERT INTO whatsapp_media (id, mime_type, file_name, size, created_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO NOTHING`,
+		media.ID, media.MimeType, media.FileName, media.Size, media.CreatedAt.UTC())
+
+	return err
+}
+
+func (r *fileMediaRepository) GetByID(id string) (domain.WaMedia, error) {
+	var media domain.WaMedia
+	err := r.db.QueryRow(`SELECT id, mime_type, file_name, size, created_at FROM whatsapp_media WHERE id = ?`, id).
+		Scan(&media.ID, &media.MimeType, &media.FileName, &media.Size, &media.CreatedAt)
+	if err == sql.ErrNoRows {
+		return media, domain.ErrMediaNotFound
+	}
+
+	return media, err
+}
+
+func (r *fileMediaRepository) Open(id string) (io.ReadCloser, error) {
+	f, err := os.Open(r.path(id))
+	if os.IsNotExist(err) {
+		return nil, domain.ErrMediaNotFound
+	}
+
+	return f, err
+}
+
+func (r *fileMediaRepository) path(id string) string {
+	if len(id) < 2 {
+		return filepath.Join(r.dir, id)
+	}
+
+	return filepath.Join(r.dir, id[:2], id)
+}
@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"strings"
+)
+
+type sqliteMessageRepository struct {
+	db *sql.DB
+}
+
+// NewSqliteMessageRepository stores the messages in the whatsapp_messages
+// table, the table is created when it does not exist yet.
+func NewSqliteMessageRepository(db *sql.DB) (domain.WaMessageRepository, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS whatsapp_messages (
+		session_id TEXT NOT NULL,
+		id TEXT NOT NULL,
+		jid TEXT NOT NULL,
+		sender_jid TEXT NOT NULL,
+		push_name TEXT NOT NULL,
+		from_me BOOLEAN NOT NULL,
+		direction TEXT NOT NULL,
+		type TEXT NOT NULL,
+		text TEXT NOT NULL,
+		caption TEXT NOT NULL,
+		mime_type TEXT NOT NULL,
+		file_name TEXT NOT NULL,
+		latitude REAL,
+		longitude REAL,
+		display_name TEXT NOT NULL,
+		vcard TEXT NOT NULL,
+		quoted_message_id TEXT NOT NULL,
+		media_id TEXT NOT NULL DEFAULT '',
+		contact_name TEXT NOT NULL DEFAULT '',
+		chat_name TEXT NOT NULL DEFAULT '',
+		timestamp DATETIME NOT NULL,
+		PRIMARY KEY (session_id, id)
+	);
+	CREATE INDEX IF NOT EXISTS whatsapp_messages_jid ON whatsapp_messages (session_id, jid, timestamp);
+	CREATE INDEX IF NOT EXISTS whatsapp_messages_timestamp ON whatsapp_messages (session_id, timestamp);
+	CREATE TABLE IF NOT EXISTS whatsapp_message_statuses (
+		session_id TEXT NOT NULL,
+		message_id TEXT NOT NULL,
+		jid TEXT NOT NULL,
+		status TEXT NOT NULL,
+		pending_at DATETIME NOT NULL,
+		server_ack_at DATETIME,
+		delivered_at DATETIME,
+		read_at DATETIME,
+		played_at DATETIME,
+		updated_at DATETIME NOT NULL,
+		PRIMARY KEY (session_id, message_id)
+	)`)
+	if err != nil {
+		return nil, err
+	}
+
+	// Added after the table was created
+	for _, column := range []string{"media_id", "contact_name", "chat_name"} {
+		err = addColumn(db, "whatsapp_messages", column, "TEXT NOT NULL DEFAULT ''")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &sqliteMessageRepository{db: db}, nil
+}
+
+const messageColumns = `session_id, id, jid, sender_jid, push_name, from_me, direction, type, text, caption, mime_type, file_name,
+	latitude, longitude, display_name, vcard, quoted_message_id, media_id, contact_name, chat_name, timestamp`
+
+func (r *sqliteMessageRepository) Store(m domain.WaMessage) error {
+	_, err := r.db.Exec(`INSERT INTO whatsapp_messages (`+messageColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(session_id, id) DO NOTHING`,
+		m.SessionID, m.ID, m.Jid, m.SenderJid, m.PushName, m.FromMe, m.Direction, m.Type, m.Text, m.Caption, m.MimeType,
+		m.FileName, m.Latitude, m.Longitude, m.DisplayName, m.Vcard, m.QuotedMessageID, m.MediaID, m.ContactName, m.ChatName,
+		m.Timestamp.UTC())
+
+	return err
+}
+
+func (r *sqliteMessageRepository) GetByID(sessionID, id string) (domain.WaMessage, error) {
+	m, err := scanMessage(r.db.QueryRow(`SELECT `+messageColumns+` FROM whatsapp_messages WHERE session_id = ? AND id = ?`,
+		sessionID, id))
+	if err == sql.ErrNoRows {
+		return m, domain.ErrMessageNotFound
+	}
+
+	return m, err
+}
+
+func (r *sqliteMessageRepository) Fetch(filter domain.WaMessageFilter) ([]domain.WaMessage, int, error) {
+	where := []string{"session_id = ?"}
+	args := []interface{}{filter.SessionID}
+	if filter.Jid != "" {
+		where = append(where, "jid = ?")
+		args = append(args, filter.Jid)
+	}
+	if filter.Direction != "" {
+		where = append(where, "direction = ?")
+		args = append(args, filter.Direction)
+	}
+	if filter.Type != "" {
+		where = append(where, "type = ?")
+		args = append(args, filter.Type)
+	}
+	if filter.From != nil {
+		where = append(where, "timestamp >= ?")
+		args = append(args, filter.From.UTC())
+	}
+	if filter.To != nil {
+		where = append(where, "timestamp <= ?")
+		args = append(args, filter.To.UTC())
+	}
+	if filter.Query != "" {
+		like := "%" + escapeLike(filter.Query) + "%"
+		where = append(where, `(text LIKE ? ESCAPE '\' OR caption LIKE ? ESCAPE '\' OR file_name LIKE ? ESCAPE '\'
+			OR contact_name LIKE ? ESCAPE '\')`)
+		args = append(args, like, like, like, like)
+	}
+	cond := " WHERE " + strings.Join(where, " AND ")
+
+	var total int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM whatsapp_messages`+cond, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, filter.PerPage, (filter.Page-1)*filter.PerPage)
+	rows, err := r.db.Query(`SELECT `+messageColumns+` FROM whatsapp_messages`+cond+`
+		ORDER BY timestamp DESC, id LIMIT ? OFFSET ?`, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	messages := []domain.WaMessage{}
+	for rows.Next() {
+		m, err := scanMessage(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		messages = append(messages, m)
+	}
+
+	return messages, total, rows.Err()
+}
+
+func scanMessage(row rowScanner) (domain.WaMessage, error) {
+	var m domain.WaMessage
+	var latitude, longitude sql.NullFloat64
+	err := row.Scan(&m.SessionID, &m.ID, &m.Jid, &m.SenderJid, &m.PushName, &m.FromMe, &m.Direction, &m.Type, &m.Text,
+		&m.Caption, &m.MimeType, &m.FileName, &latitude, &longitude, &m.DisplayName, &m.Vcard, &m.QuotedMessageID,
+		&m.MediaID, &m.ContactName, &m.ChatName, &m.Timestamp)
+	if err != nil {
+		return m, err
+	}
+
+	if latitude.Valid {
+		m.Latitude = &latitude.Float64
+	}
+	if longitude.Valid {
+		m.Longitude = &longitude.Float64
+	}
+
+	return m, nil
+}
+
+const messageStatusColumns = `session_id, message_id, jid, status, pending_at, server_ack_at, delivered_at, read_at, played_at,
+	updated_at`
+
+func (r *sqliteMessageRepository) StoreStatus(s domain.WaMessageStatus) error {
+	_, err := r.db.Exec(`INSERT INTO whatsapp_message_statuses (`+messageStatusColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(session_id, message_id) DO NOTHING`,
+		s.SessionID, s.MessageID, s.Jid, s.Status, s.PendingAt.UTC(), utcOrNil(s.ServerAckAt), utcOrNil(s.DeliveredAt),
+		utcOrNil(s.ReadAt), utcOrNil(s.PlayedAt), s.UpdatedAt.UTC())
+
+	return err
+}
+
+func (r *sqliteMessageRepository) UpdateStatus(s domain.WaMessageStatus) error {
+	_, err := r.db.Exec(`INSERT INTO whatsapp_message_statuses (`+messageStatusColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(session_id, message_id) DO UPDATE SET
+			status = excluded.status,
+			server_ack_at = excluded.server_ack_at,
+			delivered_at = excluded.delivered_at,
+			read_at = excluded.read_at,
+			played_at = excluded.played_at,
+			updated_at = excluded.updated_at`,
+		s.SessionID, s.MessageID, s.Jid, s.Status, s.PendingAt.UTC(), utcOrNil(s.ServerAckAt), utcOrNil(s.DeliveredAt),
+		utcOrNil(s.ReadAt), utcOrNil(s.PlayedAt), s.UpdatedAt.UTC())
+
+	return err
+}
+
+func (r *sqliteMessageRepository) GetStatus(sessionID, id string) (domain.WaMessageStatus, error) {
+	var s domain.WaMessageStatus
+	var serverAckAt, deliveredAt, readAt, playedAt sql.NullTime
+	err := r.db.QueryRow(`SELECT `+messageStatusColumns+` FROM whatsapp_message_statuses WHERE session_id = ? AND message_id = ?`,
+		sessionID, id).Scan(&s.SessionID, &s.MessageID, &s.Jid, &s.Status, &s.PendingAt, &serverAckAt, &deliveredAt, &readAt,
+		&playedAt, &s.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return s, domain.ErrMessageStatusNotFound
+	}
+	if err != nil {
+		return s, err
+	}
+
+	if serverAckAt.Valid {
+		s.ServerAckAt = &serverAckAt.Time
+	}
+	if deliveredAt.Valid {
+		s.DeliveredAt = &deliveredAt.Time
+	}
+	if readAt.Valid {
+		s.ReadAt = &readAt.Time
+	}
+	if playedAt.Valid {
+		s.PlayedAt = &playedAt.Time
+	}
+
+	return s, nil
+}
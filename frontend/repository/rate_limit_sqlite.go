@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"time"
+)
+
+type sqliteRateLimitRepository struct {
+	db *sql.DB
+}
+
+// NewSqliteRateLimitRepository stores the pairing time and the daily message
+// count of the sessions in the whatsapp_pairings and whatsapp_send_counts
+// tables, the tables are created when they do not exist yet.
+func NewSqliteRateLimitRepository(db *sql.DB) (domain.WaRateLimitRepository, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS whatsapp_pairings (
+		session_id TEXT PRIMARY KEY,
+		paired_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS whatsapp_send_counts (
+		session_id TEXT NOT NULL,
+		day TEXT NOT NULL,
+		count INTEGER NOT NULL,
+		PRIMARY KEY (session_id, day)
+	)`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqliteRateLimitRepository{db: db}, nil
+}
+
+func (r *sqliteRateLimitRepository) PairedAt(sessionID string) (*time.Time, error) {
+	var pairedAt time.Time
+	err := r.db.QueryRow(`SELECT paired_at FROM whatsapp_pairings WHERE session_id = ?`, sessionID).Scan(&pairedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &pairedAt, nil
+}
+
+func (r *sqliteRateLimitRepository) SetPairedAt(sessionID string, at time.Time) error {
+	_, err := r.db.Exec(`INSERT INTO whatsapp_pairings (session_id, paired_at) VALUES (?, ?)
+		ON CONFLICT(session_id) DO UPDATE SET paired_at = excluded.paired_at`, sessionID, at.UTC())
+
+	return err
+}
+
+func (r *sqliteRateLimitRepository) DailyCount(sessionID, day string) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT count FROM whatsapp_send_counts WHERE session_id = ? AND day = ?`,
+		sessionID, day).Scan(&count)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+
+	return count, err
+}
+
+func (r *sqliteRateLimitRepository) IncrementDailyCount(sessionID, day string) error {
+	_, err := r.db.Exec(`INSERT INTO whatsapp_send_counts (session_id, day, count) VALUES (?, ?, 1)
+		ON CONFLICT(session_id, day) DO UPDATE SET count = count + 1`, sessionID, day)
+
+	return err
+}
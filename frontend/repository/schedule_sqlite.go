@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"strings"
+	"time"
+)
+
+type sqliteScheduleRepository struct {
+	db *sql.DB
+}
+
+// NewSqliteScheduleRepository stores the scheduled messages in the
+// whatsapp_schedules table, the table is created when it does not exist yet.
+func NewSqliteScheduleRepository(db *sql.DB) (domain.WaScheduleRepository, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS whatsapp_schedules (
+		id TEXT PRIMARY KEY,
+		session_id TEXT NOT NULL,
+		request TEXT NOT NULL,
+		send_at DATETIME,
+		cron TEXT NOT NULL,
+		timezone TEXT NOT NULL,
+		catch_up TEXT NOT NULL,
+		status TEXT NOT NULL,
+		next_run_at DATETIME,
+		last_run_at DATETIME,
+		runs INTEGER NOT NULL,
+		skipped INTEGER NOT NULL,
+		last_job_id TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS whatsapp_schedules_due ON whatsapp_schedules (status, next_run_at);
+	CREATE INDEX IF NOT EXISTS whatsapp_schedules_session ON whatsapp_schedules (session_id, created_at)`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqliteScheduleRepository{db: db}, nil
+}
+
+const scheduleColumns = `id, session_id, request, send_at, cron, timezone, catch_up, status, next_run_at, last_run_at,
+	runs, skipped, last_job_id, created_at, updated_at`
+
+func (r *sqliteScheduleRepository) Store(s domain.WaSchedule) error {
+	request, err := json.Marshal(s.Request)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`INSERT INTO whatsapp_schedules (`+scheduleColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		s.ID, s.SessionID, string(request), utcOrNil(s.SendAt), s.Cron, s.Timezone, s.CatchUp, s.Status,
+		utcOrNil(s.NextRunAt), utcOrNil(s.LastRunAt), s.Runs, s.Skipped, s.LastJobID, s.CreatedAt.UTC(), s.UpdatedAt.UTC())
+
+	return err
+}
+
+func (r *sqliteScheduleRepository) Update(s domain.WaSchedule) error {
+	res, err := r.db.Exec(`UPDATE whatsapp_schedules SET status = ?, next_run_at = ?, last_run_at = ?, runs = ?,
+		skipped = ?, last_job_id = ?, updated_at = ? WHERE id = ?`,
+		s.Status, utcOrNil(s.NextRunAt), utcOrNil(s.LastRunAt), s.Runs, s.Skipped, s.LastJobID, s.UpdatedAt.UTC(), s.ID)
+	if err != nil {
+		return err
+	}
+
+	return affectedOne(res, domain.ErrScheduleNotFound)
+}
+
+func (r *sqliteScheduleRepository) GetByID(id string) (domain.WaSchedule, error) {
+	s, err := scanSchedule(r.db.QueryRow(`SELECT `+scheduleColumns+` FROM whatsapp_schedules WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return s, domain.ErrScheduleNotFound
+	}
+
+	return s, err
+}
+
+func (r *sqliteScheduleRepository) Fetch(filter domain.WaScheduleFilter) ([]domain.WaSchedule, int, error) {
+	var where []string
+	var args []interface{}
+	if filter.SessionID != "" {
+		where = append(where, "session_id = ?")
+		args = append(args, filter.SessionID)
+	}
+	if filter.Status != "" {
+		where = append(where, "status = ?")
+		args = append(args, filter.Status)
+	}
+	cond := ""
+	if len(where) > 0 {
+		cond = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM whatsapp_schedules`+cond, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, filter.PerPage, (filter.Page-1)*filter.PerPage)
+	schedules, err := r.query(`SELECT `+scheduleColumns+` FROM whatsapp_schedules`+cond+`
+		ORDER BY created_at DESC LIMIT ? OFFSET ?`, args...)
+
+	return schedules, total, err
+}
+
+func (r *sqliteScheduleRepository) FetchDue(now time.Time, limit int) ([]domain.WaSchedule, error) {
+	return r.query(`SELECT `+scheduleColumns+` FROM whatsapp_schedules
+		WHERE status = ? AND next_run_at <= ? ORDER BY next_run_at LIMIT ?`,
+		domain.WaScheduleActive, now.UTC(), limit)
+}
+
+func (r *sqliteScheduleRepository) query(query string, args ...interface{}) ([]domain.WaSchedule, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := []domain.WaSchedule{}
+	for rows.Next() {
+		s, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, s)
+	}
+
+	return schedules, rows.Err()
+}
+
+func scanSchedule(row rowScanner) (domain.WaSchedule, error) {
+	var s domain.WaSchedule
+	var request string
+	var sendAt, nextRunAt, lastRunAt sql.NullTime
+	err := row.Scan(&s.ID, &s.SessionID, &request, &sendAt, &s.Cron, &s.Timezone, &s.CatchUp, &s.Status, &nextRunAt,
+		&lastRunAt, &s.Runs, &s.Skipped, &s.LastJobID, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return s, err
+	}
+
+	if sendAt.Valid {
+		s.SendAt = &sendAt.Time
+	}
+	if nextRunAt.Valid {
+		s.NextRunAt = &nextRunAt.Time
+	}
+	if lastRunAt.Valid {
+		s.LastRunAt = &lastRunAt.Time
+	}
+
+	err = json.Unmarshal([]byte(request), &s.Request)
+
+	return s, err
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"strings"
+	"time"
+)
+
+type sqliteSendQueueRepository struct {
+	db *sql.DB
+}
+
+// NewSqliteSendQueueRepository stores the send queue and its attempts in the
+// whatsapp_send_jobs and whatsapp_send_attempts tables, the tables are
+// created when they do not exist yet.
+func NewSqliteSendQueueRepository(db *sql.DB) (domain.WaSendQueueRepository, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS whatsapp_send_jobs (
+		id TEXT PRIMARY KEY,
+		session_id TEXT NOT NULL,
+		request TEXT NOT NULL,
+		status TEXT NOT NULL,
+		attempts INTEGER NOT NULL,
+		max_attempts INTEGER NOT NULL,
+		message_id TEXT NOT NULL,
+		last_error TEXT NOT NULL,
+		next_attempt_at DATETIME,
+		sent_at DATETIME,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS whatsapp_send_jobs_due ON whatsapp_send_jobs (status, next_attempt_at);
+	CREATE INDEX IF NOT EXISTS whatsapp_send_jobs_session ON whatsapp_send_jobs (session_id, created_at);
+	CREATE TABLE IF NOT EXISTS whatsapp_send_attempts (
+		job_id TEXT NOT NULL,
+		attempt INTEGER NOT NULL,
+		error TEXT NOT NULL,
+		started_at DATETIME NOT NULL,
+		finished_at DATETIME NOT NULL,
+		PRIMARY KEY (job_id, attempt)
+	)`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqliteSendQueueRepository{db: db}, nil
+}
+
+const sendJobColumns = `id, session_id, request, status, attempts, max_attempts, message_id, last_error, next_attempt_at,
+	sent_at, created_at, updated_at`
+
+func (r *sqliteSendQueueRepository) Store(job domain.WaSendJob) error {
+	request, err := json.Marshal(job.Request)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`INSERT INTO whatsapp_send_jobs (`+sendJobColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		job.ID, job.SessionID, string(request), job.Status, job.Attempts, job.MaxAttempts, job.MessageID, job.LastError,
+		utcOrNil(job.NextAttemptAt), utcOrNil(job.SentAt), job.CreatedAt.UTC(), job.UpdatedAt.UTC())
+
+	return err
+}
+
+func (r *sqliteSendQueueRepository) Update(job domain.WaSendJob) error {
+	res, err := r.db.Exec(`UPDATE whatsapp_send_jobs SET status = ?, attempts = ?, max_attempts = ?, message_id = ?,
+		last_error = ?, next_attempt_at = ?, sent_at = ?, updated_at = ? WHERE id = ?`,
+		job.Status, job.Attempts, job.MaxAttempts, job.MessageID, job.LastError, utcOrNil(job.NextAttemptAt),
+		utcOrNil(job.SentAt), job.UpdatedAt.UTC(), job.ID)
+	if err != nil {
+		return err
+	}
+
+	return affectedOne(res, domain.ErrSendJobNotFound)
+}
+
+func (r *sqliteSendQueueRepository) GetByID(id string) (domain.WaSendJob, error) {
+	job, err := scanSendJob(r.db.QueryRow(`SELECT `+sendJobColumns+` FROM whatsapp_send_jobs WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return job, domain.ErrSendJobNotFound
+	}
+
+	return job, err
+}
+
+func (r *sqliteSendQueueRepository) Fetch(filter domain.WaSendJobFilter) ([]domain.WaSendJob, int, error) {
+	var where []string
+	var args []interface{}
+	if filter.SessionID != "" {
+		where = append(where, "session_id = ?")
+		args = append(args, filter.SessionID)
+	}
+	if filter.Status != "" {
+		where = append(where, "status = ?")
+		args = append(args, filter.Status)
+	}
+	cond := ""
+	if len(where) > 0 {
+		cond = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM whatsapp_send_jobs`+cond, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, filter.PerPage, (filter.Page-1)*filter.PerPage)
+	jobs, err := r.query(`SELECT `+sendJobColumns+` FROM whatsapp_send_jobs`+cond+`
+		ORDER BY created_at DESC LIMIT ? OFFSET ?`, args...)
+
+	return jobs, total, err
+}
+
+func (r *sqliteSendQueueRepository) FetchDue(now time.Time, limit int) ([]domain.WaSendJob, error) {
+	return r.query(`SELECT `+sendJobColumns+` FROM whatsapp_send_jobs
+		WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at LIMIT ?`,
+		domain.WaSendJobQueued, now.UTC(), limit)
+}
+
+func (r *sqliteSendQueueRepository) Requeue() error {
+	_, err := r.db.Exec(`UPDATE whatsapp_send_jobs SET status = ?, next_attempt_at = ? WHERE status = ?`,
+		domain.WaSendJobQueued, time.Now().UTC(), domain.WaSendJobSending)
+
+	return err
+}
+
+func (r *sqliteSendQueueRepository) StoreAttempt(a domain.WaSendAttempt) error {
+	_, err := r.db.Exec(`INSERT INTO whatsapp_send_attempts (job_id, attempt, error, started_at, finished_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(job_id, attempt) DO UPDATE SET error = excluded.error, started_at = excluded.started_at,
+			finished_at = excluded.finished_at`,
+		a.JobID, a.Attempt, a.Error, a.StartedAt.UTC(), a.FinishedAt.UTC())
+
+	return err
+}
+
+func (r *sqliteSendQueueRepository) FetchAttempts(jobID string) ([]domain.WaSendAttempt, error) {
+	rows, err := r.db.Query(`SELECT job_id, attempt, error, started_at, finished_at FROM whatsapp_send_attempts
+		WHERE job_id = ? ORDER BY attempt`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attempts := []domain.WaSendAttempt{}
+	for rows.Next() {
+		var a domain.WaSendAttempt
+		err = rows.Scan(&a.JobID, &a.Attempt, &a.Error, &a.StartedAt, &a.FinishedAt)
+		if err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, a)
+	}
+
+	return attempts, rows.Err()
+}
+
+func (r *sqliteSendQueueRepository) query(query string, args ...interface{}) ([]domain.WaSendJob, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []domain.WaSendJob{}
+	for rows.Next() {
+		job, err := scanSendJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+func scanSendJob(row rowScanner) (domain.WaSendJob, error) {
+	var job domain.WaSendJob
+	var request string
+	var nextAttemptAt, sentAt sql.NullTime
+	err := row.Scan(&job.ID, &job.SessionID, &request, &job.Status, &job.Attempts, &job.MaxAttempts, &job.MessageID,
+		&job.LastError, &nextAttemptAt, &sentAt, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return job, err
+	}
+
+	if nextAttemptAt.Valid {
+		job.NextAttemptAt = &nextAttemptAt.Time
+	}
+	if sentAt.Valid {
+		job.SentAt = &sentAt.Time
+	}
+
+	err = json.Unmarshal([]byte(request), &job.Request)
+
+	return job, err
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/gob"
+	"errors"
+	"github.com/Rhymen/go-whatsapp"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"io"
+)
+
+// sealedSessionMagic prefixes the encrypted sessions, anything else is read as a plain gob
+// so sessions saved before the encryption was enabled are still restored.
+var sealedSessionMagic = []byte("WASESS2")
+
+// SessionCipher encrypts the stored sessions with AES-256-GCM.
+type SessionCipher struct {
+	aead cipher.AEAD
+}
+
+// NewSessionCipher creates a cipher from a 32 bytes key.
+func NewSessionCipher(key []byte) (*SessionCipher, error) {
+	if len(key) != 32 {
+		return nil, errors.New("session encryption key must be 32 bytes")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SessionCipher{aead: aead}, nil
+}
+
+// sessionAdditionalData authenticates the magic and the session id, so a sealed session
+// can neither have its header swapped nor be moved to another session id.
+func sessionAdditionalData(sessionID string) []byte {
+	return append(append([]byte{}, sealedSessionMagic...), sessionID...)
+}
+
+func (c *SessionCipher) seal(sessionID string, plain []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := append([]byte{}, sealedSessionMagic...)
+	sealed = append(sealed, nonce...)
+
+	return c.aead.Seal(sealed, nonce, plain, sessionAdditionalData(sessionID)), nil
+}
+
+func (c *SessionCipher) open(sessionID string, sealed []byte) ([]byte, error) {
+	sealed = sealed[len(sealedSessionMagic):]
+	if len(sealed) < c.aead.NonceSize() {
+		return nil, domain.ErrSessionDecrypt
+	}
+
+	nonce, data := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plain, err := c.aead.Open(nil, nonce, data, sessionAdditionalData(sessionID))
+	if err != nil {
+		return nil, domain.ErrSessionDecrypt
+	}
+
+	return plain, nil
+}
+
+// encodeSession encodes the session as gob, sealed for sessionID when c is not nil.
+func encodeSession(sessionID string, session whatsapp.Session, c *SessionCipher) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(session)
+	if err != nil {
+		return nil, err
+	}
+
+	if c == nil {
+		return buf.Bytes(), nil
+	}
+
+	return c.seal(sessionID, buf.Bytes())
+}
+
+func decodeSession(sessionID string, data []byte, c *SessionCipher) (whatsapp.Session, error) {
+	session := whatsapp.Session{}
+
+	if bytes.HasPrefix(data, sealedSessionMagic) {
+		if c == nil {
+			return session, domain.ErrSessionEncrypted
+		}
+
+		var err error
+		data, err = c.open(sessionID, data)
+		if err != nil {
+			return session, err
+		}
+	}
+
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&session)
+
+	return session, err
+}
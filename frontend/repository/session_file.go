@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"github.com/Rhymen/go-whatsapp"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	sessionFilePrefix = "whatsappSession"
+	sessionFileExt    = ".gob"
+)
+
+type fileSessionStore struct {
+	dir    string
+	cipher *SessionCipher
+}
+
+// NewFileSessionStore stores every session as a gob file in dir, sealed with
+// c when it is not nil.
+func NewFileSessionStore(dir string, c *SessionCipher) domain.SessionStore {
+	return &fileSessionStore{dir: dir, cipher: c}
+}
+
+func (f *fileSessionStore) Read(sessionID string) (whatsapp.Session, error) {
+	data, err := ioutil.ReadFile(f.file(sessionID))
+	if os.IsNotExist(err) {
+		return whatsapp.Session{}, domain.ErrSessionNotStored
+	}
+	if err != nil {
+		return whatsapp.Session{}, err
+	}
+
+	return decodeSession(sessionID, data, f.cipher)
+}
+
+func (f *fileSessionStore) Write(sessionID string, session whatsapp.Session) error {
+	data, err := encodeSession(sessionID, session, f.cipher)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated session behind
+	tmp := f.file(sessionID) + ".tmp"
+	err = ioutil.WriteFile(tmp, data, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, f.file(sessionID))
+}
+
+func (f *fileSessionStore) Delete(sessionID string) error {
+	err := os.Remove(f.file(sessionID))
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+func (f *fileSessionStore) List() ([]string, error) {
+	files, err := ioutil.ReadDir(f.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		if sessionID, ok := sessionIDFromFile(file.Name()); ok {
+			ids = append(ids, sessionID)
+		}
+	}
+
+	return ids, nil
+}
+
+// file returns the gob file of a session. The default session keeps the
+// legacy file name so sessions saved by older versions are still restored.
+func (f *fileSessionStore) file(sessionID string) string {
+	if sessionID == domain.DefaultSessionID {
+		return filepath.Join(f.dir, sessionFilePrefix+sessionFileExt)
+	}
+
+	return filepath.Join(f.dir, sessionFilePrefix+"_"+sessionID+sessionFileExt)
+}
+
+// sessionIDFromFile is the reverse of file, ok is false for unrelated files.
+func sessionIDFromFile(name string) (sessionID string, ok bool) {
+	if !strings.HasPrefix(name, sessionFilePrefix) || !strings.HasSuffix(name, sessionFileExt) {
+		return "", false
+	}
+
+	sessionID = strings.TrimSuffix(strings.TrimPrefix(name, sessionFilePrefix), sessionFileExt)
+	if sessionID == "" {
+		return domain.DefaultSessionID, true
+	}
+	if !strings.HasPrefix(sessionID, "_") {
+		return "", false
+	}
+
+	return strings.TrimPrefix(sessionID, "_"), true
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"github.com/Rhymen/go-whatsapp"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/go-redis/redis/v8"
+	"strings"
+)
+
+type redisSessionStore struct {
+	client    *redis.Client
+	keyPrefix string
+	cipher    *SessionCipher
+}
+
+// NewRedisSessionStore stores every session under keyPrefix+sessionID, sealed
+// with c when it is not nil. Any server speaking the redis protocol (Redis,
+// KeyDB, Dragonfly...) can be used.
+func NewRedisSessionStore(client *redis.Client, keyPrefix string, c *SessionCipher) domain.SessionStore {
+	return &redisSessionStore{client: client, keyPrefix: keyPrefix, cipher: c}
+}
+
+func (r *redisSessionStore) Read(sessionID string) (whatsapp.Session, error) {
+	data, err := r.client.Get(context.Background(), r.keyPrefix+sessionID).Bytes()
+	if err == redis.Nil {
+		return whatsapp.Session{}, domain.ErrSessionNotStored
+	}
+	if err != nil {
+		return whatsapp.Session{}, err
+	}
+
+	return decodeSession(sessionID, data, r.cipher)
+}
+
+func (r *redisSessionStore) Write(sessionID string, session whatsapp.Session) error {
+	data, err := encodeSession(sessionID, session, r.cipher)
+	if err != nil {
+		return err
+	}
+
+	return r.client.Set(context.Background(), r.keyPrefix+sessionID, data, 0).Err()
+}
+
+func (r *redisSessionStore) Delete(sessionID string) error {
+	return r.client.Del(context.Background(), r.keyPrefix+sessionID).Err()
+}
+
+func (r *redisSessionStore) List() ([]string, error) {
+	var ids []string
+
+	iter := r.client.Scan(context.Background(), 0, r.keyPrefix+"*", 100).Iterator()
+	for iter.Next(context.Background()) {
+		ids = append(ids, strings.TrimPrefix(iter.Val(), r.keyPrefix))
+	}
+
+	return ids, iter.Err()
+}
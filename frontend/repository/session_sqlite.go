@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/Rhymen/go-whatsapp"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"time"
+)
+
+type sqliteSessionStore struct {
+	db     *sql.DB
+	cipher *SessionCipher
+}
+
+// NewSqliteSessionStore stores the sessions in the whatsapp_sessions table, the
+// table is created when it does not exist yet. Sessions are sealed with c when
+// it is not nil.
+func NewSqliteSessionStore(db *sql.DB, c *SessionCipher) (domain.SessionStore, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS whatsapp_sessions (
+		session_id TEXT PRIMARY KEY,
+		data BLOB NOT NULL,
+		updated_at DATETIME NOT NULL
+	)`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqliteSessionStore{db: db, cipher: c}, nil
+}
+
+func (s *sqliteSessionStore) Read(sessionID string) (whatsapp.Session, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM whatsapp_sessions WHERE session_id = ?`, sessionID).Scan(&data)
+	if err == sql.ErrNoRows {
+		return whatsapp.Session{}, domain.ErrSessionNotStored
+	}
+	if err != nil {
+		return whatsapp.Session{}, err
+	}
+
+	return decodeSession(sessionID, data, s.cipher)
+}
+
+func (s *sqliteSessionStore) Write(sessionID string, session whatsapp.Session) error {
+	data, err := encodeSession(sessionID, session, s.cipher)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`INSERT INTO whatsapp_sessions (session_id, data, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(session_id) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at`,
+		sessionID, data, time.Now().UTC())
+
+	return err
+}
+
+func (s *sqliteSessionStore) Delete(sessionID string) error {
+	_, err := s.db.Exec(`DELETE FROM whatsapp_sessions WHERE session_id = ?`, sessionID)
+
+	return err
+}
+
+func (s *sqliteSessionStore) List() ([]string, error) {
+	rows, err := s.db.Query(`SELECT session_id FROM whatsapp_sessions ORDER BY session_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
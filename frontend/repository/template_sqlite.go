@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+)
+
+type sqliteTemplateRepository struct {
+	db *sql.DB
+}
+
+// NewSqliteTemplateRepository stores the message templates in the
+// message_templates table, the table is created when it does not exist yet.
+func NewSqliteTemplateRepository(db *sql.DB) (domain.TemplateRepository, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS message_templates (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		language TEXT NOT NULL,
+		text TEXT NOT NULL,
+		defaults TEXT NOT NULL,
+		variants TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	)`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqliteTemplateRepository{db: db}, nil
+}
+
+const templateColumns = `id, name, language, text, defaults, variants, created_at, updated_at`
+
+func (r *sqliteTemplateRepository) Store(t domain.Template) error {
+	defaults, variants, err := templateJSON(t)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`INSERT INTO message_templates (`+templateColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.ID, t.Name, t.Language, t.Text, defaults, variants, t.CreatedAt.UTC(), t.UpdatedAt.UTC())
+
+	return err
+}
+
+func (r *sqliteTemplateRepository) Update(t domain.Template) error {
+	defaults, variants, err := templateJSON(t)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.db.Exec(`UPDATE message_templates SET name = ?, language = ?, text = ?, defaults = ?, variants = ?,
+		updated_at = ? WHERE id = ?`,
+		t.Name, t.Language, t.Text, defaults, variants, t.UpdatedAt.UTC(), t.ID)
+	if err != nil {
+		return err
+	}
+
+	return affectedOne(res, domain.ErrTemplateNotFound)
+}
+
+func (r *sqliteTemplateRepository) Delete(id string) error {
+	res, err := r.db.Exec(`DELETE FROM message_templates WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	return affectedOne(res, domain.ErrTemplateNotFound)
+}
+
+func (r *sqliteTemplateRepository) GetByID(id string) (domain.Template, error) {
+	t, err := scanTemplate(r.db.QueryRow(`SELECT `+templateColumns+` FROM message_templates WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return t, domain.ErrTemplateNotFound
+	}
+
+	return t, err
+}
+
+func (r *sqliteTemplateRepository) Fetch() ([]domain.Template, error) {
+	rows, err := r.db.Query(`SELECT ` + templateColumns + ` FROM message_templates ORDER BY name, created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []domain.Template{}
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+
+	return templates, rows.Err()
+}
+
+func scanTemplate(row rowScanner) (domain.Template, error) {
+	var t domain.Template
+	var defaults, variants string
+	err := row.Scan(&t.ID, &t.Name, &t.Language, &t.Text, &defaults, &variants, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return t, err
+	}
+
+	if err = json.Unmarshal([]byte(defaults), &t.Defaults); err != nil {
+		return t, err
+	}
+	err = json.Unmarshal([]byte(variants), &t.Variants)
+
+	return t, err
+}
+
+func templateJSON(t domain.Template) (defaults, variants string, err error) {
+	b, err := json.Marshal(t.Defaults)
+	if err != nil {
+		return
+	}
+	defaults = string(b)
+
+	b, err = json.Marshal(t.Variants)
+	if err != nil {
+		return
+	}
+	variants = string(b)
+
+	return
+}
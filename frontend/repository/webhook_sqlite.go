@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"strings"
+	"time"
+)
+
+type sqliteWebhookRepository struct {
+	db *sql.DB
+}
+
+// NewSqliteWebhookRepository stores the webhooks and their delivery log in the
+// webhooks and webhook_deliveries tables, the tables are created when they do
+// not exist yet.
+func NewSqliteWebhookRepository(db *sql.DB) (domain.WebhookRepository, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS webhooks (
+		id TEXT PRIMARY KEY,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		session_id TEXT NOT NULL,
+		event_types TEXT NOT NULL,
+		jids TEXT NOT NULL,
+		active BOOLEAN NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id TEXT PRIMARY KEY,
+		webhook_id TEXT NOT NULL,
+		event_id TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		status TEXT NOT NULL,
+		attempts INTEGER NOT NULL,
+		response_status INTEGER NOT NULL,
+		last_error TEXT NOT NULL,
+		next_attempt_at DATETIME,
+		delivered_at DATETIME,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+	CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook ON webhook_deliveries (webhook_id, created_at)`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqliteWebhookRepository{db: db}, nil
+}
+
+const webhookColumns = `id, url, secret, session_id, event_types, jids, active, created_at, updated_at`
+
+func (r *sqliteWebhookRepository) Store(webhook domain.Webhook) error {
+	eventTypes, jids, err := webhookFilters(webhook)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`INSERT INTO webhooks (`+webhookColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		webhook.ID, webhook.URL, webhook.Secret, webhook.SessionID, eventTypes, jids, webhook.Active,
+		webhook.CreatedAt.UTC(), webhook.UpdatedAt.UTC())
+
+	return err
+}
+
+func (r *sqliteWebhookRepository) Update(webhook domain.Webhook) error {
+	eventTypes, jids, err := webhookFilters(webhook)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.db.Exec(`UPDATE webhooks SET url = ?, secret = ?, session_id = ?, event_types = ?, jids = ?, active = ?, updated_at = ?
+		WHERE id = ?`,
+		webhook.URL, webhook.Secret, webhook.SessionID, eventTypes, jids, webhook.Active, webhook.UpdatedAt.UTC(), webhook.ID)
+	if err != nil {
+		return err
+	}
+
+	return affectedOne(res, domain.ErrWebhookNotFound)
+}
+
+func (r *sqliteWebhookRepository) Delete(id string) error {
+	res, err := r.db.Exec(`DELETE FROM webhooks WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if err = affectedOne(res, domain.ErrWebhookNotFound); err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`DELETE FROM webhook_deliveries WHERE webhook_id = ?`, id)
+
+	return err
+}
+
+func (r *sqliteWebhookRepository) GetByID(id string) (domain.Webhook, error) {
+	webhook, err := scanWebhook(r.db.QueryRow(`SELECT `+webhookColumns+` FROM webhooks WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return webhook, domain.ErrWebhookNotFound
+	}
+
+	return webhook, err
+}
+
+func (r *sqliteWebhookRepository) Fetch() ([]domain.Webhook, error) {
+	rows, err := r.db.Query(`SELECT ` + webhookColumns + ` FROM webhooks ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []domain.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+const webhookDeliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts, response_status, last_error,
+	next_attempt_at, delivered_at, created_at, updated_at`
+
+func (r *sqliteWebhookRepository) StoreDelivery(d domain.WebhookDelivery) error {
+	_, err := r.db.Exec(`INSERT INTO webhook_deliveries (`+webhookDeliveryColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		d.ID, d.WebhookID, d.EventID, d.EventType, d.Payload, d.Status, d.Attempts, d.ResponseStatus, d.LastError,
+		utcOrNil(d.NextAttemptAt), utcOrNil(d.DeliveredAt), d.CreatedAt.UTC(), d.UpdatedAt.UTC())
+
+	return err
+}
+
+func (r *sqliteWebhookRepository) UpdateDelivery(d domain.WebhookDelivery) error {
+	res, err := r.db.Exec(`UPDATE webhook_deliveries SET status = ?, attempts = ?, response_status = ?, last_error = ?,
+		next_attempt_at = ?, delivered_at = ?, updated_at = ? WHERE id = ?`,
+		d.Status, d.Attempts, d.ResponseStatus, d.LastError,
+		utcOrNil(d.NextAttemptAt), utcOrNil(d.DeliveredAt), d.UpdatedAt.UTC(), d.ID)
+	if err != nil {
+		return err
+	}
+
+	return affectedOne(res, domain.ErrWebhookDeliveryNotFound)
+}
+
+func (r *sqliteWebhookRepository) GetDeliveryByID(id string) (domain.WebhookDelivery, error) {
+	d, err := scanWebhookDelivery(r.db.QueryRow(`SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return d, domain.ErrWebhookDeliveryNotFound
+	}
+
+	return d, err
+}
+
+func (r *sqliteWebhookRepository) FetchDeliveries(filter domain.WebhookDeliveryFilter) ([]domain.WebhookDelivery, int, error) {
+	var where []string
+	var args []interface{}
+	if filter.WebhookID != "" {
+		where = append(where, "webhook_id = ?")
+		args = append(args, filter.WebhookID)
+	}
+	if filter.Status != "" {
+		where = append(where, "status = ?")
+		args = append(args, filter.Status)
+	}
+
+	cond := ""
+	if len(where) > 0 {
+		cond = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM webhook_deliveries`+cond, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, filter.PerPage, (filter.Page-1)*filter.PerPage)
+	deliveries, err := r.queryDeliveries(`SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries`+cond+`
+		ORDER BY created_at DESC LIMIT ? OFFSET ?`, args...)
+
+	return deliveries, total, err
+}
+
+func (r *sqliteWebhookRepository) FetchDueDeliveries(now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	return r.queryDeliveries(`SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries
+		WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at LIMIT ?`,
+		domain.WebhookDeliveryPending, now.UTC(), limit)
+}
+
+func (r *sqliteWebhookRepository) queryDeliveries(query string, args ...interface{}) ([]domain.WebhookDelivery, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []domain.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func scanWebhook(row rowScanner) (domain.Webhook, error) {
+	var webhook domain.Webhook
+	var eventTypes, jids string
+	err := row.Scan(&webhook.ID, &webhook.URL, &webhook.Secret, &webhook.SessionID, &eventTypes, &jids, &webhook.Active,
+		&webhook.CreatedAt, &webhook.UpdatedAt)
+	if err != nil {
+		return webhook, err
+	}
+
+	if err = json.Unmarshal([]byte(eventTypes), &webhook.EventTypes); err != nil {
+		return webhook, err
+	}
+	err = json.Unmarshal([]byte(jids), &webhook.Jids)
+
+	return webhook, err
+}
+
+func scanWebhookDelivery(row rowScanner) (domain.WebhookDelivery, error) {
+	var d domain.WebhookDelivery
+	var nextAttemptAt, deliveredAt sql.NullTime
+	err := row.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts, &d.ResponseStatus,
+		&d.LastError, &nextAttemptAt, &deliveredAt, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		return d, err
+	}
+
+	if nextAttemptAt.Valid {
+		d.NextAttemptAt = &nextAttemptAt.Time
+	}
+	if deliveredAt.Valid {
+		d.DeliveredAt = &deliveredAt.Time
+	}
+
+	return d, nil
+}
+
+func webhookFilters(webhook domain.Webhook) (eventTypes, jids string, err error) {
+	b, err := json.Marshal(webhook.EventTypes)
+	if err != nil {
+		return
+	}
+	eventTypes = string(b)
+
+	b, err = json.Marshal(webhook.Jids)
+	if err != nil {
+		return
+	}
+	jids = string(b)
+
+	return
+}
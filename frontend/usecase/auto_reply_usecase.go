@@ -0,0 +1,405 @@
+package usecase
+
+import (
+	"fmt"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils"
+	"github.com/cooljar/go-whatsapp-fiber/utils/log"
+	"io/ioutil"
+	"sync"
+	"time"
+)
+
+// autoReplyCooldownsSize is the number of cooldowns kept before the expired
+// ones are dropped.
+const autoReplyCooldownsSize = 1024
+
+// autoReplyRule is a rule ready to be matched
+type autoReplyRule struct {
+	domain.AutoReplyRule
+	matcher  textMatcher
+	location *time.Location
+}
+
+type autoReplyUsecase struct {
+	repo     domain.AutoReplyRepository
+	sessions domain.WhatsappSessionManager
+	media    domain.WaMediaUsecase
+
+	// mu guards rules, matched against every received text message
+	mu    sync.RWMutex
+	rules []autoReplyRule
+
+	// cooldownMu guards cooldowns, the end of the cooldown by rule and contact
+	cooldownMu sync.Mutex
+	cooldowns  map[string]time.Time
+}
+
+// NewAutoReplyUsecase answers the received text messages with the rules of
+// repo, sent by the sessions of sessions. media holds the media of the media
+// responses.
+func NewAutoReplyUsecase(repo domain.AutoReplyRepository, sessions domain.WhatsappSessionManager, media domain.WaMediaUsecase) (domain.AutoReplyUsecase, error) {
+	u := &autoReplyUsecase{
+		repo:      repo,
+		sessions:  sessions,
+		media:     media,
+		cooldowns: make(map[string]time.Time),
+	}
+
+	err := u.reload()
+	if err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+func (u *autoReplyUsecase) Create(form domain.AutoReplyRuleForm) (rule domain.AutoReplyRule, err error) {
+	now := time.Now()
+	rule = domain.AutoReplyRule{
+		ID:        utils.NewID(),
+		Active:    true,
+		CreatedAt: now,
+	}
+
+	err = u.applyForm(&rule, form, now)
+	if err != nil {
+		return
+	}
+
+	err = u.repo.Store(rule)
+	if err != nil {
+		return
+	}
+
+	err = u.reload()
+
+	return
+}
+
+func (u *autoReplyUsecase) Update(id string, form domain.AutoReplyRuleForm) (rule domain.AutoReplyRule, err error) {
+	rule, err = u.repo.GetByID(id)
+	if err != nil {
+		return
+	}
+
+	err = u.applyForm(&rule, form, time.Now())
+	if err != nil {
+		return
+	}
+
+	err = u.repo.Update(rule)
+	if err != nil {
+		return
+	}
+
+	err = u.reload()
+
+	return
+}
+
+func (u *autoReplyUsecase) Delete(id string) error {
+	err := u.repo.Delete(id)
+	if err != nil {
+		return err
+	}
+
+	return u.reload()
+}
+
+func (u *autoReplyUsecase) Get(id string) (domain.AutoReplyRule, error) {
+	return u.repo.GetByID(id)
+}
+
+func (u *autoReplyUsecase) Fetch() ([]domain.AutoReplyRule, error) {
+	return u.repo.Fetch()
+}
+
+func (u *autoReplyUsecase) HandleEvent(event domain.WaEvent) {
+	if event.Type != domain.WaEventText {
+		return
+	}
+	message, ok := event.Data.(domain.WaMessage)
+	if !ok || message.FromMe || message.Jid == "status@broadcast" {
+		return
+	}
+
+	contact := messageContact(message)
+	now := time.Now()
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	for _, rule := range u.rules {
+		if !rule.match(message, now) {
+			continue
+		}
+		if !u.startCooldown(rule, contact, now) {
+			// The first matching rule answers, even when cooling down
+			return
+		}
+
+		// Sending waits for whatsapp, never block the event bus
+		go u.reply(rule.AutoReplyRule, message)
+		return
+	}
+}
+
+// match reports whether the rule answers message at now.
+func (r autoReplyRule) match(message domain.WaMessage, now time.Time) bool {
+	if !r.Active {
+		return false
+	}
+	if r.SessionID != "" && r.SessionID != message.SessionID {
+		return false
+	}
+
+	if !matchJids(r.Jids, message) {
+		return false
+	}
+	if !r.inWindow(now.In(r.location)) {
+		return false
+	}
+
+	return r.matcher.matches(message.Text)
+}
+
+// inWindow reports whether the local time t is in the days and time window
+// of the rule.
+func (r autoReplyRule) inWindow(t time.Time) bool {
+	if len(r.Days) > 0 {
+		found := false
+		for _, day := range r.Days {
+			if time.Weekday(day) == t.Weekday() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if r.TimeStart == "" && r.TimeEnd == "" {
+		return true
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	start, _ := parseClock(r.TimeStart, 0)
+	end, _ := parseClock(r.TimeEnd, 24*60)
+	if start <= end {
+		return minute >= start && minute < end
+	}
+
+	// The window spans midnight
+	return minute >= start || minute < end
+}
+
+// startCooldown reports whether the rule may answer contact, and if so starts
+// its cooldown.
+func (u *autoReplyUsecase) startCooldown(rule autoReplyRule, contact string, now time.Time) bool {
+	if rule.CooldownSeconds <= 0 {
+		return true
+	}
+
+	key := rule.ID + "|" + contact
+
+	u.cooldownMu.Lock()
+	defer u.cooldownMu.Unlock()
+
+	if until, ok := u.cooldowns[key]; ok && now.Before(until) {
+		return false
+	}
+
+	if len(u.cooldowns) >= autoReplyCooldownsSize {
+		for k, until := range u.cooldowns {
+			if !now.Before(until) {
+				delete(u.cooldowns, k)
+			}
+		}
+	}
+	u.cooldowns[key] = now.Add(time.Duration(rule.CooldownSeconds) * time.Second)
+
+	return true
+}
+
+// reply sends the response of rule to the chat of message.
+func (u *autoReplyUsecase) reply(rule domain.AutoReplyRule, message domain.WaMessage) {
+	err := u.send(rule, message)
+	if err != nil {
+		log.Println(log.LogLevelError, "auto-reply", message.SessionID+": rule "+rule.ID+": "+err.Error())
+	}
+}
+
+func (u *autoReplyUsecase) send(rule domain.AutoReplyRule, message domain.WaMessage) error {
+	wa, err := u.sessions.Get(message.SessionID)
+	if err != nil {
+		return err
+	}
+
+	response := rule.Response
+	var quotedID, quoted string
+	if response.Quote {
+		quotedID, quoted = message.ID, message.Text
+	}
+
+	switch response.Type {
+	case domain.AutoReplyText:
+		_, err = wa.SendText(domain.WaSendTextForm{
+			Msisdn:      message.Jid,
+			Text:        response.Text,
+			MsgQuotedID: quotedID,
+			MsgQuoted:   quoted,
+		})
+	case domain.AutoReplyLocation:
+		_, err = wa.SendLocation(domain.WaSendLocationForm{
+			Msisdn:      message.Jid,
+			Latitude:    response.Latitude,
+			Longitude:   response.Longitude,
+			MsgQuotedID: quotedID,
+			MsgQuoted:   quoted,
+		})
+	default:
+		media, content, err := u.media.Get(response.MediaID)
+		if err != nil {
+			return err
+		}
+		b, err := ioutil.ReadAll(content)
+		content.Close()
+		if err != nil {
+			return err
+		}
+
+		fileHeader, err := utils.NewFileHeader(media.FileName, media.MimeType, b)
+		if err != nil {
+			return err
+		}
+
+		_, err = wa.SendFile(domain.WaSendFileForm{
+			Msisdn:      message.Jid,
+			MsgQuotedID: quotedID,
+			MsgQuoted:   quoted,
+			Message:     response.Text,
+			FileHeader:  fileHeader,
+		}, string(response.Type))
+		return err
+	}
+
+	return err
+}
+
+// reload caches the rules, compiled, by priority.
+func (u *autoReplyUsecase) reload() error {
+	rules, err := u.repo.Fetch()
+	if err != nil {
+		return err
+	}
+
+	compiled := make([]autoReplyRule, 0, len(rules))
+	for _, rule := range rules {
+		c, err := compileAutoReplyRule(rule)
+		if err != nil {
+			// Validated when saved, only a timezone missing on this host can fail
+			log.Println(log.LogLevelWarn, "auto-reply", "rule "+rule.ID+" disabled: "+err.Error())
+			continue
+		}
+		compiled = append(compiled, c)
+	}
+
+	u.mu.Lock()
+	u.rules = compiled
+	u.mu.Unlock()
+
+	return nil
+}
+
+func (u *autoReplyUsecase) applyForm(rule *domain.AutoReplyRule, form domain.AutoReplyRuleForm, now time.Time) error {
+	rule.Name = form.Name
+	rule.SessionID = form.SessionID
+	rule.Match = form.Match
+	rule.Pattern = form.Pattern
+	rule.CaseSensitive = form.CaseSensitive
+	rule.TimeStart = form.TimeStart
+	rule.TimeEnd = form.TimeEnd
+	rule.Days = form.Days
+	rule.Timezone = form.Timezone
+	rule.CooldownSeconds = form.CooldownSeconds
+	rule.Priority = form.Priority
+	rule.Response = form.Response
+	rule.UpdatedAt = now
+	if form.Active != nil {
+		rule.Active = *form.Active
+	}
+
+	// The rules compare full jids, accept msisdn too
+	rule.Jids = normalizeJids(form.Jids)
+	if rule.Days == nil {
+		rule.Days = []int{}
+	}
+
+	if _, err := compileAutoReplyRule(*rule); err != nil {
+		return err
+	}
+
+	response := rule.Response
+	switch response.Type {
+	case domain.AutoReplyText:
+		if response.Text == "" {
+			return fmt.Errorf("%w: text response without text", domain.ErrInvalidAutoReplyRule)
+		}
+	case domain.AutoReplyLocation:
+		if response.Latitude < -90 || response.Latitude > 90 || response.Longitude < -180 || response.Longitude > 180 {
+			return fmt.Errorf("%w: invalid location", domain.ErrInvalidAutoReplyRule)
+		}
+	default:
+		_, content, err := u.media.Get(response.MediaID)
+		if err == domain.ErrMediaNotFound {
+			return fmt.Errorf("%w: media %q not found", domain.ErrInvalidAutoReplyRule, response.MediaID)
+		}
+		if err != nil {
+			return err
+		}
+		content.Close()
+	}
+
+	return nil
+}
+
+func compileAutoReplyRule(rule domain.AutoReplyRule) (c autoReplyRule, err error) {
+	c.AutoReplyRule = rule
+
+	c.matcher, err = newTextMatcher(rule.Match, rule.Pattern, rule.CaseSensitive)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", domain.ErrInvalidAutoReplyRule, err.Error())
+		return
+	}
+
+	if _, ok := parseClock(rule.TimeStart, 0); !ok {
+		err = fmt.Errorf("%w: invalid time_start %q, expected HH:MM", domain.ErrInvalidAutoReplyRule, rule.TimeStart)
+		return
+	}
+	if _, ok := parseClock(rule.TimeEnd, 0); !ok {
+		err = fmt.Errorf("%w: invalid time_end %q, expected HH:MM", domain.ErrInvalidAutoReplyRule, rule.TimeEnd)
+		return
+	}
+
+	c.location, err = time.LoadLocation(rule.Timezone)
+	if err != nil {
+		err = fmt.Errorf("%w: invalid timezone %q", domain.ErrInvalidAutoReplyRule, rule.Timezone)
+	}
+
+	return
+}
+
+// parseClock returns the minutes since midnight of a "HH:MM" time, or def
+// when it is empty.
+func parseClock(clock string, def int) (int, bool) {
+	if clock == "" {
+		return def, true
+	}
+
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, false
+	}
+
+	return t.Hour()*60 + t.Minute(), true
+}
@@ -0,0 +1,626 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils"
+	"github.com/cooljar/go-whatsapp-fiber/utils/log"
+	"io"
+	"mime/multipart"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	campaignPollPeriod  = 5 * time.Second
+	campaignErrorLength = 512
+)
+
+type campaignUsecase struct {
+	repo          domain.CampaignRepository
+	sessions      domain.WhatsappSessionManager
+	media         domain.WaMediaUsecase
+	interval      time.Duration
+	maxRecipients int
+
+	// mu serializes the status changes of the campaigns
+	mu sync.Mutex
+
+	// Used by the worker only: the campaign sent last, when, and the
+	// campaigns waiting for the rate limiter
+	lastID   string
+	lastSent time.Time
+	retryAt  map[string]time.Time
+
+	wake chan struct{}
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+// NewCampaignUsecase creates the campaigns, their messages are sent by the
+// sessions of sessions one every CAMPAIGN_SEND_INTERVAL_MS milliseconds
+// (default to 2000), on top of the session rate limits. A campaign has at
+// most CAMPAIGN_MAX_RECIPIENTS recipients, default to 10000.
+func NewCampaignUsecase(repo domain.CampaignRepository, sessions domain.WhatsappSessionManager, media domain.WaMediaUsecase) domain.CampaignUsecase {
+	return &campaignUsecase{
+		repo:          repo,
+		sessions:      sessions,
+		media:         media,
+		interval:      time.Duration(utils.GetEnvInt("CAMPAIGN_SEND_INTERVAL_MS", 2000)) * time.Millisecond,
+		maxRecipients: utils.GetEnvInt("CAMPAIGN_MAX_RECIPIENTS", 10000),
+		retryAt:       make(map[string]time.Time),
+		wake:          make(chan struct{}, 1),
+		stop:          make(chan struct{}),
+	}
+}
+
+func (u *campaignUsecase) Create(sessionID string, form domain.CampaignForm, recipients io.Reader, file *multipart.FileHeader) (c domain.Campaign, err error) {
+	now := time.Now()
+	c = domain.Campaign{
+		ID:          utils.NewID(),
+		SessionID:   sessionID,
+		Name:        form.Name,
+		Type:        form.Type,
+		Text:        form.Text,
+		MediaID:     form.MediaID,
+		Status:      domain.CampaignRunning,
+		ScheduledAt: form.ScheduledAt,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if !validSendType(c.Type) || c.Type == "location" {
+		err = fmt.Errorf("%w: type must be text, image, document, audio or video", domain.ErrInvalidCampaign)
+		return
+	}
+	if c.Type == "text" && strings.TrimSpace(c.Text) == "" {
+		err = fmt.Errorf("%w: a text campaign needs a text", domain.ErrInvalidCampaign)
+		return
+	}
+
+	rows, err := u.readRecipients(c.ID, c.Text, recipients, now)
+	if err != nil {
+		return
+	}
+
+	if c.Type != "text" {
+		switch {
+		case file != nil:
+			var media domain.WaMedia
+			media, err = saveUpload(u.media, file)
+			if err != nil {
+				return
+			}
+			c.MediaID = media.ID
+		case c.MediaID != "":
+			var content io.ReadCloser
+			_, content, err = u.media.Get(c.MediaID)
+			if err != nil {
+				return
+			}
+			content.Close()
+		default:
+			err = fmt.Errorf("%w: a %s campaign needs a file or a media_id", domain.ErrInvalidCampaign, c.Type)
+			return
+		}
+	}
+
+	if c.ScheduledAt != nil && c.ScheduledAt.After(now) {
+		c.Status = domain.CampaignScheduled
+	} else {
+		c.StartedAt = &now
+	}
+
+	err = u.repo.Store(c, rows)
+	if err != nil {
+		return
+	}
+
+	c.Progress = domain.CampaignProgress{Total: len(rows), Queued: len(rows)}
+	u.notify()
+
+	return
+}
+
+// readRecipients reads the CSV recipient list, the header names the columns.
+// Every column is a variable of the recipient, msisdn is required.
+func (u *campaignUsecase) readRecipients(campaignID, text string, r io.Reader, now time.Time) ([]domain.CampaignRecipient, error) {
+	if r == nil {
+		return nil, fmt.Errorf("%w: recipients CSV is required", domain.ErrInvalidCampaign)
+	}
+
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: recipients CSV is empty", domain.ErrInvalidCampaign)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: recipients: %s", domain.ErrInvalidCampaign, err.Error())
+	}
+
+	msisdnColumn := -1
+	columns := make(map[string]bool, len(header))
+	for i, name := range header {
+		// Spreadsheets export the CSV with a byte order mark
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		header[i] = name
+		columns[name] = true
+
+		if strings.EqualFold(name, "msisdn") {
+			msisdnColumn = i
+		}
+	}
+	if msisdnColumn < 0 {
+		return nil, fmt.Errorf("%w: recipients CSV has no msisdn column", domain.ErrInvalidCampaign)
+	}
+
+	for _, name := range placeholders(text) {
+		if !columns[name] {
+			return nil, fmt.Errorf("%w: variable %q is not a column of the recipients CSV", domain.ErrInvalidCampaign, name)
+		}
+	}
+
+	recipients := []domain.CampaignRecipient{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: recipients: %s", domain.ErrInvalidCampaign, err.Error())
+		}
+
+		row := len(recipients) + 1
+		if row > u.maxRecipients {
+			return nil, fmt.Errorf("%w: more than %d recipients", domain.ErrInvalidCampaign, u.maxRecipients)
+		}
+
+		msisdn := strings.TrimSpace(record[msisdnColumn])
+		if msisdn == "" {
+			return nil, fmt.Errorf("%w: recipient row %d has no msisdn", domain.ErrInvalidCampaign, row)
+		}
+
+		variables := make(map[string]string, len(header))
+		for i, name := range header {
+			variables[name] = record[i]
+		}
+
+		recipients = append(recipients, domain.CampaignRecipient{
+			CampaignID: campaignID,
+			Row:        row,
+			Msisdn:     msisdn,
+			Variables:  variables,
+			Status:     domain.CampaignRecipientQueued,
+			UpdatedAt:  now,
+		})
+	}
+
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("%w: recipients CSV has no recipient", domain.ErrInvalidCampaign)
+	}
+
+	return recipients, nil
+}
+
+func (u *campaignUsecase) Get(sessionID, id string) (c domain.Campaign, err error) {
+	c, err = u.get(sessionID, id)
+	if err != nil {
+		return
+	}
+
+	c.Progress, err = u.repo.Progress(c.ID)
+
+	return
+}
+
+func (u *campaignUsecase) Fetch(sessionID string, page, perPage int) (campaigns []domain.Campaign, meta domain.JSONResultMeta, err error) {
+	page, perPage = normalizePage(page, perPage)
+
+	campaigns, total, err := u.repo.Fetch(sessionID, page, perPage)
+	if err != nil {
+		return
+	}
+
+	for i := range campaigns {
+		campaigns[i].Progress, err = u.repo.Progress(campaigns[i].ID)
+		if err != nil {
+			return
+		}
+	}
+
+	meta = newResultMeta(total, page, perPage)
+
+	return
+}
+
+func (u *campaignUsecase) Recipients(sessionID string, filter domain.CampaignRecipientFilter) (recipients []domain.CampaignRecipient, meta domain.JSONResultMeta, err error) {
+	_, err = u.get(sessionID, filter.CampaignID)
+	if err != nil {
+		return
+	}
+
+	filter.Page, filter.PerPage = normalizePage(filter.Page, filter.PerPage)
+
+	recipients, total, err := u.repo.FetchRecipients(filter)
+	if err != nil {
+		return
+	}
+
+	meta = newResultMeta(total, filter.Page, filter.PerPage)
+
+	return
+}
+
+func (u *campaignUsecase) Report(sessionID, id string, w io.Writer) error {
+	_, err := u.get(sessionID, id)
+	if err != nil {
+		return err
+	}
+
+	recipients, _, err := u.repo.FetchRecipients(domain.CampaignRecipientFilter{CampaignID: id})
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	err = writer.Write([]string{"row", "msisdn", "status", "message_id", "error", "sent_at"})
+	if err != nil {
+		return err
+	}
+
+	for _, recipient := range recipients {
+		sentAt := ""
+		if recipient.SentAt != nil {
+			sentAt = recipient.SentAt.Format(time.RFC3339)
+		}
+
+		err = writer.Write([]string{strconv.Itoa(recipient.Row), recipient.Msisdn, string(recipient.Status),
+			recipient.MessageID, recipient.Error, sentAt})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+func (u *campaignUsecase) Pause(sessionID, id string) (domain.Campaign, error) {
+	return u.transition(sessionID, id, func(c *domain.Campaign, now time.Time) error {
+		if c.Status != domain.CampaignRunning && c.Status != domain.CampaignScheduled {
+			return domain.ErrCampaignState
+		}
+		c.Status = domain.CampaignPaused
+
+		return nil
+	})
+}
+
+func (u *campaignUsecase) Resume(sessionID, id string) (domain.Campaign, error) {
+	return u.transition(sessionID, id, func(c *domain.Campaign, now time.Time) error {
+		if c.Status != domain.CampaignPaused {
+			return domain.ErrCampaignState
+		}
+
+		if c.ScheduledAt != nil && c.ScheduledAt.After(now) {
+			c.Status = domain.CampaignScheduled
+			return nil
+		}
+
+		c.Status = domain.CampaignRunning
+		if c.StartedAt == nil {
+			c.StartedAt = &now
+		}
+
+		return nil
+	})
+}
+
+func (u *campaignUsecase) Cancel(sessionID, id string) (domain.Campaign, error) {
+	return u.transition(sessionID, id, func(c *domain.Campaign, now time.Time) error {
+		if c.Status == domain.CampaignCompleted || c.Status == domain.CampaignCancelled {
+			return domain.ErrCampaignState
+		}
+
+		err := u.repo.CancelRecipients(c.ID)
+		if err != nil {
+			return err
+		}
+
+		c.Status = domain.CampaignCancelled
+		c.CompletedAt = &now
+
+		return nil
+	})
+}
+
+// transition changes the status of the campaign id of the session with
+// change and stores it.
+func (u *campaignUsecase) transition(sessionID, id string, change func(c *domain.Campaign, now time.Time) error) (c domain.Campaign, err error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	c, err = u.get(sessionID, id)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	err = change(&c, now)
+	if err != nil {
+		return
+	}
+	c.UpdatedAt = now
+
+	err = u.repo.Update(c)
+	if err != nil {
+		return
+	}
+
+	c.Progress, err = u.repo.Progress(c.ID)
+	u.notify()
+
+	return
+}
+
+func (u *campaignUsecase) Start() {
+	err := u.repo.FailSendingRecipients("interrupted while sending, the message may have been sent")
+	if err != nil {
+		log.Println(log.LogLevelError, "campaign", err.Error())
+	}
+
+	u.done.Add(1)
+	go func() {
+		defer u.done.Done()
+
+		var delay time.Duration
+		for {
+			timer := time.NewTimer(delay)
+			select {
+			case <-u.stop:
+				timer.Stop()
+				return
+			case <-u.wake:
+				timer.Stop()
+			case <-timer.C:
+			}
+
+			delay = u.sendNext()
+		}
+	}()
+}
+
+func (u *campaignUsecase) Shutdown(ctx context.Context) error {
+	close(u.stop)
+
+	done := make(chan struct{})
+	go func() {
+		u.done.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// get returns the campaign id of the session.
+func (u *campaignUsecase) get(sessionID, id string) (domain.Campaign, error) {
+	c, err := u.repo.GetByID(id)
+	if err != nil {
+		return c, err
+	}
+	if c.SessionID != sessionID {
+		return c, domain.ErrCampaignNotFound
+	}
+
+	return c, nil
+}
+
+// sendNext sends the message of the next recipient of the running campaigns,
+// taking the campaigns in turn, and returns how long to wait before the next
+// one.
+func (u *campaignUsecase) sendNext() time.Duration {
+	if wait := time.Until(u.lastSent.Add(u.interval)); wait > 0 {
+		return wait
+	}
+
+	u.startScheduled()
+
+	campaigns, err := u.repo.FetchByStatus(domain.CampaignRunning)
+	if err != nil {
+		log.Println(log.LogLevelError, "campaign", err.Error())
+		return campaignPollPeriod
+	}
+
+	start := 0
+	for i, c := range campaigns {
+		if c.ID == u.lastID {
+			start = i + 1
+			break
+		}
+	}
+
+	now := time.Now()
+	for i := range campaigns {
+		c := campaigns[(start+i)%len(campaigns)]
+
+		if retryAt, ok := u.retryAt[c.ID]; ok {
+			if now.Before(retryAt) {
+				continue
+			}
+			delete(u.retryAt, c.ID)
+		}
+
+		sent, err := u.sendTo(c)
+		if err != nil {
+			log.Println(log.LogLevelError, "campaign", c.ID+": "+err.Error())
+		}
+		if sent {
+			u.lastID = c.ID
+			u.lastSent = time.Now()
+			return u.interval
+		}
+	}
+
+	return campaignPollPeriod
+}
+
+// sendTo sends the message of the next recipient of c, it reports whether a
+// message was sent or failed.
+func (u *campaignUsecase) sendTo(c domain.Campaign) (bool, error) {
+	wa, err := u.sessions.Get(c.SessionID)
+	if err != nil {
+		u.retryAt[c.ID] = time.Now().Add(time.Minute)
+		return false, err
+	}
+
+	// The campaign waits for its session to be connected
+	if wa.ConnectionStatus().State != domain.WaStateConnected {
+		return false, nil
+	}
+
+	recipient, ok, err := u.claimNext(c.ID)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	// u.mu is not held while sending, the wait for a rate limiter slot and a
+	// media upload must not block the other campaigns
+	recipient.MessageID, err = sendRequest(wa, u.media, domain.WaSendRequest{
+		Type:    c.Type,
+		Msisdn:  recipient.Msisdn,
+		Text:    renderVariables(c.Text, recipient.Variables),
+		MediaID: c.MediaID,
+	})
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	now := time.Now()
+	recipient.UpdatedAt = now
+
+	var limited *domain.WaRateLimitError
+	if errors.As(err, &limited) || errors.Is(err, domain.ErrShuttingDown) {
+		if limited != nil {
+			u.retryAt[c.ID] = now.Add(limited.RetryAfter)
+		}
+		return false, u.unclaim(recipient)
+	}
+
+	if err != nil {
+		recipient.Status = domain.CampaignRecipientFailed
+		recipient.Error = err.Error()
+		if len(recipient.Error) > campaignErrorLength {
+			recipient.Error = recipient.Error[:campaignErrorLength]
+		}
+	} else {
+		recipient.Status = domain.CampaignRecipientSent
+		recipient.SentAt = &now
+	}
+
+	_, err = u.repo.UpdateRecipient(recipient, domain.CampaignRecipientSending)
+
+	return true, err
+}
+
+// claimNext marks the next recipient of the campaign id sending, while the
+// campaign is running. A pause or cancel from then on leaves the recipient to
+// the message being sent. The campaign is completed when no recipient is left.
+func (u *campaignUsecase) claimNext(id string) (recipient domain.CampaignRecipient, ok bool, err error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	c, err := u.repo.GetByID(id)
+	if err != nil || c.Status != domain.CampaignRunning {
+		return
+	}
+
+	recipient, ok, err = u.repo.NextRecipient(c.ID)
+	if err != nil {
+		return
+	}
+	if !ok {
+		err = u.complete(c)
+		return
+	}
+
+	recipient.Status = domain.CampaignRecipientSending
+	recipient.UpdatedAt = time.Now()
+	ok, err = u.repo.UpdateRecipient(recipient, domain.CampaignRecipientQueued)
+
+	return
+}
+
+// unclaim queues again the recipient whose message was not sent, it is
+// cancelled when its campaign was cancelled meanwhile. u.mu must be held.
+func (u *campaignUsecase) unclaim(recipient domain.CampaignRecipient) error {
+	c, err := u.repo.GetByID(recipient.CampaignID)
+	if err != nil {
+		return err
+	}
+
+	recipient.Status = domain.CampaignRecipientQueued
+	if c.Status == domain.CampaignCancelled {
+		recipient.Status = domain.CampaignRecipientCancelled
+	}
+	_, err = u.repo.UpdateRecipient(recipient, domain.CampaignRecipientSending)
+
+	return err
+}
+
+// startScheduled starts the scheduled campaigns which are due.
+func (u *campaignUsecase) startScheduled() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	campaigns, err := u.repo.FetchByStatus(domain.CampaignScheduled)
+	if err != nil {
+		log.Println(log.LogLevelError, "campaign", err.Error())
+		return
+	}
+
+	now := time.Now()
+	for _, c := range campaigns {
+		if c.ScheduledAt != nil && c.ScheduledAt.After(now) {
+			continue
+		}
+
+		c.Status = domain.CampaignRunning
+		c.StartedAt = &now
+		c.UpdatedAt = now
+
+		err = u.repo.Update(c)
+		if err != nil {
+			log.Println(log.LogLevelError, "campaign", c.ID+": "+err.Error())
+		}
+	}
+}
+
+// complete marks the running campaign c completed, u.mu must be held.
+func (u *campaignUsecase) complete(c domain.Campaign) error {
+	now := time.Now()
+	c.Status = domain.CampaignCompleted
+	c.CompletedAt = &now
+	c.UpdatedAt = now
+
+	return u.repo.Update(c)
+}
+
+func (u *campaignUsecase) notify() {
+	select {
+	case u.wake <- struct{}{}:
+	default:
+	}
+}
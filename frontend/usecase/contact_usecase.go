@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"time"
+)
+
+type contactUsecase struct {
+	repo domain.WaContactRepository
+}
+
+func NewContactUsecase(repo domain.WaContactRepository) domain.WaContactUsecase {
+	return &contactUsecase{repo: repo}
+}
+
+func (u *contactUsecase) Fetch(filter domain.WaContactFilter) (contacts []domain.WaContact, meta domain.JSONResultMeta, err error) {
+	filter.Page, filter.PerPage = normalizePage(filter.Page, filter.PerPage)
+
+	contacts, total, err := u.repo.Fetch(filter)
+	if err != nil {
+		return
+	}
+
+	meta = newResultMeta(total, filter.Page, filter.PerPage)
+
+	return
+}
+
+func (u *contactUsecase) Get(sessionID, jid string) (domain.WaContact, error) {
+	return u.repo.GetByJid(sessionID, parseMsisdn(jid))
+}
+
+func (u *contactUsecase) Save(contacts []domain.WaContact) error {
+	now := time.Now()
+	for _, contact := range contacts {
+		if contact.Jid == "" {
+			continue
+		}
+		if contact.UpdatedAt.IsZero() {
+			contact.UpdatedAt = now
+		}
+
+		err := u.repo.Save(contact)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (u *contactUsecase) DisplayName(sessionID, jid string) string {
+	contact, err := u.repo.GetByJid(sessionID, jid)
+	if err != nil {
+		return ""
+	}
+
+	return contact.ResolveDisplayName()
+}
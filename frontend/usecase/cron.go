@@ -0,0 +1,234 @@
+package usecase
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSearchYears is how far next looks for a matching time, a cron
+// expression without any, such as "0 0 30 2 *", never runs.
+const cronSearchYears = 5
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	cronMonthNames = map[string]int{"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6, "jul": 7, "aug": 8,
+		"sep": 9, "oct": 10, "nov": 11, "dec": 12}
+	cronDayNames = map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}
+)
+
+// cronSchedule is a standard 5 fields cron expression: minute, hour, day of
+// month, month and day of week. The fields are bit sets of the values they
+// match.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny are set for a "*" day field, when both day fields
+	// are restricted a day matching either of them matches.
+	domAny, dowAny bool
+}
+
+// parseCron parses a cron expression: "*", values, ranges ("1-5"), steps
+// ("*/15", "0-30/10") and lists of them separated by commas, the month and
+// day of week names ("JAN", "MON") and the @daily like macros. 7 is Sunday
+// too.
+func parseCron(expr string) (s cronSchedule, err error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := cronMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		err = fmt.Errorf("cron expression %q must have 5 fields: minute, hour, day of month, month and day of week", expr)
+		return
+	}
+
+	if s.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return
+	}
+	if s.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return
+	}
+	if s.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return
+	}
+	if s.month, err = parseCronField(fields[3], 1, 12, cronMonthNames); err != nil {
+		return
+	}
+	if s.dow, err = parseCronField(fields[4], 0, 7, cronDayNames); err != nil {
+		return
+	}
+
+	// 7 is Sunday
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+
+	s.domAny = fields[2] == "*" || fields[2] == "?"
+	s.dowAny = fields[4] == "*" || fields[4] == "?"
+
+	return
+}
+
+func parseCronField(field string, min, max int, names map[string]int) (bits uint64, err error) {
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			rangePart = part[:i]
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+		}
+
+		var start, end int
+		switch {
+		case rangePart == "*" || rangePart == "?":
+			start, end = min, max
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			if start, err = cronValue(bounds[0], names); err != nil {
+				return
+			}
+			if end, err = cronValue(bounds[1], names); err != nil {
+				return
+			}
+		default:
+			if start, err = cronValue(rangePart, names); err != nil {
+				return
+			}
+			end = start
+			if step > 1 {
+				// "5/15" is "5-max/15"
+				end = max
+			}
+		}
+
+		if start < min || end > max || start > end {
+			return 0, fmt.Errorf("%q is out of the %d-%d range", part, min, max)
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return
+}
+
+func cronValue(s string, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+
+	return v, nil
+}
+
+// next returns the first time matching the schedule after t, in the location
+// of t, or the zero time when there is none. The schedule is matched against
+// the wall clock: a time skipped by a daylight saving change runs as much
+// after the change (02:30 runs at 03:30) and a time repeated by one runs once.
+func (s cronSchedule) next(t time.Time) time.Time {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+	for {
+		wall = s.nextWall(wall)
+		if wall.IsZero() {
+			return wall
+		}
+
+		next := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), 0, 0, t.Location())
+		if next.Hour() != wall.Hour() || next.Minute() != wall.Minute() {
+			// Skipped, read with the offset in effect before the change
+			_, offset := next.Add(-12 * time.Hour).Zone()
+			next = wall.Add(-time.Duration(offset) * time.Second).In(t.Location())
+		}
+		// A repeated wall time already passed the first time around
+		if next.After(t) {
+			return next
+		}
+	}
+}
+
+// nextWall returns the first wall clock time matching the schedule after the
+// minute of t, a UTC time, or the zero time when there is none.
+func (s cronSchedule) nextWall(t time.Time) time.Time {
+	loc := time.UTC
+	t = t.Add(time.Minute)
+	yearLimit := t.Year() + cronSearchYears
+
+	// Each field is moved forward until it matches, a field wrapping around
+	// starts over from the month.
+	added := false
+wrap:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for s.month&(1<<uint(t.Month())) == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+		}
+		t = t.AddDate(0, 1, 0)
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+
+	for !s.dayMatches(t) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		}
+		t = t.AddDate(0, 0, 1)
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+
+	for s.hour&(1<<uint(t.Hour())) == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+		}
+		t = t.Add(time.Hour)
+		if t.Hour() == 0 {
+			goto wrap
+		}
+	}
+
+	for s.minute&(1<<uint(t.Minute())) == 0 {
+		added = true
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+
+	return t
+}
+
+func (s cronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+
+	return dom || dow
+}
@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{expr: "* * * * *"},
+		{expr: "*/15 9-17 * * MON-FRI"},
+		{expr: "0 0 1 jan,JUL *"},
+		{expr: "0,30 * ? * *"},
+		{expr: "0 0 * * 7"},
+		{expr: "@daily"},
+		{expr: " @Weekly "},
+		{expr: "", wantErr: true},
+		{expr: "* * * *", wantErr: true},
+		{expr: "* * * * * *", wantErr: true},
+		{expr: "60 * * * *", wantErr: true},
+		{expr: "* 24 * * *", wantErr: true},
+		{expr: "* * 0 * *", wantErr: true},
+		{expr: "* * 32 * *", wantErr: true},
+		{expr: "* * * 13 *", wantErr: true},
+		{expr: "* * * * 8", wantErr: true},
+		{expr: "*/0 * * * *", wantErr: true},
+		{expr: "*/x * * * *", wantErr: true},
+		{expr: "5-1 * * * *", wantErr: true},
+		{expr: "a * * * *", wantErr: true},
+		{expr: "* * * FOO *", wantErr: true},
+		{expr: "@sometimes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		_, err := parseCron(tt.expr)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseCron(%q) error = %v, want error %v", tt.expr, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseCronField(t *testing.T) {
+	tests := []struct {
+		field    string
+		min, max int
+		names    map[string]int
+		want     []int
+	}{
+		{field: "5", min: 0, max: 59, want: []int{5}},
+		{field: "1,3,5", min: 0, max: 59, want: []int{1, 3, 5}},
+		{field: "10-13", min: 0, max: 59, want: []int{10, 11, 12, 13}},
+		{field: "0-30/10", min: 0, max: 59, want: []int{0, 10, 20, 30}},
+		{field: "5/15", min: 0, max: 59, want: []int{5, 20, 35, 50}},
+		{field: "*/6", min: 0, max: 23, want: []int{0, 6, 12, 18}},
+		{field: "*", min: 1, max: 12, names: cronMonthNames, want: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}},
+		{field: "feb-Apr", min: 1, max: 12, names: cronMonthNames, want: []int{2, 3, 4}},
+		{field: "MON-WED,sat", min: 0, max: 7, names: cronDayNames, want: []int{1, 2, 3, 6}},
+	}
+
+	for _, tt := range tests {
+		got, err := parseCronField(tt.field, tt.min, tt.max, tt.names)
+		if err != nil {
+			t.Errorf("parseCronField(%q) error: %v", tt.field, err)
+			continue
+		}
+
+		var want uint64
+		for _, v := range tt.want {
+			want |= 1 << uint(v)
+		}
+		if got != want {
+			t.Errorf("parseCronField(%q) = %b, want %b", tt.field, got, want)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	utc := func(year int, month time.Month, day, hour, min, sec int) time.Time {
+		return time.Date(year, month, day, hour, min, sec, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{name: "step", expr: "*/15 * * * *", from: utc(2021, 6, 1, 10, 7, 30), want: utc(2021, 6, 1, 10, 15, 0)},
+		{name: "strictly after", expr: "@hourly", from: utc(2021, 6, 1, 10, 0, 0), want: utc(2021, 6, 1, 11, 0, 0)},
+		{name: "day of week", expr: "0 9 * * MON", from: utc(2021, 6, 1, 10, 0, 0), want: utc(2021, 6, 7, 9, 0, 0)},
+		{name: "sunday as 7", expr: "0 0 * * 7", from: utc(2021, 6, 1, 0, 0, 0), want: utc(2021, 6, 6, 0, 0, 0)},
+		{name: "year wrap", expr: "0 12 1 1 *", from: utc(2021, 6, 1, 0, 0, 0), want: utc(2022, 1, 1, 12, 0, 0)},
+		{name: "last minute of the year", expr: "59 23 31 12 *", from: utc(2021, 12, 31, 23, 59, 0), want: utc(2022, 12, 31, 23, 59, 0)},
+		{name: "month without the day", expr: "0 0 31 * *", from: utc(2021, 4, 1, 0, 0, 0), want: utc(2021, 5, 31, 0, 0, 0)},
+		{name: "february 29", expr: "0 0 29 2 *", from: utc(2021, 3, 1, 0, 0, 0), want: utc(2024, 2, 29, 0, 0, 0)},
+		{name: "never", expr: "0 0 30 2 *", from: utc(2021, 3, 1, 0, 0, 0)},
+		{name: "day of month or week, the week day", expr: "0 0 13 * FRI", from: utc(2021, 6, 1, 0, 0, 0), want: utc(2021, 6, 4, 0, 0, 0)},
+		{name: "day of month or week, the month day", expr: "0 0 13 * FRI", from: utc(2021, 6, 12, 0, 0, 0), want: utc(2021, 6, 13, 0, 0, 0)},
+		{name: "day of month and any week day", expr: "0 0 13 * *", from: utc(2021, 6, 1, 0, 0, 0), want: utc(2021, 6, 13, 0, 0, 0)},
+		{name: "in the location", expr: "0 9 * * *", from: time.Date(2021, 6, 1, 10, 0, 0, 0, newYork), want: time.Date(2021, 6, 2, 9, 0, 0, 0, newYork)},
+		{
+			name: "skipped by daylight saving, runs after it",
+			expr: "30 2 * * *",
+			from: time.Date(2021, 3, 14, 0, 0, 0, 0, newYork),
+			want: utc(2021, 3, 14, 7, 30, 0), // 03:30 EDT
+		},
+		{
+			name: "repeated by daylight saving, before it",
+			expr: "30 1 * * *",
+			from: time.Date(2021, 11, 7, 0, 50, 0, 0, newYork),
+			want: utc(2021, 11, 7, 5, 30, 0), // 01:30 EDT
+		},
+		{
+			name: "repeated by daylight saving, runs once",
+			expr: "30 1 * * *",
+			from: utc(2021, 11, 7, 5, 45, 0).In(newYork), // 01:45 EDT
+			want: time.Date(2021, 11, 8, 1, 30, 0, 0, newYork),
+		},
+		{
+			name: "repeated by daylight saving, during the second hour",
+			expr: "50 1 * * *",
+			from: utc(2021, 11, 7, 6, 45, 0).In(newYork), // 01:45 EST
+			want: time.Date(2021, 11, 8, 1, 50, 0, 0, newYork),
+		},
+		{
+			name: "hourly over the repeated hour",
+			expr: "0 * * * *",
+			from: utc(2021, 11, 7, 6, 30, 0).In(newYork), // 01:30 EST
+			want: utc(2021, 11, 7, 7, 0, 0),              // 02:00 EST
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := parseCron(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := s.next(tt.from)
+			if !got.Equal(tt.want) {
+				t.Fatalf("next(%s) = %s, want %s", tt.from, got, tt.want)
+			}
+			if !got.IsZero() && got.Location() != tt.from.Location() {
+				t.Errorf("next(%s) is in %s, want %s", tt.from, got.Location(), tt.from.Location())
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"sync"
+)
+
+type waEventBus struct {
+	mu          sync.RWMutex
+	nextID      int
+	subscribers map[int]func(event domain.WaEvent)
+}
+
+// NewWaEventBus creates an in process event bus.
+func NewWaEventBus() domain.WaEventBus {
+	return &waEventBus{subscribers: make(map[int]func(event domain.WaEvent))}
+}
+
+func (b *waEventBus) Publish(event domain.WaEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, fn := range b.subscribers {
+		fn(event)
+	}
+}
+
+func (b *waEventBus) Subscribe(fn func(event domain.WaEvent)) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = fn
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		delete(b.subscribers, id)
+	}
+}
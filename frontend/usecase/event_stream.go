@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"sync"
+)
+
+// eventStreamSubscriberBuffer is how many events a subscriber may lag behind
+// before it is dropped.
+const eventStreamSubscriberBuffer = 256
+
+type waEventStream struct {
+	mu     sync.Mutex
+	buffer []domain.WaEvent
+	size   int
+	next   int
+
+	subscribers map[chan domain.WaEvent]struct{}
+}
+
+// NewWaEventStream keeps the last size events so the streams can resume.
+func NewWaEventStream(size int) domain.WaEventStream {
+	if size < 1 {
+		size = 1
+	}
+
+	return &waEventStream{
+		size:        size,
+		subscribers: make(map[chan domain.WaEvent]struct{}),
+	}
+}
+
+func (s *waEventStream) HandleEvent(event domain.WaEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.buffer) < s.size {
+		s.buffer = append(s.buffer, event)
+	} else {
+		s.buffer[s.next] = event
+		s.next = (s.next + 1) % s.size
+	}
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// A slow client must not hold the sessions back, it resumes from
+			// its last event id when it reconnects.
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+func (s *waEventStream) Subscribe(lastEventID string) (backlog []domain.WaEvent, events <-chan domain.WaEvent, cancel func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if lastEventID != "" {
+		// The buffer from the oldest to the newest event
+		ordered := append(append([]domain.WaEvent{}, s.buffer[s.next:]...), s.buffer[:s.next]...)
+		for i, event := range ordered {
+			if event.ID == lastEventID {
+				backlog = ordered[i+1:]
+				break
+			}
+		}
+	}
+
+	ch := make(chan domain.WaEvent, eventStreamSubscriberBuffer)
+	s.subscribers[ch] = struct{}{}
+
+	cancel = func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if _, ok := s.subscribers[ch]; ok {
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+
+	return backlog, ch, cancel
+}
@@ -0,0 +1,558 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils"
+	"github.com/cooljar/go-whatsapp-fiber/utils/log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	flowTimeoutPeriod = time.Second
+	flowTimeoutBatch  = 100
+	flowRepliesSize   = 256
+)
+
+// flowState is a state ready to be matched
+type flowState struct {
+	domain.FlowState
+	validate *regexp.Regexp
+	branches []textMatcher
+}
+
+// end reports whether the conversation ends once the state prompt is sent.
+func (s flowState) end() bool {
+	return len(s.Branches) == 0 && s.Next == ""
+}
+
+// compiledFlow is a flow ready to be matched
+type compiledFlow struct {
+	domain.Flow
+	trigger textMatcher
+	states  map[string]flowState
+}
+
+// flowReply is a prompt waiting to be sent
+type flowReply struct {
+	sessionID string
+	form      domain.WaSendTextForm
+}
+
+type flowUsecase struct {
+	repo     domain.FlowRepository
+	sessions domain.WhatsappSessionManager
+
+	// mu guards flows, the active flows by priority
+	mu    sync.RWMutex
+	flows []compiledFlow
+
+	// conversationMu serializes the conversation changes, the messages of a
+	// contact are handled in order
+	conversationMu sync.Mutex
+
+	replies chan flowReply
+	stop    chan struct{}
+	done    sync.WaitGroup
+}
+
+// NewFlowUsecase drives the conversations of the flows of repo with the
+// received text messages, the prompts are sent by the sessions of sessions.
+func NewFlowUsecase(repo domain.FlowRepository, sessions domain.WhatsappSessionManager) (domain.FlowUsecase, error) {
+	u := &flowUsecase{
+		repo:     repo,
+		sessions: sessions,
+		replies:  make(chan flowReply, flowRepliesSize),
+		stop:     make(chan struct{}),
+	}
+
+	err := u.reload()
+	if err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+func (u *flowUsecase) Create(form domain.FlowForm) (flow domain.Flow, err error) {
+	now := time.Now()
+	flow = domain.Flow{
+		ID:        utils.NewID(),
+		Active:    true,
+		CreatedAt: now,
+	}
+
+	err = applyFlowForm(&flow, form, now)
+	if err != nil {
+		return
+	}
+
+	err = u.repo.Store(flow)
+	if err != nil {
+		return
+	}
+
+	err = u.reload()
+
+	return
+}
+
+func (u *flowUsecase) Update(id string, form domain.FlowForm) (flow domain.Flow, err error) {
+	flow, err = u.repo.GetByID(id)
+	if err != nil {
+		return
+	}
+
+	err = applyFlowForm(&flow, form, time.Now())
+	if err != nil {
+		return
+	}
+
+	err = u.repo.Update(flow)
+	if err != nil {
+		return
+	}
+
+	err = u.reload()
+
+	return
+}
+
+func (u *flowUsecase) Delete(id string) error {
+	err := u.repo.Delete(id)
+	if err != nil {
+		return err
+	}
+
+	return u.reload()
+}
+
+func (u *flowUsecase) Get(id string) (domain.Flow, error) {
+	return u.repo.GetByID(id)
+}
+
+func (u *flowUsecase) Fetch() ([]domain.Flow, error) {
+	return u.repo.Fetch()
+}
+
+func (u *flowUsecase) Conversations(flowID string) ([]domain.FlowConversation, error) {
+	_, err := u.repo.GetByID(flowID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.repo.FetchConversations(flowID)
+}
+
+func (u *flowUsecase) EndConversation(id string) error {
+	u.conversationMu.Lock()
+	defer u.conversationMu.Unlock()
+
+	return u.repo.DeleteConversation(id)
+}
+
+func (u *flowUsecase) HandleEvent(event domain.WaEvent) bool {
+	if event.Type != domain.WaEventText {
+		return false
+	}
+	message, ok := event.Data.(domain.WaMessage)
+	if !ok || message.FromMe || message.Jid == "status@broadcast" {
+		return false
+	}
+
+	u.conversationMu.Lock()
+	defer u.conversationMu.Unlock()
+
+	conversation, err := u.repo.GetConversation(message.SessionID, message.Jid, messageContact(message))
+	if err == domain.ErrFlowConversationNotFound {
+		return u.trigger(message)
+	}
+	if err != nil {
+		log.Println(log.LogLevelError, "flow", message.SessionID+": "+err.Error())
+		return false
+	}
+
+	flow, state, ok := u.state(conversation)
+	if !ok {
+		// The flow was deactivated or its state removed since
+		u.end(conversation)
+		return u.trigger(message)
+	}
+
+	u.answer(flow, state, conversation, message)
+
+	return true
+}
+
+func (u *flowUsecase) Start() {
+	u.done.Add(1)
+	go func() {
+		defer u.done.Done()
+
+		for {
+			select {
+			case <-u.stop:
+				return
+			case reply := <-u.replies:
+				u.send(reply)
+			}
+		}
+	}()
+
+	u.done.Add(1)
+	go func() {
+		defer u.done.Done()
+
+		ticker := time.NewTicker(flowTimeoutPeriod)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-u.stop:
+				return
+			case <-ticker.C:
+				u.expire()
+			}
+		}
+	}()
+}
+
+func (u *flowUsecase) Shutdown(ctx context.Context) error {
+	close(u.stop)
+
+	done := make(chan struct{})
+	go func() {
+		u.done.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// trigger starts the first flow triggered by message, it reports whether one
+// was.
+func (u *flowUsecase) trigger(message domain.WaMessage) bool {
+	u.mu.RLock()
+	var flow *compiledFlow
+	for i := range u.flows {
+		f := &u.flows[i]
+		if f.SessionID != "" && f.SessionID != message.SessionID {
+			continue
+		}
+		if matchJids(f.Jids, message) && f.trigger.matches(message.Text) {
+			flow = f
+			break
+		}
+	}
+	u.mu.RUnlock()
+
+	if flow == nil {
+		return false
+	}
+
+	now := time.Now()
+	conversation := domain.FlowConversation{
+		ID:         utils.NewID(),
+		FlowID:     flow.ID,
+		SessionID:  message.SessionID,
+		Jid:        message.Jid,
+		ContactJid: messageContact(message),
+		Variables:  map[string]string{},
+		CreatedAt:  now,
+	}
+	if message.PushName != "" {
+		conversation.Variables["push_name"] = message.PushName
+	}
+
+	u.enter(*flow, flow.Start, conversation, &message)
+
+	return true
+}
+
+// answer moves the conversation with the answer in message.
+func (u *flowUsecase) answer(flow compiledFlow, state flowState, conversation domain.FlowConversation, message domain.WaMessage) {
+	text := strings.TrimSpace(message.Text)
+
+	next := ""
+	valid := state.validate == nil || state.validate.MatchString(text)
+	if valid {
+		for i, branch := range state.branches {
+			if branch.matches(text) {
+				next = state.Branches[i].Next
+				break
+			}
+		}
+		if next == "" {
+			next = state.Next
+		}
+		valid = next != ""
+	}
+
+	if !valid {
+		conversation.Retries++
+		if state.MaxRetries > 0 && conversation.Retries >= state.MaxRetries {
+			u.end(conversation)
+			return
+		}
+
+		reply := state.Error
+		if reply == "" {
+			reply = state.Prompt
+		}
+		u.reply(conversation, reply, &message)
+		u.save(flow, state, conversation)
+		return
+	}
+
+	if state.Save != "" {
+		conversation.Variables[state.Save] = text
+	}
+
+	u.enter(flow, next, conversation, &message)
+}
+
+// enter moves the conversation to the state name and sends its prompt,
+// quoting message when the state quotes and there is one.
+func (u *flowUsecase) enter(flow compiledFlow, name string, conversation domain.FlowConversation, message *domain.WaMessage) {
+	state := flow.states[name]
+	conversation.State = name
+	conversation.Retries = 0
+
+	if !state.Quote {
+		message = nil
+	}
+	u.reply(conversation, state.Prompt, message)
+
+	if state.end() {
+		u.end(conversation)
+		return
+	}
+
+	u.save(flow, state, conversation)
+}
+
+// save stores the conversation, waiting for the answer to state.
+func (u *flowUsecase) save(flow compiledFlow, state flowState, conversation domain.FlowConversation) {
+	now := time.Now()
+	conversation.UpdatedAt = now
+	conversation.ExpiresAt = nil
+
+	timeout := state.TimeoutSeconds
+	if timeout == 0 {
+		timeout = flow.TimeoutSeconds
+	}
+	if timeout > 0 {
+		expiresAt := now.Add(time.Duration(timeout) * time.Second)
+		conversation.ExpiresAt = &expiresAt
+	}
+
+	err := u.repo.SaveConversation(conversation)
+	if err != nil {
+		log.Println(log.LogLevelError, "flow", conversation.SessionID+": "+err.Error())
+	}
+}
+
+func (u *flowUsecase) end(conversation domain.FlowConversation) {
+	err := u.repo.DeleteConversation(conversation.ID)
+	if err != nil && err != domain.ErrFlowConversationNotFound {
+		log.Println(log.LogLevelError, "flow", conversation.SessionID+": "+err.Error())
+	}
+}
+
+// expire moves the conversations not answered in time to the timeout state
+// of their state, or ends them.
+func (u *flowUsecase) expire() {
+	u.conversationMu.Lock()
+	defer u.conversationMu.Unlock()
+
+	conversations, err := u.repo.FetchExpiredConversations(time.Now(), flowTimeoutBatch)
+	if err != nil {
+		log.Println(log.LogLevelError, "flow", err.Error())
+		return
+	}
+
+	for _, conversation := range conversations {
+		flow, state, ok := u.state(conversation)
+		if !ok || state.TimeoutNext == "" {
+			u.end(conversation)
+			continue
+		}
+
+		u.enter(flow, state.TimeoutNext, conversation, nil)
+	}
+}
+
+// state returns the active flow of the conversation and its current state.
+func (u *flowUsecase) state(conversation domain.FlowConversation) (flow compiledFlow, state flowState, ok bool) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	for _, f := range u.flows {
+		if f.ID == conversation.FlowID {
+			state, ok = f.states[conversation.State]
+			return f, state, ok
+		}
+	}
+
+	return
+}
+
+// reply queues the text to the chat of the conversation, {{name}} is replaced
+// with the conversation variables.
+func (u *flowUsecase) reply(conversation domain.FlowConversation, text string, quoted *domain.WaMessage) {
+	if text == "" {
+		return
+	}
+
+	names := make([]string, 0, len(conversation.Variables))
+	for name := range conversation.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	oldnew := make([]string, 0, 2*len(names))
+	for _, name := range names {
+		oldnew = append(oldnew, "{{"+name+"}}", conversation.Variables[name])
+	}
+
+	reply := flowReply{
+		sessionID: conversation.SessionID,
+		form: domain.WaSendTextForm{
+			Msisdn: conversation.Jid,
+			Text:   strings.NewReplacer(oldnew...).Replace(text),
+		},
+	}
+	if quoted != nil {
+		reply.form.MsgQuotedID = quoted.ID
+		reply.form.MsgQuoted = quoted.Text
+	}
+
+	// Sending waits for whatsapp, never block the event bus
+	select {
+	case u.replies <- reply:
+	default:
+		log.Println(log.LogLevelWarn, "flow", conversation.SessionID+": reply dropped, too many replies waiting")
+	}
+}
+
+func (u *flowUsecase) send(reply flowReply) {
+	wa, err := u.sessions.Get(reply.sessionID)
+	if err == nil {
+		_, err = wa.SendText(reply.form)
+	}
+	if err != nil {
+		log.Println(log.LogLevelError, "flow", reply.sessionID+": "+err.Error())
+	}
+}
+
+// reload caches the active flows, compiled, by priority.
+func (u *flowUsecase) reload() error {
+	flows, err := u.repo.Fetch()
+	if err != nil {
+		return err
+	}
+
+	compiled := make([]compiledFlow, 0, len(flows))
+	for _, flow := range flows {
+		if !flow.Active {
+			continue
+		}
+
+		c, err := compileFlow(flow)
+		if err != nil {
+			log.Println(log.LogLevelWarn, "flow", "flow "+flow.ID+" disabled: "+err.Error())
+			continue
+		}
+		compiled = append(compiled, c)
+	}
+
+	u.mu.Lock()
+	u.flows = compiled
+	u.mu.Unlock()
+
+	return nil
+}
+
+func applyFlowForm(flow *domain.Flow, form domain.FlowForm, now time.Time) error {
+	flow.Name = form.Name
+	flow.SessionID = form.SessionID
+	flow.Jids = normalizeJids(form.Jids)
+	flow.Trigger = form.Trigger
+	flow.Start = form.Start
+	flow.TimeoutSeconds = form.TimeoutSeconds
+	flow.Priority = form.Priority
+	flow.States = form.States
+	flow.UpdatedAt = now
+	if form.Active != nil {
+		flow.Active = *form.Active
+	}
+
+	_, err := compileFlow(*flow)
+
+	return err
+}
+
+func compileFlow(flow domain.Flow) (c compiledFlow, err error) {
+	c.Flow = flow
+
+	if flow.Trigger.Pattern == "" {
+		return c, fmt.Errorf("%w: trigger without pattern", domain.ErrInvalidFlow)
+	}
+	c.trigger, err = newTextMatcher(flow.Trigger.Match, flow.Trigger.Pattern, flow.Trigger.CaseSensitive)
+	if err != nil {
+		return c, fmt.Errorf("%w: trigger: %s", domain.ErrInvalidFlow, err.Error())
+	}
+
+	if _, ok := flow.States[flow.Start]; !ok {
+		return c, fmt.Errorf("%w: start state %q not found", domain.ErrInvalidFlow, flow.Start)
+	}
+
+	c.states = make(map[string]flowState, len(flow.States))
+	for name, state := range flow.States {
+		s := flowState{FlowState: state}
+		invalid := func(format string, a ...interface{}) error {
+			return fmt.Errorf("%w: state %q: %s", domain.ErrInvalidFlow, name, fmt.Sprintf(format, a...))
+		}
+
+		if state.Validate != "" {
+			s.validate, err = regexp.Compile(state.Validate)
+			if err != nil {
+				return c, invalid("validate: %s", err.Error())
+			}
+		}
+
+		for i, branch := range state.Branches {
+			matcher, err := newTextMatcher(branch.Match, branch.Pattern, branch.CaseSensitive)
+			if err != nil {
+				return c, invalid("branch %d: %s", i, err.Error())
+			}
+			if _, ok := flow.States[branch.Next]; !ok {
+				return c, invalid("branch %d: next state %q not found", i, branch.Next)
+			}
+			s.branches = append(s.branches, matcher)
+		}
+
+		for _, next := range []string{state.Next, state.TimeoutNext} {
+			if _, ok := flow.States[next]; next != "" && !ok {
+				return c, invalid("state %q not found", next)
+			}
+		}
+		if state.TimeoutSeconds < 0 || state.MaxRetries < 0 {
+			return c, invalid("negative timeout_seconds or max_retries")
+		}
+
+		c.states[name] = s
+	}
+
+	return c, nil
+}
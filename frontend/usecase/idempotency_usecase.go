@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils"
+	"github.com/cooljar/go-whatsapp-fiber/utils/log"
+	"sync"
+	"time"
+)
+
+// idempotencyPurgePeriod is how often the expired keys are deleted
+const idempotencyPurgePeriod = time.Hour
+
+// idempotencyLock serializes the requests with the same key, refs counts the
+// requests holding or waiting for it.
+type idempotencyLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+type idempotencyUsecase struct {
+	repo   domain.IdempotencyRepository
+	window time.Duration
+
+	// locksMu guards locks, by session and key
+	locksMu sync.Mutex
+	locks   map[string]*idempotencyLock
+
+	// purgeMu guards purgedAt, the last time the expired keys were deleted
+	purgeMu  sync.Mutex
+	purgedAt time.Time
+}
+
+// NewIdempotencyUsecase keeps the responses to the requests with an
+// idempotency key in repo for IDEMPOTENCY_KEY_TTL_HOURS hours (default to 24).
+func NewIdempotencyUsecase(repo domain.IdempotencyRepository) domain.IdempotencyUsecase {
+	return &idempotencyUsecase{
+		repo:   repo,
+		window: time.Duration(utils.GetEnvInt("IDEMPOTENCY_KEY_TTL_HOURS", 24)) * time.Hour,
+		locks:  make(map[string]*idempotencyLock),
+	}
+}
+
+func (u *idempotencyUsecase) Lock(sessionID, key string) (unlock func()) {
+	id := sessionID + "|" + key
+
+	u.locksMu.Lock()
+	l, ok := u.locks[id]
+	if !ok {
+		l = &idempotencyLock{}
+		u.locks[id] = l
+	}
+	l.refs++
+	u.locksMu.Unlock()
+
+	l.mu.Lock()
+
+	return func() {
+		l.mu.Unlock()
+
+		u.locksMu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(u.locks, id)
+		}
+		u.locksMu.Unlock()
+	}
+}
+
+func (u *idempotencyUsecase) Get(sessionID, key string) (domain.IdempotencyRecord, error) {
+	record, err := u.repo.Get(sessionID, key)
+	if err != nil {
+		return record, err
+	}
+	if !time.Now().Before(record.ExpiresAt) {
+		return record, domain.ErrIdempotencyKeyNotFound
+	}
+
+	return record, nil
+}
+
+func (u *idempotencyUsecase) Store(record domain.IdempotencyRecord) error {
+	now := time.Now()
+	record.CreatedAt = now
+	record.ExpiresAt = now.Add(u.window)
+
+	err := u.repo.Store(record)
+	if err != nil {
+		return err
+	}
+
+	u.purge(now)
+
+	return nil
+}
+
+// purge deletes the expired keys, at most once per idempotencyPurgePeriod.
+func (u *idempotencyUsecase) purge(now time.Time) {
+	u.purgeMu.Lock()
+	if now.Sub(u.purgedAt) < idempotencyPurgePeriod {
+		u.purgeMu.Unlock()
+		return
+	}
+	u.purgedAt = now
+	u.purgeMu.Unlock()
+
+	err := u.repo.DeleteExpired(now)
+	if err != nil {
+		log.Println(log.LogLevelError, "idempotency", err.Error())
+	}
+}
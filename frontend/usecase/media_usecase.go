@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils"
+	"io"
+	"mime"
+	"strings"
+	"time"
+)
+
+type mediaUsecase struct {
+	repo domain.WaMediaRepository
+}
+
+// NewMediaUsecase stores the media of the received messages. The size limit
+// is MEDIA_MAX_SIZE_MB (default to 64) megabytes, MEDIA_MAX_SIZE_MB_IMAGE,
+// _VIDEO, _AUDIO and _DOCUMENT override it per type. 0 disables the download.
+func NewMediaUsecase(repo domain.WaMediaRepository) domain.WaMediaUsecase {
+	return &mediaUsecase{repo: repo}
+}
+
+func (u *mediaUsecase) Save(mediaType, mimeType, fileName string, declaredSize uint64, download func() ([]byte, error)) (media domain.WaMedia, err error) {
+	limit := mediaSizeLimit(mediaType)
+	if declaredSize > limit {
+		err = fmt.Errorf("%w: %d bytes", domain.ErrMediaTooLarge, declaredSize)
+		return
+	}
+
+	content, err := download()
+	if err != nil {
+		return
+	}
+	if uint64(len(content)) > limit {
+		err = fmt.Errorf("%w: %d bytes", domain.ErrMediaTooLarge, len(content))
+		return
+	}
+
+	sum := sha256.Sum256(content)
+	media = domain.WaMedia{
+		ID:        hex.EncodeToString(sum[:]),
+		MimeType:  mimeType,
+		FileName:  fileName,
+		Size:      int64(len(content)),
+		CreatedAt: time.Now(),
+	}
+	if media.MimeType == "" {
+		media.MimeType = "application/octet-stream"
+	}
+	if media.FileName == "" {
+		media.FileName = mediaType + "-" + media.ID[:12] + mediaExtension(media.MimeType)
+	}
+
+	err = u.repo.Store(media, content)
+
+	return
+}
+
+func (u *mediaUsecase) Get(id string) (media domain.WaMedia, content io.ReadCloser, err error) {
+	media, err = u.repo.GetByID(id)
+	if err != nil {
+		return
+	}
+
+	content, err = u.repo.Open(id)
+
+	return
+}
+
+func mediaSizeLimit(mediaType string) uint64 {
+	mb := utils.GetEnvInt("MEDIA_MAX_SIZE_MB", 64)
+	mb = utils.GetEnvInt("MEDIA_MAX_SIZE_MB_"+strings.ToUpper(mediaType), mb)
+	if mb < 0 {
+		mb = 0
+	}
+
+	return uint64(mb) << 20
+}
+
+// mediaExtension returns the usual extension of the mime type, eg: ".jpg".
+func mediaExtension(mimeType string) string {
+	mediaType, _, _ := mime.ParseMediaType(mimeType)
+	switch mediaType {
+	case "image/jpeg":
+		return ".jpg"
+	case "audio/ogg":
+		return ".ogg"
+	case "video/mp4":
+		return ".mp4"
+	}
+
+	extensions, err := mime.ExtensionsByType(mediaType)
+	if err != nil || len(extensions) == 0 {
+		return ""
+	}
+
+	return extensions[0]
+}
@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils/log"
+)
+
+type messageUsecase struct {
+	repo domain.WaMessageRepository
+}
+
+func NewMessageUsecase(repo domain.WaMessageRepository) domain.WaMessageUsecase {
+	return &messageUsecase{repo: repo}
+}
+
+func (u *messageUsecase) Fetch(filter domain.WaMessageFilter) (messages []domain.WaMessage, meta domain.JSONResultMeta, err error) {
+	filter.Page, filter.PerPage = normalizePage(filter.Page, filter.PerPage)
+
+	messages, total, err := u.repo.Fetch(filter)
+	if err != nil {
+		return
+	}
+
+	meta = newResultMeta(total, filter.Page, filter.PerPage)
+
+	return
+}
+
+func (u *messageUsecase) GetByID(sessionID, id string) (domain.WaMessage, error) {
+	return u.repo.GetByID(sessionID, id)
+}
+
+func (u *messageUsecase) Status(sessionID, id string) (domain.WaMessageStatus, error) {
+	return u.repo.GetStatus(sessionID, id)
+}
+
+func (u *messageUsecase) HandleEvent(event domain.WaEvent) {
+	message, ok := event.Data.(domain.WaMessage)
+	if !ok {
+		return
+	}
+
+	err := u.repo.Store(message)
+	if err != nil {
+		log.Println(log.LogLevelError, "message-store", message.SessionID+": "+err.Error())
+	}
+}
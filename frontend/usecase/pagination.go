@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"math"
+)
+
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+)
+
+// normalizePage returns the page and page size to query, default to the first
+// page of 20 items. A page size above 100 is lowered to 100.
+func normalizePage(page, perPage int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = defaultPerPage
+	}
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+
+	return page, perPage
+}
+
+func newResultMeta(total, page, perPage int) domain.JSONResultMeta {
+	return domain.JSONResultMeta{
+		TotalCount:  total,
+		PageCount:   int(math.Ceil(float64(total) / float64(perPage))),
+		CurrentPage: page,
+		PerPage:     perPage,
+	}
+}
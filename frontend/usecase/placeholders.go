@@ -0,0 +1,35 @@
+package usecase
+
+import "regexp"
+
+// placeholderPattern matches the {{variable}} placeholders of the campaigns
+// and templates
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+
+// renderVariables replaces the {{variable}} placeholders of text with the
+// variables, the unknown ones are left as is.
+func renderVariables(text string, variables map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		name := placeholderPattern.FindStringSubmatch(placeholder)[1]
+		if v, ok := variables[name]; ok {
+			return v
+		}
+
+		return placeholder
+	})
+}
+
+// placeholders returns the variable names of the placeholders of text, each
+// one once in order of appearance.
+func placeholders(text string) []string {
+	names := []string{}
+	seen := make(map[string]bool)
+	for _, m := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+
+	return names
+}
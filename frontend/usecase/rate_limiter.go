@@ -0,0 +1,227 @@
+package usecase
+
+import (
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+)
+
+const rateLimitDayLayout = "2006-01-02"
+
+type rateLimiter struct {
+	repo         domain.WaRateLimitRepository
+	perMinute    int
+	perRecipient int
+	minInterval  time.Duration
+	jitter       time.Duration
+	warmupDays   int
+	warmupStart  int
+	warmupStep   int
+	overflow     domain.WaRateLimitOverflow
+
+	// mu guards sessions and lastSweep
+	mu        sync.Mutex
+	sessions  map[string]*sessionRate
+	lastSweep time.Time
+}
+
+// sessionRate is the sending of a session: the slots taken in the last
+// minute, in total and per recipient, and the messages sent today.
+type sessionRate struct {
+	next       time.Time
+	slots      []time.Time
+	recipients map[string][]time.Time
+
+	pairedAt     *time.Time
+	pairedLoaded bool
+
+	day   string
+	daily int
+}
+
+// NewRateLimiter creates the rate limiter of the sessions, the limits are read
+// from the RATE_LIMIT_* env (see the README), a limit set to 0 is disabled.
+// The pairing times and daily counts are kept in repo.
+func NewRateLimiter(repo domain.WaRateLimitRepository) domain.WaRateLimiter {
+	overflow := domain.WaRateLimitOverflow(os.Getenv("RATE_LIMIT_OVERFLOW"))
+	if overflow != domain.WaRateLimitQueue {
+		overflow = domain.WaRateLimitReject
+	}
+
+	return &rateLimiter{
+		repo:         repo,
+		perMinute:    utils.GetEnvInt("RATE_LIMIT_PER_MINUTE", 30),
+		perRecipient: utils.GetEnvInt("RATE_LIMIT_PER_RECIPIENT_PER_MINUTE", 6),
+		minInterval:  time.Duration(utils.GetEnvInt("RATE_LIMIT_MIN_INTERVAL_MS", 1000)) * time.Millisecond,
+		jitter:       time.Duration(utils.GetEnvInt("RATE_LIMIT_JITTER_MS", 1000)) * time.Millisecond,
+		warmupDays:   utils.GetEnvInt("RATE_LIMIT_WARMUP_DAYS", 7),
+		warmupStart:  utils.GetEnvInt("RATE_LIMIT_WARMUP_START", 100),
+		warmupStep:   utils.GetEnvInt("RATE_LIMIT_WARMUP_STEP", 100),
+		overflow:     overflow,
+		sessions:     make(map[string]*sessionRate),
+	}
+}
+
+func (l *rateLimiter) Overflow() domain.WaRateLimitOverflow {
+	return l.overflow
+}
+
+func (l *rateLimiter) Paired(sessionID string) error {
+	now := time.Now()
+	err := l.repo.SetPairedAt(sessionID, now)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s := l.session(sessionID)
+	s.pairedAt = &now
+	s.pairedLoaded = true
+
+	return nil
+}
+
+func (l *rateLimiter) Reserve(sessionID, jid string) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	s := l.session(sessionID)
+
+	// The message is sent at slot, the windows are counted back from it
+	slot := now
+	if s.next.After(slot) {
+		slot = s.next
+	}
+	windowStart := slot.Add(-time.Minute)
+
+	s.slots = slotsSince(s.slots, windowStart)
+	if l.perMinute > 0 && len(s.slots) >= l.perMinute {
+		return 0, &domain.WaRateLimitError{Limit: "per-minute", RetryAfter: s.slots[0].Add(time.Minute).Sub(now)}
+	}
+
+	recipient := slotsSince(s.recipients[jid], windowStart)
+	if l.perRecipient > 0 && len(recipient) >= l.perRecipient {
+		return 0, &domain.WaRateLimitError{Limit: "recipient", RetryAfter: recipient[0].Add(time.Minute).Sub(now)}
+	}
+
+	day := now.UTC().Format(rateLimitDayLayout)
+	if s.day != day {
+		daily, err := l.repo.DailyCount(sessionID, day)
+		if err != nil {
+			return 0, err
+		}
+		s.day, s.daily = day, daily
+	}
+
+	limit, err := l.dailyLimit(sessionID, s, now)
+	if err != nil {
+		return 0, err
+	}
+	if limit > 0 && s.daily >= limit {
+		tomorrow := now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+		return 0, &domain.WaRateLimitError{Limit: "daily", RetryAfter: tomorrow.Sub(now)}
+	}
+
+	// Stored by Done once the message is sent, counted here so the messages
+	// being sent take their quota
+	s.daily++
+
+	s.slots = append(s.slots, slot)
+	s.recipients[jid] = append(recipient, slot)
+	s.next = slot.Add(l.minInterval)
+	if l.jitter > 0 {
+		s.next = s.next.Add(time.Duration(rand.Int63n(int64(l.jitter))))
+	}
+
+	l.sweep(now)
+
+	return slot.Sub(now), nil
+}
+
+func (l *rateLimiter) Done(sessionID string, sent bool) error {
+	day := time.Now().UTC().Format(rateLimitDayLayout)
+	if sent {
+		return l.repo.IncrementDailyCount(sessionID, day)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s := l.session(sessionID)
+	if s.day == day && s.daily > 0 {
+		s.daily--
+	}
+
+	return nil
+}
+
+// dailyLimit returns the messages the session may send today, 0 when it is
+// not warming up.
+func (l *rateLimiter) dailyLimit(sessionID string, s *sessionRate, now time.Time) (int, error) {
+	if l.warmupDays <= 0 {
+		return 0, nil
+	}
+
+	if !s.pairedLoaded {
+		pairedAt, err := l.repo.PairedAt(sessionID)
+		if err != nil {
+			return 0, err
+		}
+		s.pairedAt, s.pairedLoaded = pairedAt, true
+	}
+
+	// The sessions paired before the limiter existed are not warmed up
+	if s.pairedAt == nil {
+		return 0, nil
+	}
+
+	day := int(now.Sub(*s.pairedAt)/(24*time.Hour)) + 1
+	if day > l.warmupDays {
+		return 0, nil
+	}
+
+	return l.warmupStart + (day-1)*l.warmupStep, nil
+}
+
+func (l *rateLimiter) session(sessionID string) *sessionRate {
+	s, ok := l.sessions[sessionID]
+	if !ok {
+		s = &sessionRate{recipients: make(map[string][]time.Time)}
+		l.sessions[sessionID] = s
+	}
+
+	return s
+}
+
+// sweep forgets the recipients without a message in the last minute, once a
+// minute.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+
+	for _, s := range l.sessions {
+		for jid, slots := range s.recipients {
+			if len(slotsSince(slots, now.Add(-time.Minute))) == 0 {
+				delete(s.recipients, jid)
+			}
+		}
+	}
+}
+
+// slotsSince drops the slots before start, slots are in time order.
+func slotsSince(slots []time.Time, start time.Time) []time.Time {
+	i := 0
+	for i < len(slots) && !slots[i].After(start) {
+		i++
+	}
+
+	return slots[i:]
+}
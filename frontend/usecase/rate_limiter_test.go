@@ -0,0 +1,299 @@
+package usecase
+
+import (
+	"errors"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"testing"
+	"time"
+)
+
+// memoryRateLimitRepository keeps the pairing times and daily counts in memory
+type memoryRateLimitRepository struct {
+	pairedAt map[string]*time.Time
+	daily    map[string]int
+}
+
+func newMemoryRateLimitRepository() *memoryRateLimitRepository {
+	return &memoryRateLimitRepository{
+		pairedAt: make(map[string]*time.Time),
+		daily:    make(map[string]int),
+	}
+}
+
+func (r *memoryRateLimitRepository) PairedAt(sessionID string) (*time.Time, error) {
+	return r.pairedAt[sessionID], nil
+}
+
+func (r *memoryRateLimitRepository) SetPairedAt(sessionID string, at time.Time) error {
+	r.pairedAt[sessionID] = &at
+	return nil
+}
+
+func (r *memoryRateLimitRepository) DailyCount(sessionID, day string) (int, error) {
+	return r.daily[sessionID+"|"+day], nil
+}
+
+func (r *memoryRateLimitRepository) IncrementDailyCount(sessionID, day string) error {
+	r.daily[sessionID+"|"+day]++
+	return nil
+}
+
+// newTestRateLimiter returns a limiter without interval nor jitter, so every
+// slot is taken now.
+func newTestRateLimiter(repo domain.WaRateLimitRepository, perMinute, perRecipient int) *rateLimiter {
+	return &rateLimiter{
+		repo:         repo,
+		perMinute:    perMinute,
+		perRecipient: perRecipient,
+		warmupDays:   7,
+		warmupStart:  100,
+		warmupStep:   100,
+		overflow:     domain.WaRateLimitReject,
+		sessions:     make(map[string]*sessionRate),
+	}
+}
+
+// limitOf returns the limit of a *WaRateLimitError, empty for nil.
+func limitOf(t *testing.T, err error) string {
+	t.Helper()
+
+	if err == nil {
+		return ""
+	}
+
+	var limited *domain.WaRateLimitError
+	if !errors.As(err, &limited) {
+		t.Fatalf("error %v is not a rate limit error", err)
+	}
+	if limited.RetryAfter <= 0 {
+		t.Errorf("%s limit: retry after %s, want > 0", limited.Limit, limited.RetryAfter)
+	}
+
+	return limited.Limit
+}
+
+func TestRateLimiterReserve(t *testing.T) {
+	type reserve struct {
+		sessionID string
+		jid       string
+		limit     string
+	}
+
+	tests := []struct {
+		name         string
+		perMinute    int
+		perRecipient int
+		reserves     []reserve
+	}{
+		{
+			name:      "per minute",
+			perMinute: 3,
+			reserves: []reserve{
+				{"default", "a", ""},
+				{"default", "b", ""},
+				{"default", "c", ""},
+				{"default", "d", "per-minute"},
+			},
+		},
+		{
+			name:         "per recipient",
+			perMinute:    10,
+			perRecipient: 2,
+			reserves: []reserve{
+				{"default", "a", ""},
+				{"default", "a", ""},
+				{"default", "a", "recipient"},
+				{"default", "b", ""},
+			},
+		},
+		{
+			name:      "per session",
+			perMinute: 1,
+			reserves: []reserve{
+				{"default", "a", ""},
+				{"sales", "a", ""},
+				{"default", "a", "per-minute"},
+			},
+		},
+		{
+			name: "disabled",
+			reserves: []reserve{
+				{"default", "a", ""},
+				{"default", "a", ""},
+				{"default", "a", ""},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newTestRateLimiter(newMemoryRateLimitRepository(), tt.perMinute, tt.perRecipient)
+
+			for i, r := range tt.reserves {
+				delay, err := l.Reserve(r.sessionID, r.jid)
+				if limit := limitOf(t, err); limit != r.limit {
+					t.Fatalf("reserve %d: limit %q, want %q", i+1, limit, r.limit)
+				}
+				if err == nil && delay != 0 {
+					t.Errorf("reserve %d: delay %s, want 0", i+1, delay)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterMinInterval(t *testing.T) {
+	l := newTestRateLimiter(newMemoryRateLimitRepository(), 0, 0)
+	l.minInterval = time.Second
+
+	for i, want := range []time.Duration{0, time.Second, 2 * time.Second} {
+		delay, err := l.Reserve("default", "a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The slots are taken from the clock, allow for the time elapsed
+		if delay > want || delay < want-100*time.Millisecond {
+			t.Errorf("reserve %d: delay %s, want about %s", i+1, delay, want)
+		}
+	}
+}
+
+func TestRateLimiterWarmup(t *testing.T) {
+	now := time.Now()
+	day := now.UTC().Format(rateLimitDayLayout)
+
+	tests := []struct {
+		name     string
+		pairedAt *time.Time
+		sent     int
+		limit    string
+	}{
+		{name: "not paired", sent: 1000},
+		{name: "first day under", pairedAt: timeAgo(now, time.Hour), sent: 99},
+		{name: "first day reached", pairedAt: timeAgo(now, time.Hour), sent: 100, limit: "daily"},
+		{name: "third day under", pairedAt: timeAgo(now, 50*time.Hour), sent: 299},
+		{name: "third day reached", pairedAt: timeAgo(now, 50*time.Hour), sent: 300, limit: "daily"},
+		{name: "last day reached", pairedAt: timeAgo(now, 6*24*time.Hour+time.Hour), sent: 700, limit: "daily"},
+		{name: "warmed up", pairedAt: timeAgo(now, 7*24*time.Hour+time.Hour), sent: 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemoryRateLimitRepository()
+			repo.pairedAt["default"] = tt.pairedAt
+			repo.daily["default|"+day] = tt.sent
+
+			l := newTestRateLimiter(repo, 0, 0)
+
+			_, err := l.Reserve("default", "a")
+			if limit := limitOf(t, err); limit != tt.limit {
+				t.Fatalf("limit %q, want %q", limit, tt.limit)
+			}
+			if err != nil {
+				return
+			}
+
+			if repo.daily["default|"+day] != tt.sent {
+				t.Errorf("daily count %d before the send, want %d", repo.daily["default|"+day], tt.sent)
+			}
+			if err = l.Done("default", true); err != nil {
+				t.Fatal(err)
+			}
+			if repo.daily["default|"+day] != tt.sent+1 {
+				t.Errorf("daily count %d, want %d", repo.daily["default|"+day], tt.sent+1)
+			}
+		})
+	}
+}
+
+func TestRateLimiterPaired(t *testing.T) {
+	repo := newMemoryRateLimitRepository()
+	l := newTestRateLimiter(repo, 0, 0)
+	l.warmupStart = 2
+
+	err := l.Paired("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.pairedAt["default"] == nil {
+		t.Fatal("pairing time not stored")
+	}
+
+	for i, want := range []string{"", "", "daily"} {
+		_, err = l.Reserve("default", "a")
+		if limit := limitOf(t, err); limit != want {
+			t.Fatalf("reserve %d: limit %q, want %q", i+1, limit, want)
+		}
+	}
+}
+
+func TestRateLimiterDone(t *testing.T) {
+	day := time.Now().UTC().Format(rateLimitDayLayout)
+
+	tests := []struct {
+		name      string
+		sent      []bool
+		wantDaily int
+		limit     string
+	}{
+		{name: "sent", sent: []bool{true, true}, wantDaily: 2, limit: "daily"},
+		{name: "failed", sent: []bool{false, false}, wantDaily: 0},
+		{name: "sent and failed", sent: []bool{true, false}, wantDaily: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemoryRateLimitRepository()
+			l := newTestRateLimiter(repo, 0, 0)
+			l.warmupStart = 2
+			if err := l.Paired("default"); err != nil {
+				t.Fatal(err)
+			}
+
+			for i, sent := range tt.sent {
+				if _, err := l.Reserve("default", "a"); err != nil {
+					t.Fatalf("reserve %d: %v", i+1, err)
+				}
+				if err := l.Done("default", sent); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if repo.daily["default|"+day] != tt.wantDaily {
+				t.Errorf("daily count %d, want %d", repo.daily["default|"+day], tt.wantDaily)
+			}
+
+			// The quota of the failed messages is given back
+			_, err := l.Reserve("default", "a")
+			if limit := limitOf(t, err); limit != tt.limit {
+				t.Errorf("next reserve: limit %q, want %q", limit, tt.limit)
+			}
+		})
+	}
+}
+
+func TestSlotsSince(t *testing.T) {
+	start := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	slots := []time.Time{start.Add(-time.Second), start, start.Add(time.Second), start.Add(time.Minute)}
+
+	tests := []struct {
+		since time.Time
+		want  int
+	}{
+		{start.Add(-time.Minute), 4},
+		{start.Add(-time.Second), 3},
+		{start, 2},
+		{start.Add(time.Minute), 0},
+	}
+
+	for _, tt := range tests {
+		if got := slotsSince(slots, tt.since); len(got) != tt.want {
+			t.Errorf("slotsSince(%s) kept %d slots, want %d", tt.since.Format(time.RFC3339), len(got), tt.want)
+		}
+	}
+}
+
+func timeAgo(now time.Time, d time.Duration) *time.Time {
+	t := now.Add(-d)
+	return &t
+}
@@ -0,0 +1,392 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils"
+	"github.com/cooljar/go-whatsapp-fiber/utils/log"
+	"mime/multipart"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	schedulePollPeriod = time.Second
+	schedulePollBatch  = 100
+)
+
+// scheduleLocalLayouts are the send_at layouts without an offset, read in the
+// timezone of the schedule
+var scheduleLocalLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+type scheduleUsecase struct {
+	repo      domain.WaScheduleRepository
+	sendQueue domain.WaSendQueueUsecase
+	media     domain.WaMediaUsecase
+	catchUp   domain.WaScheduleCatchUp
+	grace     time.Duration
+	maxRuns   int
+
+	// mu keeps a schedule from being cancelled while it runs
+	mu sync.Mutex
+
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+// NewScheduleUsecase creates the scheduler, the scheduled messages are queued
+// in sendQueue when they are due and the files kept in media.
+//
+// A run late by more than SCHEDULE_MISFIRE_GRACE_SECONDS (default to 60), as
+// after a downtime, is missed: SCHEDULE_CATCH_UP (default to once) is the
+// policy of the schedules without their own, and SCHEDULE_CATCH_UP_MAX_RUNS
+// (default to 10) the most missed runs the all policy sends.
+func NewScheduleUsecase(repo domain.WaScheduleRepository, sendQueue domain.WaSendQueueUsecase, media domain.WaMediaUsecase) domain.WaScheduleUsecase {
+	catchUp := domain.WaScheduleCatchUp(os.Getenv("SCHEDULE_CATCH_UP"))
+	if !validCatchUp(catchUp) {
+		catchUp = domain.WaScheduleCatchUpOnce
+	}
+
+	return &scheduleUsecase{
+		repo:      repo,
+		sendQueue: sendQueue,
+		media:     media,
+		catchUp:   catchUp,
+		grace:     time.Duration(utils.GetEnvInt("SCHEDULE_MISFIRE_GRACE_SECONDS", 60)) * time.Second,
+		maxRuns:   utils.GetEnvInt("SCHEDULE_CATCH_UP_MAX_RUNS", 10),
+		stop:      make(chan struct{}),
+	}
+}
+
+func (u *scheduleUsecase) Schedule(sessionID string, request domain.WaSendRequest, file *multipart.FileHeader, form domain.WaScheduleForm) (s domain.WaSchedule, err error) {
+	if !validSendType(request.Type) {
+		err = domain.ErrInvalidSendType
+		return
+	}
+
+	now := time.Now()
+	s = domain.WaSchedule{
+		ID:        utils.NewID(),
+		SessionID: sessionID,
+		Cron:      strings.TrimSpace(form.Cron),
+		Timezone:  form.Timezone,
+		CatchUp:   form.CatchUp,
+		Status:    domain.WaScheduleActive,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if s.Timezone == "" {
+		s.Timezone = "UTC"
+	}
+	if s.CatchUp == "" {
+		s.CatchUp = u.catchUp
+	}
+
+	err = u.applyForm(&s, form, now)
+	if err != nil {
+		return
+	}
+
+	if file != nil {
+		var media domain.WaMedia
+		media, err = saveUpload(u.media, file)
+		if err != nil {
+			return
+		}
+		request.MediaID = media.ID
+	}
+	s.Request = request
+
+	err = u.repo.Store(s)
+
+	return
+}
+
+func (u *scheduleUsecase) Get(sessionID, id string) (domain.WaSchedule, error) {
+	return u.get(sessionID, id)
+}
+
+func (u *scheduleUsecase) Fetch(filter domain.WaScheduleFilter) (schedules []domain.WaSchedule, meta domain.JSONResultMeta, err error) {
+	filter.Page, filter.PerPage = normalizePage(filter.Page, filter.PerPage)
+
+	schedules, total, err := u.repo.Fetch(filter)
+	if err != nil {
+		return
+	}
+
+	meta = newResultMeta(total, filter.Page, filter.PerPage)
+
+	return
+}
+
+func (u *scheduleUsecase) Cancel(sessionID, id string) (s domain.WaSchedule, err error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	s, err = u.get(sessionID, id)
+	if err != nil {
+		return
+	}
+
+	if s.Status != domain.WaScheduleActive {
+		err = domain.ErrScheduleNotActive
+		return
+	}
+
+	s.Status = domain.WaScheduleCancelled
+	s.NextRunAt = nil
+	s.UpdatedAt = time.Now()
+
+	err = u.repo.Update(s)
+
+	return
+}
+
+func (u *scheduleUsecase) Start() {
+	u.done.Add(1)
+	go func() {
+		defer u.done.Done()
+
+		ticker := time.NewTicker(schedulePollPeriod)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-u.stop:
+				return
+			case <-ticker.C:
+			}
+
+			u.runDue()
+		}
+	}()
+}
+
+func (u *scheduleUsecase) Shutdown(ctx context.Context) error {
+	close(u.stop)
+
+	done := make(chan struct{})
+	go func() {
+		u.done.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// get returns the schedule id of the session.
+func (u *scheduleUsecase) get(sessionID, id string) (domain.WaSchedule, error) {
+	s, err := u.repo.GetByID(id)
+	if err != nil {
+		return s, err
+	}
+	if s.SessionID != sessionID {
+		return s, domain.ErrScheduleNotFound
+	}
+
+	return s, nil
+}
+
+// applyForm sets the time, or the cron expression, and the first run of s.
+func (u *scheduleUsecase) applyForm(s *domain.WaSchedule, form domain.WaScheduleForm, now time.Time) error {
+	if !validCatchUp(s.CatchUp) {
+		return fmt.Errorf("%w: unknown catch_up %q, use skip, once or all", domain.ErrInvalidSchedule, s.CatchUp)
+	}
+
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return fmt.Errorf("%w: invalid timezone %q", domain.ErrInvalidSchedule, s.Timezone)
+	}
+
+	switch {
+	case form.SendAt != "" && s.Cron != "":
+		return fmt.Errorf("%w: send either a send_at or a cron", domain.ErrInvalidSchedule)
+	case s.Cron != "":
+		cron, err := parseCron(s.Cron)
+		if err != nil {
+			return fmt.Errorf("%w: %s", domain.ErrInvalidSchedule, err.Error())
+		}
+		next := cron.next(now.In(loc))
+		if next.IsZero() {
+			return fmt.Errorf("%w: cron %q never runs", domain.ErrInvalidSchedule, s.Cron)
+		}
+		s.NextRunAt = &next
+	case form.SendAt != "":
+		sendAt, err := parseSendAt(form.SendAt, loc)
+		if err != nil {
+			return fmt.Errorf("%w: %s", domain.ErrInvalidSchedule, err.Error())
+		}
+		if sendAt.Before(now.Add(-u.grace)) {
+			return fmt.Errorf("%w: send_at is in the past", domain.ErrInvalidSchedule)
+		}
+		s.SendAt = &sendAt
+		s.NextRunAt = &sendAt
+	default:
+		return fmt.Errorf("%w: send_at or cron required", domain.ErrInvalidSchedule)
+	}
+
+	return nil
+}
+
+// runDue queues the messages of the due schedules.
+func (u *scheduleUsecase) runDue() {
+	due, err := u.repo.FetchDue(time.Now(), schedulePollBatch)
+	if err != nil {
+		log.Println(log.LogLevelError, "scheduler", err.Error())
+		return
+	}
+
+	for _, s := range due {
+		select {
+		case <-u.stop:
+			return
+		default:
+		}
+
+		err = u.run(s.ID)
+		if err != nil {
+			log.Println(log.LogLevelError, "scheduler", s.ID+": "+err.Error())
+		}
+	}
+}
+
+// run queues the message of the schedule id for its due runs, as many as its
+// catch-up policy allows, and moves it to its next run.
+func (u *scheduleUsecase) run(id string) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	// Cancelled since it was fetched
+	s, err := u.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if s.Status != domain.WaScheduleActive || s.NextRunAt == nil || s.NextRunAt.After(now) {
+		return nil
+	}
+
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return err
+	}
+
+	var cron cronSchedule
+	if s.Cron != "" {
+		cron, err = parseCron(s.Cron)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The due runs, the first one is never missed
+	runs := []time.Time{*s.NextRunAt}
+	if s.Cron != "" {
+		for t := cron.next(s.NextRunAt.In(loc)); !t.IsZero() && !t.After(now); t = cron.next(t) {
+			if len(runs) > u.maxRuns {
+				// Only counted as skipped from here
+				s.Skipped++
+				continue
+			}
+			runs = append(runs, t)
+		}
+	}
+
+	missed := now.Sub(runs[len(runs)-1]) > u.grace
+	send := len(runs)
+	if missed {
+		switch s.CatchUp {
+		case domain.WaScheduleCatchUpSkip:
+			send = 0
+		case domain.WaScheduleCatchUpOnce:
+			send = 1
+		default:
+			if send > u.maxRuns {
+				send = u.maxRuns
+			}
+		}
+	} else {
+		// The last run is on time, the ones before it were missed
+		switch s.CatchUp {
+		case domain.WaScheduleCatchUpSkip, domain.WaScheduleCatchUpOnce:
+			send = 1
+		default:
+			if send > u.maxRuns+1 {
+				send = u.maxRuns + 1
+			}
+		}
+	}
+	s.Skipped += len(runs) - send
+
+	for i := 0; i < send; i++ {
+		job, err := u.sendQueue.Enqueue(s.SessionID, s.Request, nil)
+		if err != nil {
+			return err
+		}
+		s.Runs++
+		s.LastJobID = job.ID
+	}
+
+	last := runs[len(runs)-1]
+	s.LastRunAt = &now
+	s.NextRunAt = nil
+	if s.Cron != "" {
+		if next := cron.next(maxTime(last, now).In(loc)); !next.IsZero() {
+			s.NextRunAt = &next
+		}
+	}
+	if s.NextRunAt == nil {
+		s.Status = domain.WaScheduleCompleted
+	}
+	s.UpdatedAt = now
+
+	return u.repo.Update(s)
+}
+
+// parseSendAt parses a RFC3339 time, or a local time in loc without the
+// offset.
+func parseSendAt(value string, loc *time.Location) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return t, nil
+	}
+
+	for _, layout := range scheduleLocalLayouts {
+		t, err = time.ParseInLocation(layout, value, loc)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return t, fmt.Errorf("invalid send_at %q, expected RFC3339 or YYYY-MM-DD HH:MM in the timezone", value)
+}
+
+func validCatchUp(catchUp domain.WaScheduleCatchUp) bool {
+	switch catchUp {
+	case domain.WaScheduleCatchUpSkip, domain.WaScheduleCatchUpOnce, domain.WaScheduleCatchUpAll:
+		return true
+	}
+
+	return false
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
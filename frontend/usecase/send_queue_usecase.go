@@ -0,0 +1,389 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils"
+	"github.com/cooljar/go-whatsapp-fiber/utils/log"
+	"mime/multipart"
+	"sync"
+	"time"
+)
+
+const (
+	sendQueueBackoffMin  = 5 * time.Second
+	sendQueueBackoffMax  = 10 * time.Minute
+	sendQueuePollPeriod  = time.Second
+	sendQueuePollBatch   = 100
+	sendQueueErrorLength = 512
+)
+
+type sendQueueUsecase struct {
+	repo        domain.WaSendQueueRepository
+	sessions    domain.WhatsappSessionManager
+	media       domain.WaMediaUsecase
+	maxAttempts int
+	workers     int
+
+	// inFlightMu guards inFlight, the jobs handed to a worker
+	inFlightMu sync.Mutex
+	inFlight   map[string]struct{}
+
+	wake chan struct{}
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+// NewSendQueueUsecase creates the send queue, the messages are sent by the
+// sessions of sessions and the files kept in media. Jobs are tried
+// SEND_QUEUE_MAX_ATTEMPTS times (default to 5) by SEND_QUEUE_WORKERS workers
+// (default to 2).
+func NewSendQueueUsecase(repo domain.WaSendQueueRepository, sessions domain.WhatsappSessionManager, media domain.WaMediaUsecase) domain.WaSendQueueUsecase {
+	return &sendQueueUsecase{
+		repo:        repo,
+		sessions:    sessions,
+		media:       media,
+		maxAttempts: utils.GetEnvInt("SEND_QUEUE_MAX_ATTEMPTS", 5),
+		workers:     utils.GetEnvInt("SEND_QUEUE_WORKERS", 2),
+		inFlight:    make(map[string]struct{}),
+		wake:        make(chan struct{}, 1),
+		stop:        make(chan struct{}),
+	}
+}
+
+func (u *sendQueueUsecase) Enqueue(sessionID string, request domain.WaSendRequest, file *multipart.FileHeader) (job domain.WaSendJob, err error) {
+	if !validSendType(request.Type) {
+		err = domain.ErrInvalidSendType
+		return
+	}
+
+	if file != nil {
+		var media domain.WaMedia
+		media, err = saveUpload(u.media, file)
+		if err != nil {
+			return
+		}
+		request.MediaID = media.ID
+	}
+
+	now := time.Now()
+	job = domain.WaSendJob{
+		ID:            utils.NewID(),
+		SessionID:     sessionID,
+		Request:       request,
+		Status:        domain.WaSendJobQueued,
+		MaxAttempts:   u.maxAttempts,
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	err = u.repo.Store(job)
+	if err != nil {
+		return
+	}
+
+	u.notify()
+
+	return
+}
+
+func (u *sendQueueUsecase) Get(sessionID, id string) (job domain.WaSendJob, err error) {
+	job, err = u.get(sessionID, id)
+	if err != nil {
+		return
+	}
+
+	job.AttemptLog, err = u.repo.FetchAttempts(job.ID)
+
+	return
+}
+
+func (u *sendQueueUsecase) Fetch(filter domain.WaSendJobFilter) (jobs []domain.WaSendJob, meta domain.JSONResultMeta, err error) {
+	filter.Page, filter.PerPage = normalizePage(filter.Page, filter.PerPage)
+
+	jobs, total, err := u.repo.Fetch(filter)
+	if err != nil {
+		return
+	}
+
+	meta = newResultMeta(total, filter.Page, filter.PerPage)
+
+	return
+}
+
+func (u *sendQueueUsecase) Cancel(sessionID, id string) (job domain.WaSendJob, err error) {
+	// Holding the lock keeps the dispatcher from handing the job to a worker
+	u.inFlightMu.Lock()
+	defer u.inFlightMu.Unlock()
+
+	job, err = u.get(sessionID, id)
+	if err != nil {
+		return
+	}
+
+	if _, busy := u.inFlight[job.ID]; busy || job.Status != domain.WaSendJobQueued {
+		err = domain.ErrSendJobBusy
+		return
+	}
+
+	job.Status = domain.WaSendJobCancelled
+	job.NextAttemptAt = nil
+	job.UpdatedAt = time.Now()
+
+	err = u.repo.Update(job)
+
+	return
+}
+
+func (u *sendQueueUsecase) Retry(sessionID, id string) (job domain.WaSendJob, err error) {
+	job, err = u.get(sessionID, id)
+	if err != nil {
+		return
+	}
+
+	if job.Status != domain.WaSendJobFailed && job.Status != domain.WaSendJobCancelled {
+		err = domain.ErrSendJobNotFailed
+		return
+	}
+
+	now := time.Now()
+	job.Status = domain.WaSendJobQueued
+	job.MaxAttempts = job.Attempts + u.maxAttempts
+	job.NextAttemptAt = &now
+	job.UpdatedAt = now
+
+	err = u.repo.Update(job)
+	if err != nil {
+		return
+	}
+
+	u.notify()
+
+	return
+}
+
+func (u *sendQueueUsecase) Start() {
+	// The jobs being sent when the service stopped are sent again
+	err := u.repo.Requeue()
+	if err != nil {
+		log.Println(log.LogLevelError, "send-queue", err.Error())
+	}
+
+	jobs := make(chan domain.WaSendJob)
+
+	for i := 0; i < u.workers; i++ {
+		u.done.Add(1)
+		go func() {
+			defer u.done.Done()
+
+			for job := range jobs {
+				u.send(job)
+			}
+		}()
+	}
+
+	u.done.Add(1)
+	go func() {
+		defer u.done.Done()
+		defer close(jobs)
+
+		ticker := time.NewTicker(sendQueuePollPeriod)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-u.stop:
+				return
+			case <-u.wake:
+			case <-ticker.C:
+			}
+
+			if !u.dispatchDue(jobs) {
+				return
+			}
+		}
+	}()
+}
+
+func (u *sendQueueUsecase) Shutdown(ctx context.Context) error {
+	close(u.stop)
+
+	done := make(chan struct{})
+	go func() {
+		u.done.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// get returns the job id of the session.
+func (u *sendQueueUsecase) get(sessionID, id string) (domain.WaSendJob, error) {
+	job, err := u.repo.GetByID(id)
+	if err != nil {
+		return job, err
+	}
+	if job.SessionID != sessionID {
+		return job, domain.ErrSendJobNotFound
+	}
+
+	return job, nil
+}
+
+// dispatchDue hands the due jobs to the workers, it returns false when the
+// dispatcher is stopped.
+func (u *sendQueueUsecase) dispatchDue(jobs chan<- domain.WaSendJob) bool {
+	due, err := u.repo.FetchDue(time.Now(), sendQueuePollBatch)
+	if err != nil {
+		log.Println(log.LogLevelError, "send-queue", err.Error())
+		return true
+	}
+
+	for _, job := range due {
+		u.inFlightMu.Lock()
+		_, busy := u.inFlight[job.ID]
+		if !busy {
+			// Cancelled since it was fetched
+			job, err = u.repo.GetByID(job.ID)
+			busy = err != nil || job.Status != domain.WaSendJobQueued
+		}
+		if !busy {
+			u.inFlight[job.ID] = struct{}{}
+		}
+		u.inFlightMu.Unlock()
+
+		if busy {
+			continue
+		}
+
+		select {
+		case jobs <- job:
+		case <-u.stop:
+			u.release(job.ID)
+			return false
+		}
+	}
+
+	return true
+}
+
+// send tries to send the job once and records the attempt.
+func (u *sendQueueUsecase) send(job domain.WaSendJob) {
+	defer u.release(job.ID)
+
+	job.Attempts++
+	job.Status = domain.WaSendJobSending
+	job.UpdatedAt = time.Now()
+	err := u.repo.Update(job)
+	if err != nil {
+		log.Println(log.LogLevelError, "send-queue", job.ID+": "+err.Error())
+		return
+	}
+
+	attempt := domain.WaSendAttempt{JobID: job.ID, Attempt: job.Attempts, StartedAt: time.Now()}
+
+	wa, err := u.sessions.Get(job.SessionID)
+	if err == nil {
+		job.MessageID, err = sendRequest(wa, u.media, job.Request)
+	}
+
+	now := time.Now()
+
+	// Over the rate limit the job waits for a slot, it is not an attempt
+	var limited *domain.WaRateLimitError
+	if errors.As(err, &limited) {
+		next := now.Add(limited.RetryAfter)
+		job.Attempts--
+		job.Status = domain.WaSendJobQueued
+		job.NextAttemptAt = &next
+		job.UpdatedAt = now
+
+		err = u.repo.Update(job)
+		if err != nil {
+			log.Println(log.LogLevelError, "send-queue", job.ID+": "+err.Error())
+		}
+		return
+	}
+
+	// Interrupted by the shutdown, it is sent on the next start
+	if errors.Is(err, domain.ErrShuttingDown) {
+		job.Attempts--
+		job.Status = domain.WaSendJobQueued
+		job.UpdatedAt = now
+
+		err = u.repo.Update(job)
+		if err != nil {
+			log.Println(log.LogLevelError, "send-queue", job.ID+": "+err.Error())
+		}
+		return
+	}
+
+	attempt.FinishedAt = now
+	job.UpdatedAt = now
+	job.NextAttemptAt = nil
+	job.LastError = ""
+
+	switch {
+	case err == nil:
+		job.Status = domain.WaSendJobSent
+		job.SentAt = &now
+	case job.Attempts >= job.MaxAttempts:
+		job.Status = domain.WaSendJobFailed
+		job.LastError = err.Error()
+	default:
+		next := now.Add(sendQueueBackoff(job.Attempts))
+		job.Status = domain.WaSendJobQueued
+		job.NextAttemptAt = &next
+		job.LastError = err.Error()
+	}
+
+	if len(job.LastError) > sendQueueErrorLength {
+		job.LastError = job.LastError[:sendQueueErrorLength]
+	}
+	attempt.Error = job.LastError
+
+	err = u.repo.StoreAttempt(attempt)
+	if err != nil {
+		log.Println(log.LogLevelError, "send-queue", job.ID+": "+err.Error())
+	}
+
+	err = u.repo.Update(job)
+	if err != nil {
+		log.Println(log.LogLevelError, "send-queue", job.ID+": "+err.Error())
+	}
+}
+
+func (u *sendQueueUsecase) release(jobID string) {
+	u.inFlightMu.Lock()
+	defer u.inFlightMu.Unlock()
+
+	delete(u.inFlight, jobID)
+}
+
+func (u *sendQueueUsecase) notify() {
+	select {
+	case u.wake <- struct{}{}:
+	default:
+	}
+}
+
+// sendQueueBackoff is an exponential backoff from 5 seconds up to 10 minutes.
+func sendQueueBackoff(attempt int) time.Duration {
+	if attempt > 20 {
+		return sendQueueBackoffMax
+	}
+
+	delay := sendQueueBackoffMin << uint(attempt-1)
+	if delay > sendQueueBackoffMax {
+		delay = sendQueueBackoffMax
+	}
+
+	return delay
+}
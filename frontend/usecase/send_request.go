@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils"
+	"io/ioutil"
+	"mime/multipart"
+)
+
+// sendRequest sends the request with the session wa, the file of a media
+// message is read from the media library.
+func sendRequest(wa domain.WhatsappUsecase, media domain.WaMediaUsecase, request domain.WaSendRequest) (msgId string, err error) {
+	switch request.Type {
+	case "text":
+		return wa.SendText(domain.WaSendTextForm{
+			Msisdn:        request.Msisdn,
+			Text:          request.Text,
+			MsgQuotedID:   request.MsgQuotedID,
+			MsgQuoted:     request.MsgQuoted,
+			TypingSeconds: request.TypingSeconds,
+		})
+	case "location":
+		return wa.SendLocation(domain.WaSendLocationForm{
+			Msisdn:      request.Msisdn,
+			Latitude:    request.Latitude,
+			Longitude:   request.Longitude,
+			MsgQuotedID: request.MsgQuotedID,
+			MsgQuoted:   request.MsgQuoted,
+		})
+	case "contact":
+		return wa.SendContacts(domain.WaSendContactForm{
+			Msisdn:      request.Msisdn,
+			Contacts:    request.Contacts,
+			MsgQuotedID: request.MsgQuotedID,
+			MsgQuoted:   request.MsgQuoted,
+		})
+	case "image", "document", "audio", "video":
+	default:
+		return "", domain.ErrInvalidSendType
+	}
+
+	m, content, err := media.Get(request.MediaID)
+	if err != nil {
+		return
+	}
+	b, err := ioutil.ReadAll(content)
+	content.Close()
+	if err != nil {
+		return
+	}
+
+	fileHeader, err := utils.NewFileHeader(m.FileName, m.MimeType, b)
+	if err != nil {
+		return
+	}
+
+	return wa.SendFile(domain.WaSendFileForm{
+		Msisdn:      request.Msisdn,
+		MsgQuotedID: request.MsgQuotedID,
+		MsgQuoted:   request.MsgQuoted,
+		Message:     request.Text,
+		FileHeader:  fileHeader,
+	}, request.Type)
+}
+
+// validSendType reports whether t is a message type sendRequest can send.
+func validSendType(t string) bool {
+	switch t {
+	case "text", "location", "contact", "image", "document", "audio", "video":
+		return true
+	}
+
+	return false
+}
+
+// saveUpload stores the uploaded file in the media library.
+func saveUpload(media domain.WaMediaUsecase, file *multipart.FileHeader) (domain.WaMedia, error) {
+	return media.Save("upload", file.Header.Get("Content-Type"), file.Filename, uint64(file.Size),
+		func() ([]byte, error) {
+			f, err := file.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+
+			return ioutil.ReadAll(f)
+		})
+}
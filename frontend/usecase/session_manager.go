@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/Rhymen/go-whatsapp"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils"
+	"github.com/cooljar/go-whatsapp-fiber/utils/log"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+var sessionIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+type whatsappSessionManager struct {
+	mu           sync.RWMutex
+	sessions     map[string]domain.WhatsappUsecase
+	maxSessions  int
+	newConn      func() (*whatsapp.Conn, error)
+	sessionStore domain.SessionStore
+	events       domain.WaEventBus
+	messages     domain.WaMessageRepository
+	media        domain.WaMediaUsecase
+	contacts     domain.WaContactUsecase
+	limiter      domain.WaRateLimiter
+}
+
+// NewWhatsappSessionManager creates an empty session registry, newConn is used
+// to open the connection of every session added to it. The events received by
+// the sessions are published on events, the messages they send are stored in
+// messages, the media they receive in media and their contacts in contacts.
+// The messages sent are throttled by limiter. At most WHATSAPP_MAX_SESSIONS
+// sessions (default to 10) are registered.
+func NewWhatsappSessionManager(newConn func() (*whatsapp.Conn, error), sessionStore domain.SessionStore, events domain.WaEventBus, messages domain.WaMessageRepository, media domain.WaMediaUsecase, contacts domain.WaContactUsecase, limiter domain.WaRateLimiter) domain.WhatsappSessionManager {
+	return &whatsappSessionManager{
+		sessions:     make(map[string]domain.WhatsappUsecase),
+		maxSessions:  utils.GetEnvInt("WHATSAPP_MAX_SESSIONS", 10),
+		newConn:      newConn,
+		sessionStore: sessionStore,
+		events:       events,
+		messages:     messages,
+		media:        media,
+		contacts:     contacts,
+		limiter:      limiter,
+	}
+}
+
+func (m *whatsappSessionManager) Get(sessionID string) (domain.WhatsappUsecase, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	session, ok := m.sessions[sessionID]
+	if !ok {
+		return nil, domain.ErrSessionNotFound
+	}
+
+	return session, nil
+}
+
+func (m *whatsappSessionManager) GetOrCreate(sessionID string) (domain.WhatsappUsecase, error) {
+	if !sessionIDPattern.MatchString(sessionID) {
+		return nil, domain.ErrInvalidSessionID
+	}
+
+	m.mu.RLock()
+	session, ok := m.sessions[sessionID]
+	full := len(m.sessions) >= m.maxSessions
+	m.mu.RUnlock()
+	if ok {
+		return session, nil
+	}
+	if full {
+		return nil, domain.ErrTooManySessions
+	}
+
+	// Dialing may be slow, the registry is not locked meanwhile
+	conn, err := m.newConn()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Created or filled up while dialing
+	if session, ok := m.sessions[sessionID]; ok {
+		_, _ = conn.Disconnect()
+		return session, nil
+	}
+	if len(m.sessions) >= m.maxSessions {
+		_, _ = conn.Disconnect()
+		return nil, domain.ErrTooManySessions
+	}
+
+	session = NewWhatsappUsecase(sessionID, conn, m.newConn, m.sessionStore, m.events, m.messages, m.media, m.contacts, m.limiter)
+	m.sessions[sessionID] = session
+
+	return session, nil
+}
+
+func (m *whatsappSessionManager) List() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ids := make([]string, 0, len(m.sessions))
+	for id := range m.sessions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+// RestoreAll restores every session found in the session store. A session
+// that can not be restored is logged and skipped, so one broken account does
+// not keep the others offline.
+func (m *whatsappSessionManager) RestoreAll() error {
+	sessionIDs, err := m.sessionStore.List()
+	if err != nil {
+		return err
+	}
+
+	for _, sessionID := range sessionIDs {
+		if !sessionIDPattern.MatchString(sessionID) {
+			continue
+		}
+
+		session, err := m.GetOrCreate(sessionID)
+		if err == domain.ErrTooManySessions {
+			log.Println(log.LogLevelError, "whatsapp-session-restore", sessionID+": "+err.Error())
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		err = session.RestoreSession()
+		if err == domain.ErrSessionDecrypt || err == domain.ErrSessionEncrypted {
+			// A wrong key is a configuration error, refuse to start rather than
+			// running without the sessions or overwriting them.
+			return fmt.Errorf("session %s: %w", sessionID, err)
+		}
+		if err != nil {
+			log.Println(log.LogLevelError, "whatsapp-session-restore", sessionID+": "+err.Error())
+			continue
+		}
+
+		log.Println(log.LogLevelInfo, "whatsapp-session-restore", sessionID+": restored")
+	}
+
+	return nil
+}
+
+// StopSending stops every session from sending, see whatsappUsecase.StopSending.
+func (m *whatsappSessionManager) StopSending() {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, session := range m.sessions {
+		session.StopSending()
+	}
+}
+
+// Shutdown shuts every session down in parallel, see whatsappUsecase.Shutdown.
+func (m *whatsappSessionManager) Shutdown(ctx context.Context) error {
+	m.mu.RLock()
+	sessions := make([]domain.WhatsappUsecase, 0, len(m.sessions))
+	for _, session := range m.sessions {
+		sessions = append(sessions, session)
+	}
+	m.mu.RUnlock()
+
+	errs := make(chan error, len(sessions))
+	for _, session := range sessions {
+		go func(session domain.WhatsappUsecase) {
+			err := session.Shutdown(ctx)
+			if err != nil {
+				err = fmt.Errorf("session %s: %w", session.SessionID(), err)
+			}
+			errs <- err
+		}(session)
+	}
+
+	var firstErr error
+	for range sessions {
+		if err := <-errs; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
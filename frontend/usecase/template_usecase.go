@@ -0,0 +1,199 @@
+package usecase
+
+import (
+	"fmt"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils"
+	"sort"
+	"strings"
+	"time"
+)
+
+type templateUsecase struct {
+	repo domain.TemplateRepository
+}
+
+// NewTemplateUsecase manages the message templates of repo and renders them.
+func NewTemplateUsecase(repo domain.TemplateRepository) domain.TemplateUsecase {
+	return &templateUsecase{repo: repo}
+}
+
+func (u *templateUsecase) Create(form domain.TemplateForm) (t domain.Template, err error) {
+	now := time.Now()
+	t = domain.Template{
+		ID:        utils.NewID(),
+		CreatedAt: now,
+	}
+
+	err = applyTemplateForm(&t, form, now)
+	if err != nil {
+		return
+	}
+
+	err = u.repo.Store(t)
+	if err != nil {
+		return
+	}
+
+	t.Placeholders = templatePlaceholders(t)
+
+	return
+}
+
+func (u *templateUsecase) Update(id string, form domain.TemplateForm) (t domain.Template, err error) {
+	t, err = u.repo.GetByID(id)
+	if err != nil {
+		return
+	}
+
+	err = applyTemplateForm(&t, form, time.Now())
+	if err != nil {
+		return
+	}
+
+	err = u.repo.Update(t)
+	if err != nil {
+		return
+	}
+
+	t.Placeholders = templatePlaceholders(t)
+
+	return
+}
+
+func (u *templateUsecase) Delete(id string) error {
+	return u.repo.Delete(id)
+}
+
+func (u *templateUsecase) Get(id string) (domain.Template, error) {
+	t, err := u.repo.GetByID(id)
+	if err != nil {
+		return t, err
+	}
+
+	t.Placeholders = templatePlaceholders(t)
+
+	return t, nil
+}
+
+func (u *templateUsecase) Fetch() ([]domain.Template, error) {
+	templates, err := u.repo.Fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range templates {
+		templates[i].Placeholders = templatePlaceholders(templates[i])
+	}
+
+	return templates, nil
+}
+
+func (u *templateUsecase) Render(id string, form domain.TemplateRenderForm) (render domain.TemplateRender, err error) {
+	t, err := u.repo.GetByID(id)
+	if err != nil {
+		return
+	}
+
+	render.TemplateID = t.ID
+	render.Language, render.Text = templateVariant(t, form.Language)
+
+	variables := make(map[string]string, len(t.Defaults)+len(form.Variables))
+	for k, v := range t.Defaults {
+		variables[k] = v
+	}
+	for k, v := range form.Variables {
+		variables[k] = v
+	}
+
+	var missing []string
+	for _, name := range placeholders(render.Text) {
+		if _, ok := variables[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return domain.TemplateRender{}, fmt.Errorf("%w: %s", domain.ErrTemplateVariableMissing, strings.Join(missing, ", "))
+	}
+
+	render.Text = renderVariables(render.Text, variables)
+
+	return
+}
+
+// templateVariant returns the language and text of the variant of t for
+// language: the exact language, else its base language ("pt" for "pt-BR"),
+// else the template text.
+func templateVariant(t domain.Template, language string) (string, string) {
+	if language == "" || strings.EqualFold(language, t.Language) {
+		return t.Language, t.Text
+	}
+
+	for code, text := range t.Variants {
+		if strings.EqualFold(code, language) {
+			return code, text
+		}
+	}
+
+	if i := strings.IndexAny(language, "-_"); i > 0 {
+		base := language[:i]
+		if strings.EqualFold(base, t.Language) {
+			return t.Language, t.Text
+		}
+		for code, text := range t.Variants {
+			if strings.EqualFold(code, base) {
+				return code, text
+			}
+		}
+	}
+
+	return t.Language, t.Text
+}
+
+// templatePlaceholders returns the placeholders of the text and the variants
+// of t, the ones of the text first.
+func templatePlaceholders(t domain.Template) []string {
+	codes := make([]string, 0, len(t.Variants))
+	for code := range t.Variants {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	text := t.Text
+	for _, code := range codes {
+		text += "\n" + t.Variants[code]
+	}
+
+	return placeholders(text)
+}
+
+func applyTemplateForm(t *domain.Template, form domain.TemplateForm, now time.Time) error {
+	t.Name = form.Name
+	t.Language = form.Language
+	t.Text = form.Text
+	t.Defaults = form.Defaults
+	t.Variants = form.Variants
+	t.UpdatedAt = now
+	if t.Defaults == nil {
+		t.Defaults = map[string]string{}
+	}
+	if t.Variants == nil {
+		t.Variants = map[string]string{}
+	}
+
+	if _, ok := t.Variants[t.Language]; ok && t.Language != "" {
+		return fmt.Errorf("%w: variant %q is the template language", domain.ErrInvalidTemplate, t.Language)
+	}
+
+	known := make(map[string]bool)
+	for _, name := range templatePlaceholders(*t) {
+		known[name] = true
+	}
+	for name := range t.Defaults {
+		if !known[name] {
+			return fmt.Errorf("%w: default %q matches no placeholder", domain.ErrInvalidTemplate, name)
+		}
+	}
+
+	return nil
+}
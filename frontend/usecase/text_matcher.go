@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"fmt"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"regexp"
+	"strings"
+)
+
+// textMatcher compares a received text to a pattern, as the auto-reply rules
+// and the flows do.
+type textMatcher struct {
+	match         domain.AutoReplyMatch
+	pattern       string
+	caseSensitive bool
+	re            *regexp.Regexp
+}
+
+func newTextMatcher(match domain.AutoReplyMatch, pattern string, caseSensitive bool) (m textMatcher, err error) {
+	m = textMatcher{match: match, pattern: pattern, caseSensitive: caseSensitive}
+
+	switch match {
+	case domain.AutoReplyExact, domain.AutoReplyContains:
+	case domain.AutoReplyRegex:
+		if !caseSensitive {
+			pattern = "(?i)" + pattern
+		}
+		m.re, err = regexp.Compile(pattern)
+	default:
+		err = fmt.Errorf("unknown match %q", match)
+	}
+
+	return
+}
+
+func (m textMatcher) matches(text string) bool {
+	switch m.match {
+	case domain.AutoReplyExact:
+		text = strings.TrimSpace(text)
+		if m.caseSensitive {
+			return text == m.pattern
+		}
+		return strings.EqualFold(text, m.pattern)
+	case domain.AutoReplyContains:
+		if m.caseSensitive {
+			return strings.Contains(text, m.pattern)
+		}
+		return strings.Contains(strings.ToLower(text), strings.ToLower(m.pattern))
+	case domain.AutoReplyRegex:
+		return m.re.MatchString(text)
+	}
+
+	return false
+}
+
+// matchJids reports whether message is in one of jids, the chat or, in
+// groups, the sender. Empty jids match every message.
+func matchJids(jids []string, message domain.WaMessage) bool {
+	if len(jids) == 0 {
+		return true
+	}
+
+	for _, jid := range jids {
+		if jid == message.Jid || jid == message.SenderJid {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeJids returns the full jids of jids, given as jid or msisdn.
+func normalizeJids(jids []string) []string {
+	normalized := make([]string, 0, len(jids))
+	for _, jid := range jids {
+		normalized = append(normalized, parseMsisdn(jid))
+	}
+
+	return normalized
+}
+
+// messageContact is the contact who sent message, the sender in groups.
+func messageContact(message domain.WaMessage) string {
+	if message.SenderJid != "" {
+		return message.SenderJid
+	}
+
+	return message.Jid
+}
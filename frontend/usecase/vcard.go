@@ -0,0 +1,296 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/Rhymen/go-whatsapp"
+	"github.com/Rhymen/go-whatsapp/binary/proto"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// vcardLineLength is the longest vCard line, in octets, before it is
+	// folded
+	vcardLineLength = 75
+	// maxContactCards is the most contacts sent in a message
+	maxContactCards = 20
+)
+
+// vcardContact is a contact card ready to be sent
+type vcardContact struct {
+	displayName string
+	vcard       string
+}
+
+// contactVcards returns the vCards of the contacts, a raw vCard may hold
+// several cards.
+func contactVcards(contacts []domain.WaContactCard) ([]vcardContact, error) {
+	var cards []vcardContact
+	for i, contact := range contacts {
+		if contact.Vcard == "" {
+			vcard, err := newVcard(contact)
+			if err != nil {
+				return nil, fmt.Errorf("%w: contact %d: %s", domain.ErrInvalidContact, i+1, err.Error())
+			}
+			cards = append(cards, vcardContact{displayName: strings.TrimSpace(contact.Name), vcard: vcard})
+			continue
+		}
+
+		raw, err := splitVcards(contact.Vcard)
+		if err != nil {
+			return nil, fmt.Errorf("%w: contact %d: %s", domain.ErrInvalidContact, i+1, err.Error())
+		}
+		if contact.Name != "" && len(raw) == 1 {
+			raw[0].displayName = contact.Name
+		}
+		cards = append(cards, raw...)
+	}
+
+	if len(cards) == 0 {
+		return nil, fmt.Errorf("%w: no contact", domain.ErrInvalidContact)
+	}
+
+	return cards, nil
+}
+
+// contactsArrayProto returns the message of several contacts to jid, the
+// library only builds the single contact ones.
+func contactsArrayProto(jid string, cards []vcardContact, contextInfo whatsapp.ContextInfo) *proto.WebMessageInfo {
+	// As the library does for the messages it builds
+	b := make([]byte, 10)
+	rand.Read(b)
+	id := strings.ToUpper(hex.EncodeToString(b))
+	timestamp := uint64(time.Now().Unix())
+	fromMe := true
+	status := proto.WebMessageInfo_WEB_MESSAGE_INFO_STATUS(0)
+
+	contacts := make([]*proto.ContactMessage, len(cards))
+	for i := range cards {
+		contacts[i] = &proto.ContactMessage{
+			DisplayName: &cards[i].displayName,
+			Vcard:       &cards[i].vcard,
+		}
+	}
+
+	displayName := strconv.Itoa(len(cards)) + " contacts"
+	message := &proto.ContactsArrayMessage{
+		DisplayName: &displayName,
+		Contacts:    contacts,
+	}
+	if contextInfo.QuotedMessageID != "" {
+		message.ContextInfo = &proto.ContextInfo{
+			StanzaId:      &contextInfo.QuotedMessageID,
+			QuotedMessage: contextInfo.QuotedMessage,
+			Participant:   &contextInfo.Participant,
+		}
+	}
+
+	return &proto.WebMessageInfo{
+		Key: &proto.MessageKey{
+			FromMe:    &fromMe,
+			RemoteJid: &jid,
+			Id:        &id,
+		},
+		MessageTimestamp: &timestamp,
+		Status:           &status,
+		Message: &proto.Message{
+			ContactsArrayMessage: message,
+		},
+	}
+}
+
+// newVcard returns the vCard 3.0 of the contact, it needs a name and a phone
+// number.
+func newVcard(contact domain.WaContactCard) (string, error) {
+	name := strings.TrimSpace(contact.Name)
+	if name == "" {
+		return "", fmt.Errorf("name required")
+	}
+	if len(contact.Phones) == 0 {
+		return "", fmt.Errorf("phone required")
+	}
+
+	var b strings.Builder
+	line := func(s string) {
+		b.WriteString(foldVcardLine(s))
+		b.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCARD")
+	line("VERSION:3.0")
+	line("N:;" + escapeVcard(name) + ";;;")
+	line("FN:" + escapeVcard(name))
+	if org := strings.TrimSpace(contact.Organization); org != "" {
+		line("ORG:" + escapeVcard(org))
+	}
+
+	for _, phone := range contact.Phones {
+		phone = strings.TrimSpace(phone)
+		digits := strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, phone)
+		if digits == "" {
+			return "", fmt.Errorf("invalid phone %q", phone)
+		}
+		if phone == digits {
+			phone = "+" + digits
+		}
+
+		// waid links the number to its whatsapp account
+		line("TEL;type=CELL;type=VOICE;waid=" + digits + ":" + escapeVcard(phone))
+	}
+
+	for _, email := range contact.Emails {
+		email = strings.TrimSpace(email)
+		if !strings.Contains(email, "@") {
+			return "", fmt.Errorf("invalid email %q", email)
+		}
+		line("EMAIL;type=INTERNET:" + escapeVcard(email))
+	}
+
+	line("END:VCARD")
+
+	return b.String(), nil
+}
+
+// splitVcards returns the cards of a raw vCard, named after their FN or N
+// property.
+func splitVcards(raw string) ([]vcardContact, error) {
+	var cards []vcardContact
+	var card []string
+	var displayName, fallbackName string
+	inCard := false
+
+	for _, line := range unfoldVcard(raw) {
+		name, value := vcardProperty(line)
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VCARD"):
+			if inCard {
+				return nil, fmt.Errorf("BEGIN:VCARD before END:VCARD")
+			}
+			inCard = true
+			card = nil
+			displayName, fallbackName = "", ""
+		case !inCard:
+			if strings.TrimSpace(line) != "" {
+				return nil, fmt.Errorf("content outside of BEGIN:VCARD and END:VCARD")
+			}
+			continue
+		case name == "FN":
+			displayName = unescapeVcard(value)
+		case name == "N":
+			fallbackName = strings.TrimSpace(strings.Join(strings.Fields(strings.ReplaceAll(unescapeVcard(value), ";", " ")), " "))
+		}
+
+		card = append(card, line)
+
+		if name == "END" && strings.EqualFold(value, "VCARD") {
+			inCard = false
+			if displayName == "" {
+				displayName = fallbackName
+			}
+			if displayName == "" {
+				return nil, fmt.Errorf("vCard %d has no FN or N name", len(cards)+1)
+			}
+
+			var b strings.Builder
+			for _, l := range card {
+				b.WriteString(foldVcardLine(l))
+				b.WriteString("\r\n")
+			}
+			cards = append(cards, vcardContact{displayName: displayName, vcard: b.String()})
+		}
+	}
+
+	if inCard {
+		return nil, fmt.Errorf("END:VCARD missing")
+	}
+	if len(cards) == 0 {
+		return nil, fmt.Errorf("no BEGIN:VCARD found")
+	}
+
+	return cards, nil
+}
+
+// unfoldVcard returns the lines of a vCard, the folded lines joined.
+func unfoldVcard(raw string) []string {
+	raw = strings.TrimPrefix(raw, "\ufeff")
+	raw = strings.ReplaceAll(raw, "\r\n", "\n")
+
+	var lines []string
+	for _, line := range strings.Split(raw, "\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, "\r"))
+	}
+
+	return lines
+}
+
+// vcardProperty returns the upper case name, without its parameters, and
+// the value of a vCard line.
+func vcardProperty(line string) (name, value string) {
+	i := strings.Index(line, ":")
+	if i < 0 {
+		return "", ""
+	}
+
+	name = line[:i]
+	if j := strings.Index(name, ";"); j >= 0 {
+		name = name[:j]
+	}
+	// A group prefix, eg: item1.TEL
+	if j := strings.LastIndex(name, "."); j >= 0 {
+		name = name[j+1:]
+	}
+
+	return strings.ToUpper(strings.TrimSpace(name)), line[i+1:]
+}
+
+// foldVcardLine splits the line in lines of at most vcardLineLength octets,
+// never inside a character, the following lines start with a space.
+func foldVcardLine(line string) string {
+	if len(line) <= vcardLineLength {
+		return line
+	}
+
+	var b strings.Builder
+	limit := vcardLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space counts
+		limit = vcardLineLength - 1
+	}
+	b.WriteString(line)
+
+	return b.String()
+}
+
+var (
+	vcardEscaper   = strings.NewReplacer(`\`, `\\`, `,`, `\,`, `;`, `\;`, "\r\n", `\n`, "\n", `\n`)
+	vcardUnescaper = strings.NewReplacer(`\\`, `\`, `\,`, `,`, `\;`, `;`, `\n`, "\n", `\N`, "\n")
+)
+
+func escapeVcard(s string) string {
+	return vcardEscaper.Replace(s)
+}
+
+func unescapeVcard(s string) string {
+	return vcardUnescaper.Replace(s)
+}
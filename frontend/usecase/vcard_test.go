@@ -0,0 +1,242 @@
+package usecase
+
+import (
+	"errors"
+	"github.com/Rhymen/go-whatsapp"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestNewVcard(t *testing.T) {
+	tests := []struct {
+		name    string
+		contact domain.WaContactCard
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "phone only",
+			contact: domain.WaContactCard{Name: "Budi", Phones: []string{"6281234567890"}},
+			want: "BEGIN:VCARD\r\nVERSION:3.0\r\nN:;Budi;;;\r\nFN:Budi\r\n" +
+				"TEL;type=CELL;type=VOICE;waid=6281234567890:+6281234567890\r\nEND:VCARD\r\n",
+		},
+		{
+			name: "every field",
+			contact: domain.WaContactCard{
+				Name:         " Budi ",
+				Phones:       []string{"+62 812-3456-7890", "6281311112222"},
+				Emails:       []string{"budi@example.com"},
+				Organization: "Acme",
+			},
+			want: "BEGIN:VCARD\r\nVERSION:3.0\r\nN:;Budi;;;\r\nFN:Budi\r\nORG:Acme\r\n" +
+				"TEL;type=CELL;type=VOICE;waid=6281234567890:+62 812-3456-7890\r\n" +
+				"TEL;type=CELL;type=VOICE;waid=6281311112222:+6281311112222\r\n" +
+				"EMAIL;type=INTERNET:budi@example.com\r\nEND:VCARD\r\n",
+		},
+		{
+			name:    "escaped",
+			contact: domain.WaContactCard{Name: `Budi, Jr; A\B`, Phones: []string{"1"}, Organization: "Line\nbreak"},
+			want: "BEGIN:VCARD\r\nVERSION:3.0\r\nN:;Budi\\, Jr\\; A\\\\B;;;\r\nFN:Budi\\, Jr\\; A\\\\B\r\nORG:Line\\nbreak\r\n" +
+				"TEL;type=CELL;type=VOICE;waid=1:+1\r\nEND:VCARD\r\n",
+		},
+		{name: "no name", contact: domain.WaContactCard{Name: " ", Phones: []string{"1"}}, wantErr: true},
+		{name: "no phone", contact: domain.WaContactCard{Name: "Budi"}, wantErr: true},
+		{name: "phone without digits", contact: domain.WaContactCard{Name: "Budi", Phones: []string{"n/a"}}, wantErr: true},
+		{name: "invalid email", contact: domain.WaContactCard{Name: "Budi", Phones: []string{"1"}, Emails: []string{"budi"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newVcard(tt.contact)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("vCard\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFoldVcardLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{name: "short", line: "FN:Budi"},
+		{name: "exactly the limit", line: "ORG:" + strings.Repeat("a", vcardLineLength-4)},
+		{name: "one over the limit", line: "ORG:" + strings.Repeat("a", vcardLineLength-3)},
+		{name: "several lines", line: "NOTE:" + strings.Repeat("0123456789", 30)},
+		{name: "multi-byte characters", line: "ORG:" + strings.Repeat("é", 100)},
+		{name: "4 bytes characters", line: "ORG:" + strings.Repeat("😀", 50)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folded := foldVcardLine(tt.line)
+
+			lines := strings.Split(folded, "\r\n")
+			for i, line := range lines {
+				if len(line) > vcardLineLength {
+					t.Errorf("line %d is %d octets, want at most %d", i+1, len(line), vcardLineLength)
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a character: %q", i+1, line)
+				}
+				if i > 0 && !strings.HasPrefix(line, " ") {
+					t.Errorf("continuation line %d does not start with a space: %q", i+1, line)
+				}
+			}
+			if len(tt.line) <= vcardLineLength && len(lines) != 1 {
+				t.Errorf("folded a line of %d octets", len(tt.line))
+			}
+
+			if unfolded := unfoldVcard(folded); len(unfolded) != 1 || unfolded[0] != tt.line {
+				t.Errorf("unfolded to %q, want %q", unfolded, tt.line)
+			}
+		})
+	}
+}
+
+func TestEscapeVcard(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "plain", want: "plain"},
+		{value: "a,b;c", want: `a\,b\;c`},
+		{value: `back\slash`, want: `back\\slash`},
+		{value: "two\nlines", want: `two\nlines`},
+		{value: "two\r\nlines", want: `two\nlines`},
+		{value: `\n is not a line break`, want: `\\n is not a line break`},
+	}
+
+	for _, tt := range tests {
+		got := escapeVcard(tt.value)
+		if got != tt.want {
+			t.Errorf("escapeVcard(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+
+		want := strings.ReplaceAll(tt.value, "\r\n", "\n")
+		if back := unescapeVcard(got); back != want {
+			t.Errorf("unescapeVcard(%q) = %q, want %q", got, back, want)
+		}
+	}
+}
+
+func TestSplitVcards(t *testing.T) {
+	budi := "BEGIN:VCARD\r\nVERSION:3.0\r\nFN:Budi\r\nTEL:+1\r\nEND:VCARD\r\n"
+
+	tests := []struct {
+		name    string
+		raw     string
+		want    []string
+		wantErr bool
+	}{
+		{name: "one card", raw: budi, want: []string{"Budi"}},
+		{name: "several cards", raw: budi + "\r\n" + strings.Replace(budi, "Budi", "Sari", 1), want: []string{"Budi", "Sari"}},
+		{name: "byte order mark and LF", raw: string(rune(0xFEFF)) + strings.ReplaceAll(budi, "\r\n", "\n"), want: []string{"Budi"}},
+		{name: "lower case", raw: "begin:vcard\nversion:3.0\nfn:Budi\nend:vcard\n", want: []string{"Budi"}},
+		{name: "escaped name", raw: "BEGIN:VCARD\nFN:Budi\\, Jr\nEND:VCARD\n", want: []string{"Budi, Jr"}},
+		{name: "named by N", raw: "BEGIN:VCARD\nN:Santoso;Budi;;;\nEND:VCARD\n", want: []string{"Santoso Budi"}},
+		{name: "grouped property", raw: "BEGIN:VCARD\nitem1.FN:Budi\nEND:VCARD\n", want: []string{"Budi"}},
+		{name: "folded name", raw: "BEGIN:VCARD\nFN:Bu\n di\nEND:VCARD\n", want: []string{"Budi"}},
+		{name: "empty", raw: "", wantErr: true},
+		{name: "no card", raw: "FN:Budi\n", wantErr: true},
+		{name: "no end", raw: "BEGIN:VCARD\nFN:Budi\n", wantErr: true},
+		{name: "nested", raw: "BEGIN:VCARD\nBEGIN:VCARD\nFN:Budi\nEND:VCARD\n", wantErr: true},
+		{name: "no name", raw: "BEGIN:VCARD\nTEL:+1\nEND:VCARD\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cards, err := splitVcards(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+
+			var names []string
+			for _, card := range cards {
+				names = append(names, card.displayName)
+				if !strings.HasSuffix(card.vcard, "END:VCARD\r\n") && !strings.HasSuffix(card.vcard, "end:vcard\r\n") {
+					t.Errorf("card %q does not end with END:VCARD and CRLF", card.vcard)
+				}
+			}
+			if strings.Join(names, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("names %q, want %q", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestContactVcards(t *testing.T) {
+	raw := "BEGIN:VCARD\nFN:Budi\nEND:VCARD\nBEGIN:VCARD\nFN:Sari\nEND:VCARD\n"
+
+	tests := []struct {
+		name     string
+		contacts []domain.WaContactCard
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "structured and raw",
+			contacts: []domain.WaContactCard{{Name: "Agus", Phones: []string{"1"}}, {Vcard: raw}},
+			want:     []string{"Agus", "Budi", "Sari"},
+		},
+		{
+			name:     "raw card renamed",
+			contacts: []domain.WaContactCard{{Name: "Pak Budi", Vcard: "BEGIN:VCARD\nFN:Budi\nEND:VCARD\n"}},
+			want:     []string{"Pak Budi"},
+		},
+		{name: "none", wantErr: true},
+		{name: "invalid structured", contacts: []domain.WaContactCard{{Name: "Agus"}}, wantErr: true},
+		{name: "invalid raw", contacts: []domain.WaContactCard{{Vcard: "FN:Budi"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cards, err := contactVcards(tt.contacts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, domain.ErrInvalidContact) {
+				t.Errorf("error %v is not ErrInvalidContact", err)
+			}
+
+			var names []string
+			for _, card := range cards {
+				names = append(names, card.displayName)
+			}
+			if strings.Join(names, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("names %q, want %q", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestContactsArrayProto(t *testing.T) {
+	cards := []vcardContact{{displayName: "Budi", vcard: "A"}, {displayName: "Sari", vcard: "B"}}
+
+	info := contactsArrayProto("6281234567890@s.whatsapp.net", cards, whatsapp.ContextInfo{QuotedMessageID: "Q"})
+
+	message := info.GetMessage().GetContactsArrayMessage()
+	if got := message.GetDisplayName(); got != "2 contacts" {
+		t.Errorf("display name %q, want %q", got, "2 contacts")
+	}
+	if len(message.GetContacts()) != 2 || message.GetContacts()[1].GetDisplayName() != "Sari" || message.GetContacts()[1].GetVcard() != "B" {
+		t.Errorf("contacts %v, want the 2 cards in order", message.GetContacts())
+	}
+	if got := message.GetContextInfo().GetStanzaId(); got != "Q" {
+		t.Errorf("quoted message %q, want %q", got, "Q")
+	}
+	if !info.GetKey().GetFromMe() || info.GetKey().GetRemoteJid() != "6281234567890@s.whatsapp.net" || info.GetKey().GetId() == "" {
+		t.Errorf("key %v, want from me to the jid with an id", info.GetKey())
+	}
+
+	unquoted := contactsArrayProto("6281234567890@s.whatsapp.net", cards, whatsapp.ContextInfo{})
+	if unquoted.GetMessage().GetContactsArrayMessage().GetContextInfo() != nil {
+		t.Error("context info set without a quoted message")
+	}
+}
@@ -0,0 +1,460 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils"
+	"github.com/cooljar/go-whatsapp-fiber/utils/log"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	webhookBackoffMin  = 10 * time.Second
+	webhookBackoffMax  = time.Hour
+	webhookPollPeriod  = time.Second
+	webhookPollBatch   = 100
+	webhookErrorLength = 512
+)
+
+type webhookUsecase struct {
+	repo        domain.WebhookRepository
+	client      *http.Client
+	maxAttempts int
+	workers     int
+
+	// mu guards webhooks, the subscriptions are matched against every event
+	mu       sync.RWMutex
+	webhooks []domain.Webhook
+
+	// inFlightMu guards inFlight, the deliveries handed to a worker
+	inFlightMu sync.Mutex
+	inFlight   map[string]struct{}
+
+	wake chan struct{}
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+// NewWebhookUsecase creates the webhook dispatcher. Deliveries are retried
+// WEBHOOK_MAX_ATTEMPTS times (default to 10) by WEBHOOK_WORKERS workers
+// (default to 4), each request times out after WEBHOOK_TIMEOUT seconds
+// (default to 10).
+func NewWebhookUsecase(repo domain.WebhookRepository) (domain.WebhookUsecase, error) {
+	webhooks, err := repo.Fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhookUsecase{
+		repo:        repo,
+		client:      &http.Client{Timeout: time.Duration(utils.GetEnvInt("WEBHOOK_TIMEOUT", 10)) * time.Second},
+		maxAttempts: utils.GetEnvInt("WEBHOOK_MAX_ATTEMPTS", 10),
+		workers:     utils.GetEnvInt("WEBHOOK_WORKERS", 4),
+		webhooks:    webhooks,
+		inFlight:    make(map[string]struct{}),
+		wake:        make(chan struct{}, 1),
+		stop:        make(chan struct{}),
+	}, nil
+}
+
+func (u *webhookUsecase) Create(form domain.WebhookForm) (webhook domain.Webhook, err error) {
+	now := time.Now()
+	webhook = domain.Webhook{
+		ID:        utils.NewID(),
+		Active:    true,
+		CreatedAt: now,
+	}
+
+	err = applyWebhookForm(&webhook, form, now)
+	if err != nil {
+		return
+	}
+	if webhook.Secret == "" {
+		webhook.Secret = newWebhookSecret()
+	}
+
+	err = u.repo.Store(webhook)
+	if err != nil {
+		return
+	}
+
+	err = u.reload()
+
+	return
+}
+
+func (u *webhookUsecase) Update(id string, form domain.WebhookForm) (webhook domain.Webhook, err error) {
+	webhook, err = u.repo.GetByID(id)
+	if err != nil {
+		return
+	}
+
+	err = applyWebhookForm(&webhook, form, time.Now())
+	if err != nil {
+		return
+	}
+
+	err = u.repo.Update(webhook)
+	if err != nil {
+		return
+	}
+
+	err = u.reload()
+
+	return
+}
+
+func (u *webhookUsecase) Delete(id string) error {
+	err := u.repo.Delete(id)
+	if err != nil {
+		return err
+	}
+
+	return u.reload()
+}
+
+func (u *webhookUsecase) Get(id string) (domain.Webhook, error) {
+	return u.repo.GetByID(id)
+}
+
+func (u *webhookUsecase) Fetch() ([]domain.Webhook, error) {
+	return u.repo.Fetch()
+}
+
+func (u *webhookUsecase) Deliveries(filter domain.WebhookDeliveryFilter) (deliveries []domain.WebhookDelivery, meta domain.JSONResultMeta, err error) {
+	filter.Page, filter.PerPage = normalizePage(filter.Page, filter.PerPage)
+
+	deliveries, total, err := u.repo.FetchDeliveries(filter)
+	if err != nil {
+		return
+	}
+
+	meta = newResultMeta(total, filter.Page, filter.PerPage)
+
+	return
+}
+
+// Redeliver queues a delivery again, whatever its status.
+func (u *webhookUsecase) Redeliver(deliveryID string) (delivery domain.WebhookDelivery, err error) {
+	delivery, err = u.repo.GetDeliveryByID(deliveryID)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	delivery.Status = domain.WebhookDeliveryPending
+	delivery.NextAttemptAt = &now
+	delivery.UpdatedAt = now
+
+	err = u.repo.UpdateDelivery(delivery)
+	if err != nil {
+		return
+	}
+
+	u.notify()
+
+	return
+}
+
+func (u *webhookUsecase) HandleEvent(event domain.WaEvent) {
+	u.mu.RLock()
+	var webhooks []domain.Webhook
+	for _, webhook := range u.webhooks {
+		if webhook.Match(event) {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	u.mu.RUnlock()
+
+	if len(webhooks) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Println(log.LogLevelError, "webhook", err.Error())
+		return
+	}
+
+	now := time.Now()
+	for _, webhook := range webhooks {
+		err = u.repo.StoreDelivery(domain.WebhookDelivery{
+			ID:            utils.NewID(),
+			WebhookID:     webhook.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       string(payload),
+			Status:        domain.WebhookDeliveryPending,
+			NextAttemptAt: &now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+		if err != nil {
+			log.Println(log.LogLevelError, "webhook", webhook.ID+": "+err.Error())
+		}
+	}
+
+	u.notify()
+}
+
+func (u *webhookUsecase) Start() {
+	jobs := make(chan domain.WebhookDelivery)
+
+	for i := 0; i < u.workers; i++ {
+		u.done.Add(1)
+		go func() {
+			defer u.done.Done()
+
+			for delivery := range jobs {
+				u.deliver(delivery)
+			}
+		}()
+	}
+
+	u.done.Add(1)
+	go func() {
+		defer u.done.Done()
+		defer close(jobs)
+
+		ticker := time.NewTicker(webhookPollPeriod)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-u.stop:
+				return
+			case <-u.wake:
+			case <-ticker.C:
+			}
+
+			if !u.dispatchDue(jobs) {
+				return
+			}
+		}
+	}()
+}
+
+func (u *webhookUsecase) Shutdown(ctx context.Context) error {
+	close(u.stop)
+
+	done := make(chan struct{})
+	go func() {
+		u.done.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// dispatchDue hands the due deliveries to the workers, it returns false when
+// the dispatcher is stopped.
+func (u *webhookUsecase) dispatchDue(jobs chan<- domain.WebhookDelivery) bool {
+	deliveries, err := u.repo.FetchDueDeliveries(time.Now(), webhookPollBatch)
+	if err != nil {
+		log.Println(log.LogLevelError, "webhook", err.Error())
+		return true
+	}
+
+	for _, delivery := range deliveries {
+		u.inFlightMu.Lock()
+		_, busy := u.inFlight[delivery.ID]
+		if !busy {
+			u.inFlight[delivery.ID] = struct{}{}
+		}
+		u.inFlightMu.Unlock()
+
+		if busy {
+			continue
+		}
+
+		select {
+		case jobs <- delivery:
+		case <-u.stop:
+			u.release(delivery.ID)
+			return false
+		}
+	}
+
+	return true
+}
+
+// deliver posts the delivery once and records the outcome.
+func (u *webhookUsecase) deliver(delivery domain.WebhookDelivery) {
+	defer u.release(delivery.ID)
+
+	webhook, err := u.repo.GetByID(delivery.WebhookID)
+	if err != nil {
+		// The webhook was deleted with its deliveries
+		return
+	}
+
+	delivery.Attempts++
+	delivery.ResponseStatus, err = u.post(webhook, delivery)
+
+	now := time.Now()
+	delivery.UpdatedAt = now
+	delivery.NextAttemptAt = nil
+	delivery.LastError = ""
+
+	switch {
+	case err == nil:
+		delivery.Status = domain.WebhookDeliverySuccess
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= u.maxAttempts:
+		delivery.Status = domain.WebhookDeliveryFailed
+		delivery.LastError = err.Error()
+	default:
+		next := now.Add(webhookBackoff(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+		delivery.LastError = err.Error()
+	}
+
+	if len(delivery.LastError) > webhookErrorLength {
+		delivery.LastError = delivery.LastError[:webhookErrorLength]
+	}
+
+	err = u.repo.UpdateDelivery(delivery)
+	if err != nil && err != domain.ErrWebhookDeliveryNotFound {
+		log.Println(log.LogLevelError, "webhook", delivery.ID+": "+err.Error())
+	}
+}
+
+// post sends the payload to the webhook, signed with its secret. Any 2xx
+// response is a success.
+func (u *webhookUsecase) post(webhook domain.Webhook, delivery domain.WebhookDelivery) (status int, err error) {
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go-whatsapp-fiber-webhook")
+	req.Header.Set("X-Webhook-Id", webhook.ID)
+	req.Header.Set("X-Webhook-Delivery", delivery.ID)
+	req.Header.Set("X-Webhook-Event", string(delivery.EventType))
+	req.Header.Set("X-Webhook-Attempt", strconv.Itoa(delivery.Attempts))
+	if webhook.Secret != "" {
+		req.Header.Set("X-Webhook-Signature", "sha256="+WebhookSignature(webhook.Secret, []byte(delivery.Payload)))
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	// Drain a bit of the body so the connection can be reused
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	status = resp.StatusCode
+	if status < 200 || status > 299 {
+		err = fmt.Errorf("webhook responded with status %d", status)
+	}
+
+	return
+}
+
+func (u *webhookUsecase) release(deliveryID string) {
+	u.inFlightMu.Lock()
+	defer u.inFlightMu.Unlock()
+
+	delete(u.inFlight, deliveryID)
+}
+
+func (u *webhookUsecase) notify() {
+	select {
+	case u.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (u *webhookUsecase) reload() error {
+	webhooks, err := u.repo.Fetch()
+	if err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.webhooks = webhooks
+
+	return nil
+}
+
+// WebhookSignature returns the hex HMAC-SHA256 of payload with secret, it is
+// sent in the X-Webhook-Signature header as "sha256=<signature>".
+func WebhookSignature(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newWebhookSecret returns a random 64 characters hex signing secret.
+func newWebhookSecret() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+func applyWebhookForm(webhook *domain.Webhook, form domain.WebhookForm, now time.Time) error {
+	for _, eventType := range form.EventTypes {
+		if !eventType.Valid() {
+			return fmt.Errorf("%w: %s", domain.ErrInvalidEventType, eventType)
+		}
+	}
+
+	webhook.URL = form.URL
+	if form.Secret != "" {
+		// An update without a secret keeps the deliveries signed
+		webhook.Secret = form.Secret
+	}
+	webhook.SessionID = form.SessionID
+	webhook.EventTypes = form.EventTypes
+	webhook.Jids = form.Jids
+	if webhook.EventTypes == nil {
+		webhook.EventTypes = []domain.WaEventType{}
+	}
+	if webhook.Jids == nil {
+		webhook.Jids = []string{}
+	}
+	if form.Active != nil {
+		webhook.Active = *form.Active
+	}
+	webhook.UpdatedAt = now
+
+	return nil
+}
+
+// webhookBackoff is an exponential backoff from 10 seconds up to an hour.
+func webhookBackoff(attempt int) time.Duration {
+	if attempt > 20 {
+		return webhookBackoffMax
+	}
+
+	delay := webhookBackoffMin << uint(attempt-1)
+	if delay > webhookBackoffMax {
+		delay = webhookBackoffMax
+	}
+
+	return delay
+}
@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"errors"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils"
+	"github.com/cooljar/go-whatsapp-fiber/utils/log"
+	"sort"
+	"strings"
+)
+
+// Chats lists the chats of the phone, pinned first then by last message. The
+// chats query holds the archived and pinned flags, the last chat list sent by
+// whatsapp is used when it fails.
+func (w *whatsappUsecase) Chats(filter domain.WaChatFilter) (chats []domain.WaChat, meta domain.JSONResultMeta, err error) {
+	if w.conn().GetConnected() == false || w.conn().GetLoggedIn() == false {
+		err = errors.New("invalid session, please login")
+		return
+	}
+
+	filter.Page, filter.PerPage = normalizePage(filter.Page, filter.PerPage)
+
+	var all []domain.WaChat
+	node, err := w.conn().Chats()
+	if err == nil {
+		all = utils.NewWaChatsFromNode(w.sessionID, node)
+	} else {
+		log.Println(log.LogLevelWarn, "whatsapp-chats", w.sessionID+": chats query failed, "+err.Error())
+		err = nil
+
+		w.chatsMu.Lock()
+		all = append(all, w.chats...)
+		w.chatsMu.Unlock()
+	}
+
+	chats = []domain.WaChat{}
+	query := strings.ToLower(filter.Query)
+	for _, chat := range all {
+		if chat.Name == "" && w.contacts != nil {
+			chat.Name = w.contacts.DisplayName(w.sessionID, chat.Jid)
+		}
+
+		if filter.Archived != nil && chat.Archived != *filter.Archived {
+			continue
+		}
+		if filter.Unread && chat.UnreadCount == 0 {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(chat.Jid), query) && !strings.Contains(strings.ToLower(chat.Name), query) {
+			continue
+		}
+
+		chats = append(chats, chat)
+	}
+
+	sort.SliceStable(chats, func(i, j int) bool {
+		if chats[i].Pinned != chats[j].Pinned {
+			return chats[i].Pinned
+		}
+		if chats[i].LastMessageAt == nil || chats[j].LastMessageAt == nil {
+			return chats[j].LastMessageAt == nil && chats[i].LastMessageAt != nil
+		}
+
+		return chats[i].LastMessageAt.After(*chats[j].LastMessageAt)
+	})
+
+	meta = newResultMeta(len(chats), filter.Page, filter.PerPage)
+
+	start := (filter.Page - 1) * filter.PerPage
+	if start > len(chats) {
+		start = len(chats)
+	}
+	end := start + filter.PerPage
+	if end > len(chats) {
+		end = len(chats)
+	}
+	chats = chats[start:end]
+
+	// Only the chats of the page get their last message
+	for i := range chats {
+		chats[i].LastMessage = w.lastMessage(chats[i].Jid)
+	}
+
+	return
+}
+
+// handleChats keeps the chat list sent by whatsapp, for when the chats query
+// fails.
+func (w *whatsappUsecase) handleChats(chats []domain.WaChat) {
+	w.chatsMu.Lock()
+	w.chats = chats
+	w.chatsMu.Unlock()
+}
+
+// lastMessage returns the latest stored message of the chat jid, nil when
+// none is stored.
+func (w *whatsappUsecase) lastMessage(jid string) *domain.WaMessage {
+	if w.messages == nil {
+		return nil
+	}
+
+	messages, _, err := w.messages.Fetch(domain.WaMessageFilter{SessionID: w.sessionID, Jid: jid, Page: 1, PerPage: 1})
+	if err != nil {
+		log.Println(log.LogLevelError, "whatsapp-chats", w.sessionID+": "+err.Error())
+		return nil
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+
+	return &messages[0]
+}
+
+func (w *whatsappUsecase) ChatMessages(jid, before string, count int) (messages []domain.WaMessage, meta domain.WaChatHistoryMeta, err error) {
+	if w.conn().GetConnected() == false || w.conn().GetLoggedIn() == false {
+		err = errors.New("invalid session, please login")
+		return
+	}
+
+	_, count = normalizePage(1, count)
+
+	node, err := w.conn().LoadMessagesBefore(parseMsisdn(jid), before, count)
+	if err != nil {
+		return
+	}
+
+	// Loaded oldest first, listed newest first like the message store
+	messages = utils.NewWaMessagesFromNode(w.sessionID, node)
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	for i := range messages {
+		messages[i] = w.resolveNames(messages[i])
+	}
+
+	meta.Count = len(messages)
+	if len(messages) > 0 {
+		meta.Before = messages[len(messages)-1].ID
+	}
+
+	return
+}
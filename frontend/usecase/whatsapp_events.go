@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils"
+	"github.com/cooljar/go-whatsapp-fiber/utils/log"
+	"strings"
+	"time"
+)
+
+// seenMessagesSize is the number of message ids remembered to drop the
+// messages whatsapp replays after a reconnect.
+const seenMessagesSize = 1024
+
+type seenMessages struct {
+	ids  map[string]struct{}
+	ring []string
+	next int
+}
+
+// add remembers id and reports whether it was new.
+func (s *seenMessages) add(id string) bool {
+	if s.ids == nil {
+		s.ids = make(map[string]struct{}, seenMessagesSize)
+		s.ring = make([]string, seenMessagesSize)
+	}
+	if _, ok := s.ids[id]; ok {
+		return false
+	}
+
+	delete(s.ids, s.ring[s.next])
+	s.ring[s.next] = id
+	s.next = (s.next + 1) % seenMessagesSize
+	s.ids[id] = struct{}{}
+
+	return true
+}
+
+// handleEvent publishes the events received by the session, each message once.
+func (w *whatsappUsecase) handleEvent(event domain.WaEvent) {
+	if w.events == nil {
+		return
+	}
+
+	if message, ok := event.Data.(domain.WaMessage); ok {
+		w.seenMu.Lock()
+		isNew := w.seen.add(message.ID)
+		w.seenMu.Unlock()
+
+		if !isNew {
+			return
+		}
+
+		event.Data = w.resolveNames(message)
+	}
+
+	w.events.Publish(event)
+}
+
+// handleMedia stores the media of a received message.
+func (w *whatsappUsecase) handleMedia(message *domain.WaMessage, size uint64, download func() ([]byte, error)) {
+	if w.media == nil {
+		return
+	}
+
+	media, err := w.media.Save(message.Type, message.MimeType, message.FileName, size, download)
+	if err != nil {
+		log.Println(log.LogLevelWarn, "whatsapp-media", w.sessionID+": message "+message.ID+", "+err.Error())
+		return
+	}
+
+	message.MediaID = media.ID
+}
+
+// publishConnection publishes the connection status of the session.
+func (w *whatsappUsecase) publishConnection() {
+	w.handleEvent(domain.WaEvent{
+		ID:        utils.NewID(),
+		Type:      domain.WaEventConnection,
+		SessionID: w.sessionID,
+		Timestamp: time.Now(),
+		Data:      w.ConnectionStatus(),
+	})
+}
+
+// recordSent stores a message sent by the session.
+func (w *whatsappUsecase) recordSent(message domain.WaMessage) {
+	if w.messages == nil {
+		return
+	}
+
+	message.SessionID = w.sessionID
+	message.FromMe = true
+	message.Direction = domain.WaMessageOutbound
+	message.Timestamp = time.Now()
+	message = w.resolveNames(message)
+
+	err := w.messages.Store(message)
+	if err != nil {
+		log.Println(log.LogLevelError, "message-store", w.sessionID+": "+err.Error())
+	}
+
+	// A receipt may have been stored already, it is kept
+	err = w.messages.StoreStatus(domain.WaMessageStatus{
+		SessionID: w.sessionID,
+		MessageID: message.ID,
+		Jid:       message.Jid,
+		Status:    domain.WaAckPending,
+		PendingAt: message.Timestamp,
+		UpdatedAt: message.Timestamp,
+	})
+	if err != nil {
+		log.Println(log.LogLevelError, "message-status", w.sessionID+": "+err.Error())
+	}
+}
+
+// handleAck moves the sent messages of a receipt forward and publishes their
+// new status. The messages sent from the phone are tracked from their first
+// receipt.
+func (w *whatsappUsecase) handleAck(ack utils.WaAck) {
+	if w.messages == nil {
+		return
+	}
+
+	w.ackMu.Lock()
+	defer w.ackMu.Unlock()
+
+	for _, id := range ack.IDs {
+		status, err := w.messages.GetStatus(w.sessionID, id)
+		if err == domain.ErrMessageStatusNotFound {
+			status = domain.WaMessageStatus{
+				SessionID: w.sessionID,
+				MessageID: id,
+				Jid:       ack.Jid,
+				Status:    domain.WaAckPending,
+				PendingAt: ack.Timestamp,
+			}
+		} else if err != nil {
+			log.Println(log.LogLevelError, "message-status", w.sessionID+": "+err.Error())
+			continue
+		}
+
+		if !status.Advance(ack.Ack, ack.Timestamp) {
+			continue
+		}
+
+		err = w.messages.UpdateStatus(status)
+		if err != nil {
+			log.Println(log.LogLevelError, "message-status", w.sessionID+": "+err.Error())
+			continue
+		}
+
+		w.handleEvent(domain.WaEvent{
+			ID:        utils.NewID(),
+			Type:      domain.WaEventStatus,
+			SessionID: w.sessionID,
+			Jid:       status.Jid,
+			Timestamp: ack.Timestamp,
+			Data:      status,
+		})
+	}
+}
+
+// resolveNames sets the contact and chat names of message from the contact
+// directory, the push name of a received message is recorded first.
+func (w *whatsappUsecase) resolveNames(message domain.WaMessage) domain.WaMessage {
+	if w.contacts == nil {
+		return message
+	}
+
+	contact := message.Jid
+	if !message.FromMe {
+		contact = messageContact(message)
+		if message.PushName != "" {
+			w.handleContacts([]domain.WaContact{{SessionID: w.sessionID, Jid: contact, Notify: message.PushName}})
+		}
+	}
+
+	if strings.HasSuffix(message.Jid, "@g.us") {
+		message.ChatName = w.contacts.DisplayName(w.sessionID, message.Jid)
+		if message.FromMe {
+			// Sent to the whole group
+			return message
+		}
+	}
+	message.ContactName = w.contacts.DisplayName(w.sessionID, contact)
+
+	return message
+}
+
+// handleContacts merges contacts in the contact directory.
+func (w *whatsappUsecase) handleContacts(contacts []domain.WaContact) {
+	if w.contacts == nil {
+		return
+	}
+
+	err := w.contacts.Save(contacts)
+	if err != nil {
+		log.Println(log.LogLevelError, "whatsapp-contacts", w.sessionID+": "+err.Error())
+	}
+}
+
+// syncContacts fills the contact directory with the contacts query, which
+// also tells the business accounts. The contacts and chats sent by whatsapp
+// on connect come through the session handler.
+func (w *whatsappUsecase) syncContacts() {
+	if w.contacts == nil {
+		return
+	}
+
+	node, err := w.conn().Contacts()
+	if err != nil {
+		log.Println(log.LogLevelWarn, "whatsapp-contacts", w.sessionID+": contacts query failed, "+err.Error())
+		return
+	}
+
+	w.handleContacts(utils.NewWaContactsFromNode(w.sessionID, node))
+}
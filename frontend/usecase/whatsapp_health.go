@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"github.com/Rhymen/go-whatsapp"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils"
+	"time"
+)
+
+// phoneHealth is what the session knows about the phone behind it
+type phoneHealth struct {
+	lastPingAt    *time.Time
+	lastPingError string
+	battery       *domain.WaBattery
+}
+
+func (w *whatsappUsecase) Status() domain.WaStatus {
+	conn := w.conn()
+
+	status := domain.WaStatus{
+		SessionID:  w.sessionID,
+		Connection: w.ConnectionStatus(),
+		Connected:  conn.GetConnected(),
+		LoggedIn:   conn.GetLoggedIn(),
+	}
+
+	if conn.Info != nil {
+		status.Jid = conn.Info.Wid
+		status.PushName = conn.Info.Pushname
+	}
+
+	w.healthMu.Lock()
+	defer w.healthMu.Unlock()
+
+	status.LastPingAt = w.health.lastPingAt
+	status.LastPingError = w.health.lastPingError
+	status.Battery = w.health.battery
+
+	// Until the phone reports a change the battery is the one sent at login
+	if status.Battery == nil && conn.Info != nil && status.LoggedIn {
+		status.Battery = &domain.WaBattery{Percentage: conn.Info.Battery, Plugged: conn.Info.Plugged}
+	}
+
+	return status
+}
+
+func (w *whatsappUsecase) handleBattery(message whatsapp.BatteryMessage) {
+	w.healthMu.Lock()
+	defer w.healthMu.Unlock()
+
+	w.health.battery = &domain.WaBattery{
+		Percentage: message.Percentage,
+		Plugged:    message.Plugged,
+		Powersave:  message.Powersave,
+		UpdatedAt:  time.Now(),
+	}
+}
+
+// pingLoop pings the phone of a logged in session every
+// WHATSAPP_PING_INTERVAL seconds, default to 60.
+func (w *whatsappUsecase) pingLoop() {
+	ticker := time.NewTicker(time.Duration(utils.GetEnvInt("WHATSAPP_PING_INTERVAL", 60)) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.stop:
+			return
+		}
+
+		conn := w.conn()
+		if !conn.GetConnected() || !conn.GetLoggedIn() {
+			continue
+		}
+
+		err := testPing(w)
+
+		w.healthMu.Lock()
+		if err != nil {
+			w.health.lastPingError = err.Error()
+		} else {
+			now := time.Now()
+			w.health.lastPingAt = &now
+			w.health.lastPingError = ""
+		}
+		w.healthMu.Unlock()
+	}
+}
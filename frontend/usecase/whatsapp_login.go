@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"github.com/Rhymen/go-whatsapp"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils"
+	"github.com/cooljar/go-whatsapp-fiber/utils/log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxLoginAttempts is how many finished login attempts a session remembers
+const maxLoginAttempts = 10
+
+// loginAttempt tracks a login and fans its updates out to the subscribers.
+type loginAttempt struct {
+	mu   sync.Mutex
+	data domain.WaLoginAttempt
+	subs map[chan domain.WaLoginAttempt]struct{}
+}
+
+func newLoginAttempt(sessionID string, timeout time.Duration) *loginAttempt {
+	now := time.Now()
+
+	return &loginAttempt{
+		data: domain.WaLoginAttempt{
+			ID:        utils.NewID(),
+			SessionID: sessionID,
+			Status:    domain.WaLoginPending,
+			CreatedAt: now,
+			UpdatedAt: now,
+			ExpiresAt: now.Add(timeout),
+		},
+		subs: make(map[chan domain.WaLoginAttempt]struct{}),
+	}
+}
+
+func (a *loginAttempt) snapshot() domain.WaLoginAttempt {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.data
+}
+
+// update applies fn and pushes the result to the subscribers. Slow subscribers
+// miss intermediate updates, the channels are closed once the attempt is done.
+func (a *loginAttempt) update(fn func(data *domain.WaLoginAttempt)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	fn(&a.data)
+	a.data.UpdatedAt = time.Now()
+
+	for sub := range a.subs {
+		select {
+		case sub <- a.data:
+		default:
+		}
+
+		if a.data.Done() {
+			close(sub)
+			delete(a.subs, sub)
+		}
+	}
+}
+
+func (a *loginAttempt) subscribe() (<-chan domain.WaLoginAttempt, func()) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	sub := make(chan domain.WaLoginAttempt, 8)
+	sub <- a.data
+	if a.data.Done() {
+		close(sub)
+		return sub, func() {}
+	}
+
+	a.subs[sub] = struct{}{}
+
+	return sub, func() {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+
+		if _, ok := a.subs[sub]; ok {
+			delete(a.subs, sub)
+			close(sub)
+		}
+	}
+}
+
+func (w *whatsappUsecase) LoginAttempt(id string) (domain.WaLoginAttempt, error) {
+	a, err := w.loginAttempt(id)
+	if err != nil {
+		return domain.WaLoginAttempt{}, err
+	}
+
+	return a.snapshot(), nil
+}
+
+func (w *whatsappUsecase) SubscribeLoginAttempt(id string) (<-chan domain.WaLoginAttempt, func(), error) {
+	a, err := w.loginAttempt(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	updates, cancel := a.subscribe()
+
+	return updates, cancel, nil
+}
+
+func (w *whatsappUsecase) loginAttempt(id string) (*loginAttempt, error) {
+	w.loginMu.Lock()
+	defer w.loginMu.Unlock()
+
+	for _, a := range w.loginAttempts {
+		if a.data.ID == id {
+			return a, nil
+		}
+	}
+
+	return nil, domain.ErrLoginAttemptNotFound
+}
+
+func (w *whatsappUsecase) addLoginAttempt(a *loginAttempt) {
+	w.loginMu.Lock()
+	defer w.loginMu.Unlock()
+
+	w.loginAttempts = append(w.loginAttempts, a)
+	if len(w.loginAttempts) > maxLoginAttempts {
+		w.loginAttempts = w.loginAttempts[len(w.loginAttempts)-maxLoginAttempts:]
+	}
+}
+
+// runLogin logs conn in, asking for a new QR code each time the previous one
+// expires until the attempt itself expires. first is closed once the first QR
+// code is known or the attempt failed before showing one.
+func (w *whatsappUsecase) runLogin(conn *whatsapp.Conn, a *loginAttempt, first chan struct{}) {
+	var firstOnce sync.Once
+	signalFirst := func() { firstOnce.Do(func() { close(first) }) }
+	defer signalFirst()
+
+	for {
+		qr := make(chan string, 1)
+		done := make(chan struct{})
+		go func() {
+			select {
+			case code := <-qr:
+				a.update(func(data *domain.WaLoginAttempt) {
+					data.QrCode = code
+					data.QrCount++
+				})
+				signalFirst()
+			case <-done:
+			}
+		}()
+
+		session, err := conn.Login(qr)
+		close(done)
+
+		if err == nil {
+			log.Println(log.LogLevelInfo, "login successful, session:", w.sessionID)
+
+			//save session
+			err = w.sessionStore.Write(w.sessionID, session)
+			if err != nil {
+				log.Println(log.LogLevelError, "error during login:", err)
+				a.update(func(data *domain.WaLoginAttempt) {
+					data.Status = domain.WaLoginFailed
+					data.QrCode = ""
+					data.Error = err.Error()
+				})
+				return
+			}
+
+			// A new pairing starts the warm-up of the number
+			if w.limiter != nil {
+				if err = w.limiter.Paired(w.sessionID); err != nil {
+					log.Println(log.LogLevelError, "error during login:", err)
+				}
+			}
+
+			w.setConnected()
+			a.update(func(data *domain.WaLoginAttempt) {
+				data.Status = domain.WaLoginSuccess
+				data.QrCode = ""
+				data.Jid = session.Wid
+			})
+			return
+		}
+
+		log.Println(log.LogLevelError, "error during login:", err)
+
+		if strings.Contains(err.Error(), "qr code scan timed out") {
+			if time.Now().Before(a.snapshot().ExpiresAt) {
+				continue
+			}
+
+			a.update(func(data *domain.WaLoginAttempt) {
+				data.Status = domain.WaLoginTimeout
+				data.QrCode = ""
+				data.Error = err.Error()
+			})
+			return
+		}
+
+		a.update(func(data *domain.WaLoginAttempt) {
+			data.Status = domain.WaLoginFailed
+			data.QrCode = ""
+			data.Error = err.Error()
+		})
+		return
+	}
+}
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"errors"
+	"github.com/Rhymen/go-whatsapp"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"time"
+)
+
+func (w *whatsappUsecase) MarkRead(jid, messageID string) (string, error) {
+	if w.conn().GetConnected() == false || w.conn().GetLoggedIn() == false {
+		return "", errors.New("invalid session, please login")
+	}
+
+	jid = parseMsisdn(jid)
+	if messageID == "" {
+		var err error
+		messageID, err = w.lastReceivedID(jid)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	_, err := w.conn().Read(jid, messageID)
+	if err != nil {
+		return "", err
+	}
+
+	return messageID, nil
+}
+
+// lastReceivedID returns the id of the latest message received in the chat
+// jid, stored or loaded from the phone. The read receipt is sent for a
+// message of the contact, marking our own messages read clears nothing.
+func (w *whatsappUsecase) lastReceivedID(jid string) (string, error) {
+	if w.messages != nil {
+		messages, _, err := w.messages.Fetch(domain.WaMessageFilter{SessionID: w.sessionID, Jid: jid, Direction: domain.WaMessageInbound, Page: 1, PerPage: 1})
+		if err == nil && len(messages) > 0 {
+			return messages[0].ID, nil
+		}
+	}
+
+	messages, _, err := w.ChatMessages(jid, "", maxPerPage)
+	if err != nil {
+		return "", err
+	}
+	for _, message := range messages {
+		if !message.FromMe {
+			return message.ID, nil
+		}
+	}
+
+	return "", domain.ErrChatEmpty
+}
+
+func (w *whatsappUsecase) SetPresence(presence domain.WaPresence) error {
+	if !presence.Valid() {
+		return domain.ErrInvalidPresence
+	}
+	if w.conn().GetConnected() == false || w.conn().GetLoggedIn() == false {
+		return errors.New("invalid session, please login")
+	}
+
+	_, err := w.conn().Presence("", whatsapp.Presence(presence))
+
+	return err
+}
+
+func (w *whatsappUsecase) SendChatState(jid string, state domain.WaChatState) error {
+	if !state.Valid() {
+		return domain.ErrInvalidChatState
+	}
+	if w.conn().GetConnected() == false || w.conn().GetLoggedIn() == false {
+		return errors.New("invalid session, please login")
+	}
+
+	_, err := w.conn().Presence(parseMsisdn(jid), whatsapp.Presence(state))
+
+	return err
+}
+
+// typing shows the session composing in the chat jid for d, then paused. It
+// stops early when the session shuts down.
+func (w *whatsappUsecase) typing(jid string, d time.Duration) error {
+	_, err := w.conn().Presence(jid, whatsapp.PresenceComposing)
+	if err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-w.stop:
+		return domain.ErrShuttingDown
+	}
+
+	_, err = w.conn().Presence(jid, whatsapp.PresencePaused)
+
+	return err
+}
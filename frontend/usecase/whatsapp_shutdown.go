@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils/log"
+)
+
+// beginSend registers an outgoing message, Shutdown waits for the registered
+// messages. It fails once the session is shutting down.
+func (w *whatsappUsecase) beginSend() error {
+	w.sendMu.Lock()
+	defer w.sendMu.Unlock()
+
+	if w.closing {
+		return domain.ErrShuttingDown
+	}
+	w.sends.Add(1)
+
+	return nil
+}
+
+func (w *whatsappUsecase) endSend() {
+	w.sends.Done()
+}
+
+// StopSending stops accepting messages and wakes the ones waiting for a rate
+// limiter slot, they fail with domain.ErrShuttingDown. The connection is kept
+// for the messages being sent.
+func (w *whatsappUsecase) StopSending() {
+	w.sendMu.Lock()
+	defer w.sendMu.Unlock()
+
+	if !w.closing {
+		w.closing = true
+		close(w.stop)
+	}
+}
+
+// Shutdown stops accepting messages, waits for the ones being sent until ctx
+// is done, saves the session and closes the connection. The session is not
+// logged out, it is restored on the next start.
+func (w *whatsappUsecase) Shutdown(ctx context.Context) error {
+	w.StopSending()
+
+	w.sendMu.Lock()
+	if w.shutdown {
+		w.sendMu.Unlock()
+		return nil
+	}
+	w.shutdown = true
+	w.sendMu.Unlock()
+
+	// Keep the supervisor from reconnecting the connection closed below
+	w.setState(domain.WaStateDisconnected, nil)
+
+	done := make(chan struct{})
+	go func() {
+		w.sends.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Println(log.LogLevelWarn, "whatsapp-shutdown", w.sessionID+": messages still being sent at the shutdown deadline")
+	}
+
+	conn := w.conn()
+	if !conn.GetConnected() {
+		return nil
+	}
+	loggedIn := conn.GetLoggedIn()
+
+	session, err := conn.Disconnect()
+	if err != nil {
+		return err
+	}
+
+	if loggedIn {
+		err = w.sessionStore.Write(w.sessionID, session)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println(log.LogLevelInfo, "whatsapp-shutdown", w.sessionID+": disconnected")
+
+	return nil
+}
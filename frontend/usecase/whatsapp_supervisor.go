@@ -0,0 +1,286 @@
+package usecase
+
+import (
+	"errors"
+	"github.com/Rhymen/go-whatsapp"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils"
+	"github.com/cooljar/go-whatsapp-fiber/utils/log"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// errReconnectCanceled is returned by reconnect when a login, logout or
+// shutdown stopped the reconnect loop during the attempt
+var errReconnectCanceled = errors.New("reconnect canceled")
+
+const (
+	reconnectBackoffMin = time.Second
+	reconnectBackoffMax = 5 * time.Minute
+)
+
+func (w *whatsappUsecase) conn() *whatsapp.Conn {
+	w.connMu.RLock()
+	defer w.connMu.RUnlock()
+
+	return w.whatsappConn
+}
+
+// swapConn replaces the session connection and returns the previous one.
+func (w *whatsappUsecase) swapConn(conn *whatsapp.Conn) *whatsapp.Conn {
+	w.connMu.Lock()
+	defer w.connMu.Unlock()
+
+	old := w.whatsappConn
+	w.whatsappConn = conn
+
+	return old
+}
+
+// handler returns the whatsapp handler of the session, wired to the supervisor.
+func (w *whatsappUsecase) handler() utils.WhatsappHandler {
+	return utils.WhatsappHandler{
+		SessionID:    w.sessionID,
+		Since:        w.startedAt,
+		OnDisconnect: w.handleDisconnect,
+		OnBattery:    w.handleBattery,
+		OnEvent:      w.handleEvent,
+		OnMedia:      w.handleMedia,
+		OnContacts:   w.handleContacts,
+		OnChats:      w.handleChats,
+		OnAck:        w.handleAck,
+	}
+}
+
+func (w *whatsappUsecase) ConnectionStatus() domain.WaConnectionStatus {
+	w.stateMu.Lock()
+	defer w.stateMu.Unlock()
+
+	return w.status
+}
+
+func (w *whatsappUsecase) setMaxReconnect(max int) {
+	w.stateMu.Lock()
+	defer w.stateMu.Unlock()
+
+	w.status.MaxReconnectAttempts = max
+}
+
+func (w *whatsappUsecase) setConnected() {
+	w.stateMu.Lock()
+	w.markConnected()
+	w.stateMu.Unlock()
+
+	w.publishConnection()
+
+	go w.syncContacts()
+}
+
+// markConnected marks the session connected, w.stateMu must be held.
+func (w *whatsappUsecase) markConnected() {
+	w.status.State = domain.WaStateConnected
+	w.status.ReconnectAttempts = 0
+	w.status.LastError = ""
+	w.status.DisconnectedAt = nil
+	w.status.NextAttemptAt = nil
+}
+
+func (w *whatsappUsecase) setState(state domain.WaConnectionState, err error) {
+	w.stateMu.Lock()
+	w.status.State = state
+	w.status.NextAttemptAt = nil
+	if err != nil {
+		w.status.LastError = err.Error()
+	}
+	w.stateMu.Unlock()
+
+	w.publishConnection()
+}
+
+// setStateIfReconnecting sets state only while the session is reconnecting, a
+// login, logout or shutdown during a reconnect attempt wins over its result.
+func (w *whatsappUsecase) setStateIfReconnecting(state domain.WaConnectionState, err error) bool {
+	w.stateMu.Lock()
+	if w.status.State != domain.WaStateReconnecting {
+		w.stateMu.Unlock()
+		return false
+	}
+	w.status.State = state
+	w.status.NextAttemptAt = nil
+	if err != nil {
+		w.status.LastError = err.Error()
+	}
+	w.stateMu.Unlock()
+
+	w.publishConnection()
+
+	return true
+}
+
+// handleDisconnect starts the reconnect loop when a connected session drops.
+// Disconnects of a session that is not connected (logged out, already
+// reconnecting) are ignored.
+func (w *whatsappUsecase) handleDisconnect(err error) {
+	w.stateMu.Lock()
+	if w.status.State != domain.WaStateConnected {
+		w.stateMu.Unlock()
+		return
+	}
+
+	w.setReconnecting(err)
+	w.stateMu.Unlock()
+
+	w.publishConnection()
+
+	log.Println(log.LogLevelWarn, "whatsapp-supervisor", w.sessionID+": connection lost, "+err.Error())
+
+	go w.reconnectLoop()
+}
+
+// setReconnecting marks the session reconnecting from now, w.stateMu must be
+// held.
+func (w *whatsappUsecase) setReconnecting(err error) {
+	now := time.Now()
+	w.status.State = domain.WaStateReconnecting
+	w.status.ReconnectAttempts = 0
+	w.status.LastError = err.Error()
+	w.status.DisconnectedAt = &now
+}
+
+// isLoggedOut reports whether err tells the phone removed the session, retrying
+// can not help then.
+func isLoggedOut(err error) bool {
+	return err == domain.ErrSessionNotStored || strings.Contains(err.Error(), "responded with 401")
+}
+
+func (w *whatsappUsecase) reconnectLoop() {
+	for attempt := 1; ; attempt++ {
+		w.stateMu.Lock()
+		if w.status.State != domain.WaStateReconnecting {
+			// Logged out or logged in again while waiting
+			w.stateMu.Unlock()
+			return
+		}
+		if attempt > w.status.MaxReconnectAttempts {
+			w.status.State = domain.WaStateDisconnected
+			w.status.NextAttemptAt = nil
+			w.stateMu.Unlock()
+
+			w.publishConnection()
+			log.Println(log.LogLevelError, "whatsapp-supervisor", w.sessionID+": giving up reconnecting, please login")
+			return
+		}
+
+		delay := reconnectBackoff(attempt)
+		next := time.Now().Add(delay)
+		w.status.ReconnectAttempts = attempt
+		w.status.NextAttemptAt = &next
+		w.stateMu.Unlock()
+
+		if !w.waitReconnect(delay) {
+			return
+		}
+
+		err := w.reconnect()
+		if err == nil {
+			w.publishConnection()
+			go w.syncContacts()
+			log.Println(log.LogLevelInfo, "whatsapp-supervisor", w.sessionID+": reconnected")
+			return
+		}
+		if err == errReconnectCanceled {
+			return
+		}
+
+		if isLoggedOut(err) {
+			if w.setStateIfReconnecting(domain.WaStateLoggedOut, err) {
+				log.Println(log.LogLevelError, "whatsapp-supervisor", w.sessionID+": session logged out, "+err.Error())
+			}
+			return
+		}
+
+		if !w.setStateIfReconnecting(domain.WaStateReconnecting, err) {
+			return
+		}
+		log.Println(log.LogLevelWarn, "whatsapp-supervisor", w.sessionID+": reconnect failed, "+err.Error())
+	}
+}
+
+// waitReconnect waits delay before the next reconnect attempt. It reports
+// whether the session is still reconnecting then, a shutdown ends the wait.
+func (w *whatsappUsecase) waitReconnect(delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-w.stop:
+		return false
+	}
+
+	w.stateMu.Lock()
+	defer w.stateMu.Unlock()
+
+	return w.status.State == domain.WaStateReconnecting
+}
+
+// reconnect restores the stored session on a new connection and swaps it in,
+// the session is connected then. It fails with errReconnectCanceled when the
+// session stopped reconnecting meanwhile, the new connection is closed then.
+func (w *whatsappUsecase) reconnect() error {
+	session, err := w.sessionStore.Read(w.sessionID)
+	if err != nil {
+		return err
+	}
+
+	conn, err := w.newConn()
+	if err != nil {
+		return err
+	}
+
+	session, err = conn.RestoreWithSession(session)
+	if err != nil {
+		_, _ = conn.Disconnect()
+		return err
+	}
+
+	err = w.sessionStore.Write(w.sessionID, session)
+	if err != nil {
+		log.Println(log.LogLevelError, "whatsapp-supervisor", w.sessionID+": "+err.Error())
+	}
+
+	w.stateMu.Lock()
+	if w.status.State != domain.WaStateReconnecting {
+		w.stateMu.Unlock()
+
+		// Closed before its handler is added, it must not look like a drop
+		_, _ = conn.Disconnect()
+		return errReconnectCanceled
+	}
+	old := w.swapConn(conn)
+	w.markConnected()
+	w.stateMu.Unlock()
+
+	conn.AddHandler(w.handler())
+
+	if old != nil {
+		_, _ = old.Disconnect()
+	}
+
+	return nil
+}
+
+// reconnectBackoff is an exponential backoff with equal jitter: half of the
+// delay is fixed and the other half random.
+func reconnectBackoff(attempt int) time.Duration {
+	delay := reconnectBackoffMax
+	if attempt < 20 {
+		delay = reconnectBackoffMin << uint(attempt-1)
+		if delay > reconnectBackoffMax {
+			delay = reconnectBackoffMax
+		}
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
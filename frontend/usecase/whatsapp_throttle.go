@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"github.com/cooljar/go-whatsapp-fiber/utils/log"
+	"time"
+)
+
+// throttle takes a rate limiter slot to send a message to jid and waits for
+// it. It fails with a *domain.WaRateLimitError when no slot is left. Once the
+// message is sent or failed, done must be called with the error of the send so
+// only the messages sent count in the daily quota.
+func (w *whatsappUsecase) throttle(jid string) (done func(err error), err error) {
+	if w.limiter == nil {
+		return func(error) {}, nil
+	}
+
+	delay, err := w.limiter.Reserve(w.sessionID, jid)
+	if err != nil {
+		return nil, err
+	}
+
+	done = func(err error) {
+		if err := w.limiter.Done(w.sessionID, err == nil); err != nil {
+			log.Println(log.LogLevelError, "rate-limit", w.sessionID+": "+err.Error())
+		}
+	}
+	if delay <= 0 {
+		return done, nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return done, nil
+	case <-w.stop:
+		done(domain.ErrShuttingDown)
+		return nil, domain.ErrShuttingDown
+	}
+}
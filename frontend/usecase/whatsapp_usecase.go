@@ -1,7 +1,6 @@
 package usecase
 
 import (
-	"encoding/gob"
 	"errors"
 	"fmt"
 	"github.com/Rhymen/go-whatsapp"
@@ -9,26 +8,89 @@ import (
 	"github.com/cooljar/go-whatsapp-fiber/domain"
 	"github.com/cooljar/go-whatsapp-fiber/utils"
 	"github.com/cooljar/go-whatsapp-fiber/utils/log"
-	"os"
 	"strings"
+	"sync"
 	"time"
 )
 
 type whatsappUsecase struct {
+	sessionID    string
+	sessionStore domain.SessionStore
+	newConn      func() (*whatsapp.Conn, error)
+	events       domain.WaEventBus
+	messages     domain.WaMessageRepository
+	media        domain.WaMediaUsecase
+	contacts     domain.WaContactUsecase
+	limiter      domain.WaRateLimiter
+	startedAt    time.Time
+
+	// connMu guards whatsappConn, the supervisor swaps it on reconnect
+	connMu       sync.RWMutex
 	whatsappConn *whatsapp.Conn
+
+	stateMu sync.Mutex
+	status  domain.WaConnectionStatus
+
+	loginMu       sync.Mutex
+	loginAttempts []*loginAttempt
+
+	healthMu sync.Mutex
+	health   phoneHealth
+
+	// sendMu guards closing and shutdown, sends counts the messages being sent
+	sendMu   sync.Mutex
+	closing  bool
+	shutdown bool
+	sends    sync.WaitGroup
+	stop     chan struct{}
+
+	seenMu sync.Mutex
+	seen   seenMessages
+
+	// ackMu serializes the receipts, they are handled concurrently
+	ackMu sync.Mutex
+
+	// chatsMu guards chats, the last chat list sent by whatsapp
+	chatsMu sync.Mutex
+	chats   []domain.WaChat
 }
 
-func NewWhatsappUsecase(conn *whatsapp.Conn) domain.WhatsappUsecase {
-	return &whatsappUsecase{whatsappConn: conn}
+func NewWhatsappUsecase(sessionID string, conn *whatsapp.Conn, newConn func() (*whatsapp.Conn, error), sessionStore domain.SessionStore, events domain.WaEventBus, messages domain.WaMessageRepository, media domain.WaMediaUsecase, contacts domain.WaContactUsecase, limiter domain.WaRateLimiter) domain.WhatsappUsecase {
+	w := &whatsappUsecase{
+		sessionID:    sessionID,
+		sessionStore: sessionStore,
+		newConn:      newConn,
+		events:       events,
+		messages:     messages,
+		media:        media,
+		contacts:     contacts,
+		limiter:      limiter,
+		startedAt:    time.Now(),
+		whatsappConn: conn,
+		stop:         make(chan struct{}),
+		status: domain.WaConnectionStatus{
+			SessionID:            sessionID,
+			State:                domain.WaStateDisconnected,
+			MaxReconnectAttempts: utils.GetEnvInt("WHATSAPP_RECONNECT_MAX_ATTEMPTS", 50),
+		},
+	}
+
+	go w.pingLoop()
+
+	return w
+}
+
+func (w *whatsappUsecase) SessionID() string {
+	return w.sessionID
 }
 
-func (w *whatsappUsecase) Login(vMajor, vMinor, vBuild, timeout, reconnect int, clientNameShort, clientNameLong string) (qrCodeStr string, err error) {
-	if w.whatsappConn.GetConnected() && w.whatsappConn.GetLoggedIn() {
+func (w *whatsappUsecase) Login(vMajor, vMinor, vBuild, timeout, reconnect, loginTimeout int, clientNameShort, clientNameLong string) (attempt domain.WaLoginAttempt, err error) {
+	if w.conn().GetConnected() && w.conn().GetLoggedIn() {
 		err = errors.New("session already active")
 		return
 	}
 
-	w.whatsappConn, err = whatsapp.NewConnWithOptions(&whatsapp.Options{
+	conn, err := whatsapp.NewConnWithOptions(&whatsapp.Options{
 		// timeout
 		Timeout: time.Duration(timeout) * time.Second,
 		//Proxy:   proxy,
@@ -40,60 +102,45 @@ func (w *whatsappUsecase) Login(vMajor, vMinor, vBuild, timeout, reconnect int,
 		return
 	}
 
-	info, err := syncVersion(w.whatsappConn, vMajor, vMinor, vBuild)
+	info, err := syncVersion(conn, vMajor, vMinor, vBuild)
 	if err != nil {
 		return
 	}
 	log.Println(log.LogLevelInfo, "whatsapp-session-init", info)
 
-	w.whatsappConn.AddHandler(utils.WhatsappHandler{})
-
-	qr := make(chan string)
-	qrCodeChan := make(chan string)
-	go func() {
-		qrStr := <-qr
-		qrCodeChan <- qrStr
-	}()
-
-	go func() {
-		session := whatsapp.Session{}
-		session, err = w.whatsappConn.Login(qr)
-		if err != nil {
-			log.Println(log.LogLevelError, "error during login:", err)
-		} else {
-			log.Println(log.LogLevelInfo, "login successful, session:", session)
+	// Stop a running reconnect loop, the new login replaces its connection
+	w.setState(domain.WaStateDisconnected, nil)
+	w.setMaxReconnect(reconnect)
+	old := w.swapConn(conn)
+	if old != nil {
+		_, _ = old.Disconnect()
+	}
 
-			//save session
-			err = writeSession(session)
-			if err != nil {
-				log.Println(log.LogLevelError, "error during login:", err)
-			}
+	conn.AddHandler(w.handler())
 
-			w.whatsappConn.AddHandler(utils.WhatsappHandler{})
-		}
+	a := newLoginAttempt(w.sessionID, time.Duration(loginTimeout)*time.Second)
+	w.addLoginAttempt(a)
 
-		return
-	}()
+	first := make(chan struct{})
+	go w.runLogin(conn, a, first)
 
-	select {
-	case qrCodeStr = <-qrCodeChan:
-		// Test ping
-		/*err = testPing(w)
-		if err != nil {
-			log.Println(log.LogLevelError, "error during login:", err)
-			return
-		}*/
-		return
+	// Wait for the first QR code, the next ones are streamed to the subscribers
+	<-first
+	attempt = a.snapshot()
+	if attempt.Status == domain.WaLoginFailed {
+		err = errors.New(attempt.Error)
 	}
+
+	return
 }
 
 func (w *whatsappUsecase) GetInfo() (info domain.WaWeb, err error) {
-	if w.whatsappConn.GetConnected() == false || w.whatsappConn.GetLoggedIn() == false {
+	if w.conn().GetConnected() == false || w.conn().GetLoggedIn() == false {
 		err = errors.New("invalid session, please login")
 		return
 	}
 
-	v := w.whatsappConn.GetClientVersion()
+	v := w.conn().GetClientVersion()
 	info.Client.Version.Major = v[0]
 	info.Client.Version.Minor = v[1]
 	info.Client.Version.Build = v[2]
@@ -110,13 +157,30 @@ func (w *whatsappUsecase) GetInfo() (info domain.WaWeb, err error) {
 }
 
 func (w *whatsappUsecase) SendText(form domain.WaSendTextForm) (msgId string, err error) {
-	if w.whatsappConn.GetConnected() == false || w.whatsappConn.GetLoggedIn() == false {
+	if w.conn().GetConnected() == false || w.conn().GetLoggedIn() == false {
 		err = errors.New("invalid session, please login")
 		return
 	}
 
+	if err = w.beginSend(); err != nil {
+		return
+	}
+	defer w.endSend()
+
 	jid := parseMsisdn(form.Msisdn)
 
+	var sent func(err error)
+	if sent, err = w.throttle(jid); err != nil {
+		return
+	}
+	defer func() { sent(err) }()
+
+	if form.TypingSeconds > 0 {
+		if err = w.typing(jid, time.Duration(form.TypingSeconds)*time.Second); err != nil {
+			return
+		}
+	}
+
 	//072217ED965D0C89DC6A
 
 	msg := whatsapp.TextMessage{
@@ -140,19 +204,41 @@ func (w *whatsappUsecase) SendText(form domain.WaSendTextForm) (msgId string, er
 		msg.ContextInfo = ContextInfo
 	}
 
-	msgId, err = w.whatsappConn.Send(msg)
+	msgId, err = w.conn().Send(msg)
+	if err != nil {
+		return
+	}
+
+	w.recordSent(domain.WaMessage{
+		ID:              msgId,
+		Jid:             jid,
+		Type:            "text",
+		Text:            form.Text,
+		QuotedMessageID: form.MsgQuotedID,
+	})
 
 	return
 }
 
 func (w *whatsappUsecase) SendLocation(form domain.WaSendLocationForm) (msgId string, err error) {
-	if w.whatsappConn.GetConnected() == false || w.whatsappConn.GetLoggedIn() == false {
+	if w.conn().GetConnected() == false || w.conn().GetLoggedIn() == false {
 		err = errors.New("invalid session, please login")
 		return
 	}
 
+	if err = w.beginSend(); err != nil {
+		return
+	}
+	defer w.endSend()
+
 	jid := parseMsisdn(form.Msisdn)
 
+	var sent func(err error)
+	if sent, err = w.throttle(jid); err != nil {
+		return
+	}
+	defer func() { sent(err) }()
+
 	msg := whatsapp.LocationMessage{
 		Info: whatsapp.MessageInfo{
 			RemoteJid: jid,
@@ -175,43 +261,153 @@ func (w *whatsappUsecase) SendLocation(form domain.WaSendLocationForm) (msgId st
 		msg.ContextInfo = ContextInfo
 	}
 
-	msgId, err = w.whatsappConn.Send(msg)
+	msgId, err = w.conn().Send(msg)
+	if err != nil {
+		return
+	}
+
+	w.recordSent(domain.WaMessage{
+		ID:              msgId,
+		Jid:             jid,
+		Type:            "location",
+		Latitude:        &form.Latitude,
+		Longitude:       &form.Longitude,
+		QuotedMessageID: form.MsgQuotedID,
+	})
 
 	return
 }
 
 func (w *whatsappUsecase) SendFile(form domain.WaSendFileForm, fileType string) (msgId string, err error) {
-	if w.whatsappConn.GetConnected() == false || w.whatsappConn.GetLoggedIn() == false {
+	if w.conn().GetConnected() == false || w.conn().GetLoggedIn() == false {
 		err = errors.New("invalid session, please login")
 		return
 	}
 
+	if err = w.beginSend(); err != nil {
+		return
+	}
+	defer w.endSend()
+
+	var sent func(err error)
+	if sent, err = w.throttle(parseMsisdn(form.Msisdn)); err != nil {
+		return
+	}
+	defer func() { sent(err) }()
+
 	switch fileType {
 	case "document":
 		msgId, err = sendDocument(w, form)
-		return
 	case "image":
 		msgId, err = sendImage(w, form)
-		return
 	case "audio":
 		msgId, err = sendAudio(w, form)
-		return
 	case "video":
 		msgId, err = sendVideo(w, form)
+	default:
+		err = errors.New("invalid format, please try again")
+	}
+	if err != nil {
+		return
+	}
+
+	message := domain.WaMessage{
+		ID:              msgId,
+		Jid:             parseMsisdn(form.Msisdn),
+		Type:            fileType,
+		MimeType:        form.FileHeader.Header.Get("Content-Type"),
+		FileName:        form.FileHeader.Filename,
+		QuotedMessageID: form.MsgQuotedID,
+	}
+	if fileType == "image" || fileType == "video" {
+		message.Caption = form.Message
+	}
+	w.recordSent(message)
+
+	return
+}
+
+func (w *whatsappUsecase) SendContacts(form domain.WaSendContactForm) (msgId string, err error) {
+	cards, err := contactVcards(form.Contacts)
+	if err != nil {
+		return
+	}
+	if len(cards) > maxContactCards {
+		err = fmt.Errorf("%w: at most %d contacts at once", domain.ErrInvalidContact, maxContactCards)
 		return
 	}
 
-	err = errors.New("invalid format, please try again")
+	if w.conn().GetConnected() == false || w.conn().GetLoggedIn() == false {
+		err = errors.New("invalid session, please login")
+		return
+	}
+
+	if err = w.beginSend(); err != nil {
+		return
+	}
+	defer w.endSend()
+
+	jid := parseMsisdn(form.Msisdn)
+
+	var sent func(err error)
+	if sent, err = w.throttle(jid); err != nil {
+		return
+	}
+	defer func() { sent(err) }()
+
+	var contextInfo whatsapp.ContextInfo
+	if len(form.MsgQuotedID) != 0 {
+		contextInfo = whatsapp.ContextInfo{
+			QuotedMessageID: form.MsgQuotedID,
+			QuotedMessage: &proto.Message{
+				Conversation: &form.MsgQuoted,
+			},
+			Participant: jid,
+		}
+	}
+
+	if len(cards) == 1 {
+		msgId, err = w.conn().Send(whatsapp.ContactMessage{
+			Info: whatsapp.MessageInfo{
+				RemoteJid: jid,
+			},
+			DisplayName: cards[0].displayName,
+			Vcard:       cards[0].vcard,
+			ContextInfo: contextInfo,
+		})
+	} else {
+		msgId, err = w.conn().Send(contactsArrayProto(jid, cards, contextInfo))
+	}
+	if err != nil {
+		return
+	}
+
+	names := make([]string, len(cards))
+	vcards := make([]string, len(cards))
+	for i, card := range cards {
+		names[i] = card.displayName
+		vcards[i] = card.vcard
+	}
+
+	w.recordSent(domain.WaMessage{
+		ID:              msgId,
+		Jid:             jid,
+		Type:            "contact",
+		DisplayName:     strings.Join(names, ", "),
+		Vcard:           strings.Join(vcards, ""),
+		QuotedMessageID: form.MsgQuotedID,
+	})
+
 	return
 }
 
 func (w *whatsappUsecase) Groups(jid string) (g string, err error) {
-	if w.whatsappConn.GetConnected() == false || w.whatsappConn.GetLoggedIn() == false {
+	if w.conn().GetConnected() == false || w.conn().GetLoggedIn() == false {
 		err = errors.New("invalid session, please login")
 		return
 	}
 
-	data, err := w.whatsappConn.GetGroupMetaData(parseMsisdn(jid))
+	data, err := w.conn().GetGroupMetaData(parseMsisdn(jid))
 	if err != nil {
 		return
 	}
@@ -222,92 +418,68 @@ func (w *whatsappUsecase) Groups(jid string) (g string, err error) {
 }
 
 func (w *whatsappUsecase) Logout() (err error) {
-	if w.whatsappConn.GetConnected() == false || w.whatsappConn.GetLoggedIn() == false {
+	if w.conn().GetConnected() == false || w.conn().GetLoggedIn() == false {
 		err = errors.New("invalid session, please login")
 		return
 	}
 
-	err = logout(w.whatsappConn)
+	// Mark the session logged out first so the supervisor ignores the disconnect
+	w.setState(domain.WaStateLoggedOut, nil)
+
+	err = logout(w.conn())
 	if err != nil {
 		return
 	}
 
+	err = w.sessionStore.Delete(w.sessionID)
+
 	return
 }
 
 func (w *whatsappUsecase) RestoreSession() error {
 	//load saved session
-	session, err := readSession()
-	if err == nil {
-		//restore session
-		session, err = w.whatsappConn.RestoreWithSession(session)
-		if err != nil {
-			_ = os.Remove(os.Getenv("WHATSAPP_CLIENT_SESSION_PATH") + "/whatsappSession.gob")
-			return err
-		}
-
-		//save session
-		err = writeSession(session)
-		if err != nil {
-			return err
-		}
-
-		w.whatsappConn.AddHandler(utils.WhatsappHandler{})
+	session, err := w.sessionStore.Read(w.sessionID)
+	if err == domain.ErrSessionNotStored {
+		return nil
 	}
-
-	return nil
-}
-
-func readSession() (whatsapp.Session, error) {
-	fmt.Println(os.TempDir())
-
-	session := whatsapp.Session{}
-	//file, err := os.Open(os.TempDir() + "/whatsappSession.gob")
-	file, err := os.Open(os.Getenv("WHATSAPP_CLIENT_SESSION_PATH") + "/whatsappSession.gob")
 	if err != nil {
-		return session, err
+		return err
 	}
 
-	defer func(file *os.File) {
-		err := file.Close()
-		if err != nil {
-			fmt.Println(err)
-		}
-	}(file)
-
-	decoder := gob.NewDecoder(file)
-	err = decoder.Decode(&session)
+	//restore session
+	session, err = w.conn().RestoreWithSession(session)
+	if err != nil && isLoggedOut(err) {
+		_ = w.sessionStore.Delete(w.sessionID)
+		w.setState(domain.WaStateLoggedOut, err)
+		return err
+	}
 	if err != nil {
-		return session, err
+		// The stored session is kept, the network may just be down yet
+		w.stateMu.Lock()
+		w.setReconnecting(err)
+		w.stateMu.Unlock()
+
+		w.publishConnection()
+
+		go w.reconnectLoop()
+
+		return fmt.Errorf("restore failed, reconnecting: %w", err)
 	}
-	return session, nil
-}
 
-func writeSession(session whatsapp.Session) error {
-	fmt.Println(os.TempDir())
-	//file, err := os.Create(os.TempDir() + "/whatsappSession.gob")
-	file, err := os.Create(os.Getenv("WHATSAPP_CLIENT_SESSION_PATH") + "/whatsappSession.gob")
+	//save session
+	err = w.sessionStore.Write(w.sessionID, session)
 	if err != nil {
 		return err
 	}
 
-	defer func(file *os.File) {
-		err := file.Close()
-		if err != nil {
-			fmt.Println(err)
-		}
-	}(file)
+	w.conn().AddHandler(w.handler())
+	w.setConnected()
 
-	encoder := gob.NewEncoder(file)
-	err = encoder.Encode(session)
-	if err != nil {
-		return err
-	}
 	return nil
 }
 
 func testPing(w *whatsappUsecase) error {
-	conn := w.whatsappConn
+	conn := w.conn()
 	ok, err := conn.AdminTest()
 	if !ok {
 		if err != nil {
@@ -351,7 +523,7 @@ func sendImage(w *whatsappUsecase, form domain.WaSendFileForm) (msgId string, er
 		msg.ContextInfo = ContextInfo
 	}
 
-	msgId, err = w.whatsappConn.Send(msg)
+	msgId, err = w.conn().Send(msg)
 
 	return
 }
@@ -386,7 +558,7 @@ func sendAudio(w *whatsappUsecase, form domain.WaSendFileForm) (msgId string, er
 		msg.ContextInfo = ContextInfo
 	}
 
-	msgId, err = w.whatsappConn.Send(msg)
+	msgId, err = w.conn().Send(msg)
 
 	return
 }
@@ -422,7 +594,7 @@ func sendVideo(w *whatsappUsecase, form domain.WaSendFileForm) (msgId string, er
 		msg.ContextInfo = ContextInfo
 	}
 
-	msgId, err = w.whatsappConn.Send(msg)
+	msgId, err = w.conn().Send(msg)
 
 	return
 }
@@ -459,7 +631,7 @@ func sendDocument(w *whatsappUsecase, form domain.WaSendFileForm) (msgId string,
 		msg.ContextInfo = ContextInfo
 	}
 
-	msgId, err = w.whatsappConn.Send(msg)
+	msgId, err = w.conn().Send(msg)
 
 	return
 }
@@ -475,8 +647,6 @@ func logout(wac *whatsapp.Conn) error {
 		return err
 	}
 
-	_ = os.Remove(os.TempDir() + "/whatsappSession.gob")
-
 	fmt.Println("Logout success..")
 
 	return nil
@@ -1,16 +1,27 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"github.com/Rhymen/go-whatsapp"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
 	_frontendHttpDelivery "github.com/cooljar/go-whatsapp-fiber/frontend/delivery/http"
 	"github.com/cooljar/go-whatsapp-fiber/frontend/delivery/http/configs"
 	_frontendDeliveryMiddleware "github.com/cooljar/go-whatsapp-fiber/frontend/delivery/http/middleware"
+	_frontendRepository "github.com/cooljar/go-whatsapp-fiber/frontend/repository"
 	_frontendUcase "github.com/cooljar/go-whatsapp-fiber/frontend/usecase"
 	"github.com/cooljar/go-whatsapp-fiber/utils"
+	"github.com/go-redis/redis/v8"
 	"github.com/gofiber/fiber/v2"
+	_ "github.com/mattn/go-sqlite3"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	// docs are generated by Swag CLI, you have to import them.
@@ -18,6 +29,12 @@ import (
 )
 
 func init() {
+	if len(os.Args) > 1 && os.Args[1] == "rotate-session-key" {
+		// Only the session store is opened
+		checkSessionStoreEnv()
+		return
+	}
+
 	if os.Getenv("SERVER_URL") == "" {
 		exitf("SERVER_URL env is required")
 	}
@@ -41,9 +58,18 @@ func init() {
 	if os.Getenv("WHATSAPP_CLIENT_VERSION_BUILD") == "" {
 		exitf("WHATSAPP_CLIENT_VERSION_BUILD env is required")
 	}
+
+	checkSessionStoreEnv()
+}
+
+// checkSessionStoreEnv exits when the env of the session store is missing.
+func checkSessionStoreEnv() {
 	if os.Getenv("WHATSAPP_CLIENT_SESSION_PATH") == "" {
 		exitf("WHATSAPP_CLIENT_SESSION_PATH env is required")
 	}
+	if os.Getenv("WHATSAPP_SESSION_STORE") == "redis" && os.Getenv("WHATSAPP_SESSION_REDIS_URL") == "" {
+		exitf("WHATSAPP_SESSION_REDIS_URL env is required when WHATSAPP_SESSION_STORE is redis")
+	}
 }
 
 // @title Go Whatsapp Rest API
@@ -55,42 +81,152 @@ func init() {
 // @license.name Apache 2.0
 // @license.url http://www.apache.org/licenses/LICENSE-2.0.html
 // @BasePath /api
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name Authorization
 func main() {
-	wac, err := whatsapp.NewConnWithOptions(&whatsapp.Options{
-		// timeout
-		Timeout: 20 * time.Second,
-		//Proxy:   proxy,
-		// set custom client name
-		ShortClientName: "Cooljar Whatsapp",
-		LongClientName:  "Cooljar Whatsapp REST Api",
-	})
+	sessionCipher, err := newSessionCipher("WHATSAPP_SESSION_ENCRYPTION_KEY", "WHATSAPP_SESSION_ENCRYPTION_KEY_FILE")
 	if err != nil {
-		exitf("Whatssap connection error: ", err)
+		exitf("Error loading session encryption key: %v", err)
 	}
 
-	waClientVerMajInt, err := strconv.Atoi(os.Getenv("WHATSAPP_CLIENT_VERSION_MAJOR"))
+	if len(os.Args) > 1 && os.Args[1] == "rotate-session-key" {
+		err = rotateSessionKey(sessionCipher)
+		if err != nil {
+			exitf("Error rotating session encryption key: %v", err)
+		}
+		return
+	}
+
+	sessionStore, err := newSessionStore(sessionCipher)
 	if err != nil {
-		exitf("Error conversion", err)
+		exitf("Error opening whatsapp session store: %v", err)
 	}
 
-	waClientVerMinInt, err := strconv.Atoi(os.Getenv("WHATSAPP_CLIENT_VERSION_MINOR"))
+	db, err := openSqlite(os.Getenv("SQLITE_DSN"))
 	if err != nil {
-		exitf("Error conversion", err)
+		exitf("Error opening sqlite database: %v", err)
 	}
 
-	waClientVerBuildInt, err := strconv.Atoi(os.Getenv("WHATSAPP_CLIENT_VERSION_BUILD"))
+	webhookRepository, err := _frontendRepository.NewSqliteWebhookRepository(db)
 	if err != nil {
-		exitf("Error conversion", err)
+		exitf("Error opening webhook repository: %v", err)
 	}
 
-	wac.SetClientVersion(waClientVerMajInt, waClientVerMinInt, waClientVerBuildInt)
+	webhookUsecase, err := _frontendUcase.NewWebhookUsecase(webhookRepository)
+	if err != nil {
+		exitf("Error loading webhooks: %v", err)
+	}
+	webhookUsecase.Start()
+
+	messageRepository, err := _frontendRepository.NewSqliteMessageRepository(db)
+	if err != nil {
+		exitf("Error opening message repository: %v", err)
+	}
+	messageUsecase := _frontendUcase.NewMessageUsecase(messageRepository)
+
+	mediaPath := os.Getenv("MEDIA_PATH")
+	if mediaPath == "" {
+		mediaPath = filepath.Join(os.Getenv("WHATSAPP_CLIENT_SESSION_PATH"), "media")
+	}
+	mediaRepository, err := _frontendRepository.NewFileMediaRepository(mediaPath, db)
+	if err != nil {
+		exitf("Error opening media repository: %v", err)
+	}
+	mediaUsecase := _frontendUcase.NewMediaUsecase(mediaRepository)
+
+	contactRepository, err := _frontendRepository.NewSqliteContactRepository(db)
+	if err != nil {
+		exitf("Error opening contact repository: %v", err)
+	}
+	contactUsecase := _frontendUcase.NewContactUsecase(contactRepository)
+
+	// Every event received by the sessions goes through the event bus
+	eventBus := _frontendUcase.NewWaEventBus()
+	eventBus.Subscribe(messageUsecase.HandleEvent)
+	eventBus.Subscribe(webhookUsecase.HandleEvent)
+
+	// The latest events are kept so the live streams can resume
+	eventStream := _frontendUcase.NewWaEventStream(utils.GetEnvInt("EVENT_STREAM_BUFFER", 1000))
+	eventBus.Subscribe(eventStream.HandleEvent)
+
+	rateLimitRepository, err := _frontendRepository.NewSqliteRateLimitRepository(db)
+	if err != nil {
+		exitf("Error opening rate limit repository: %v", err)
+	}
+	rateLimiter := _frontendUcase.NewRateLimiter(rateLimitRepository)
+
+	whatsappSessionManager := _frontendUcase.NewWhatsappSessionManager(newWhatsappConn, sessionStore, eventBus, messageRepository, mediaUsecase, contactUsecase, rateLimiter)
+
+	autoReplyRepository, err := _frontendRepository.NewSqliteAutoReplyRepository(db)
+	if err != nil {
+		exitf("Error opening auto-reply repository: %v", err)
+	}
+	autoReplyUsecase, err := _frontendUcase.NewAutoReplyUsecase(autoReplyRepository, whatsappSessionManager, mediaUsecase)
+	if err != nil {
+		exitf("Error loading auto-reply rules: %v", err)
+	}
+
+	flowRepository, err := _frontendRepository.NewSqliteFlowRepository(db)
+	if err != nil {
+		exitf("Error opening flow repository: %v", err)
+	}
+	flowUsecase, err := _frontendUcase.NewFlowUsecase(flowRepository, whatsappSessionManager)
+	if err != nil {
+		exitf("Error loading flows: %v", err)
+	}
+	flowUsecase.Start()
+
+	sendQueueRepository, err := _frontendRepository.NewSqliteSendQueueRepository(db)
+	if err != nil {
+		exitf("Error opening send queue repository: %v", err)
+	}
+	sendQueueUsecase := _frontendUcase.NewSendQueueUsecase(sendQueueRepository, whatsappSessionManager, mediaUsecase)
+	sendQueueUsecase.Start()
+
+	campaignRepository, err := _frontendRepository.NewSqliteCampaignRepository(db)
+	if err != nil {
+		exitf("Error opening campaign repository: %v", err)
+	}
+	campaignUsecase := _frontendUcase.NewCampaignUsecase(campaignRepository, whatsappSessionManager, mediaUsecase)
+	campaignUsecase.Start()
+
+	templateRepository, err := _frontendRepository.NewSqliteTemplateRepository(db)
+	if err != nil {
+		exitf("Error opening template repository: %v", err)
+	}
+	templateUsecase := _frontendUcase.NewTemplateUsecase(templateRepository)
+
+	scheduleRepository, err := _frontendRepository.NewSqliteScheduleRepository(db)
+	if err != nil {
+		exitf("Error opening schedule repository: %v", err)
+	}
+	scheduleUsecase := _frontendUcase.NewScheduleUsecase(scheduleRepository, sendQueueUsecase, mediaUsecase)
+	scheduleUsecase.Start()
+
+	idempotencyRepository, err := _frontendRepository.NewSqliteIdempotencyRepository(db)
+	if err != nil {
+		exitf("Error opening idempotency repository: %v", err)
+	}
+	idempotencyUsecase := _frontendUcase.NewIdempotencyUsecase(idempotencyRepository)
 
-	whatsappUsecae := _frontendUcase.NewWhatsappUsecase(wac)
+	// The messages of a contact in a flow are answered by the flow only
+	eventBus.Subscribe(func(event domain.WaEvent) {
+		if !flowUsecase.HandleEvent(event) {
+			autoReplyUsecase.HandleEvent(event)
+		}
+	})
 
-	//Restore session if exists
-	err = whatsappUsecae.RestoreSession()
+	// The default session is always available for the routes without a session_id
+	_, err = whatsappSessionManager.GetOrCreate(domain.DefaultSessionID)
 	if err != nil {
-		exitf("Error restoring whatsapp session. ", err)
+		exitf("Whatssap connection error: ", err)
+	}
+
+	//Restore sessions if exists
+	err = whatsappSessionManager.RestoreAll()
+	if err != nil {
+		exitf("Error restoring whatsapp session: %v", err)
 	}
 
 	// Define Fiber config.
@@ -100,6 +236,9 @@ func main() {
 	// Swagger handler
 	_frontendHttpDelivery.NewSwaggerHandler(app)
 
+	// Liveness and readiness probes
+	_frontendHttpDelivery.NewHealthHandler(app, whatsappSessionManager)
+
 	middL := _frontendDeliveryMiddleware.InitMiddleware(app)
 	app.Use(middL.CORS())
 	app.Use(middL.LOGGER())
@@ -110,11 +249,195 @@ func main() {
 	// router for private access
 	rPrivate := app.Group("/api/v1/auth", middL.JWT())
 
-	_frontendHttpDelivery.NewWhatsappHandler(whatsappUsecae, rPublic, rPrivate)
+	_frontendHttpDelivery.NewWhatsappHandler(whatsappSessionManager, sendQueueUsecase, rateLimiter, templateUsecase, scheduleUsecase, idempotencyUsecase, rPublic, rPrivate)
+	_frontendHttpDelivery.NewMessageHandler(messageUsecase, rPublic, rPrivate)
+	_frontendHttpDelivery.NewContactHandler(contactUsecase, rPublic, rPrivate)
+	_frontendHttpDelivery.NewMediaHandler(mediaUsecase, rPublic, rPrivate)
+	_frontendHttpDelivery.NewWebhookHandler(webhookUsecase, rPublic, rPrivate)
+	_frontendHttpDelivery.NewEventHandler(eventStream, rPublic, rPrivate)
+	_frontendHttpDelivery.NewAutoReplyHandler(autoReplyUsecase, rPublic, rPrivate)
+	_frontendHttpDelivery.NewFlowHandler(flowUsecase, rPublic, rPrivate)
+	_frontendHttpDelivery.NewSendQueueHandler(sendQueueUsecase, rPublic, rPrivate)
+	_frontendHttpDelivery.NewCampaignHandler(campaignUsecase, rPublic, rPrivate)
+	_frontendHttpDelivery.NewTemplateHandler(templateUsecase, rPublic, rPrivate)
+	_frontendHttpDelivery.NewScheduleHandler(scheduleUsecase, rPublic, rPrivate)
 
 	//_frontendHttpDelivery.NewDebugHandler(rPublic, rPublic)
 
-	utils.StartServer(app)
+	shutdownTimeout := time.Duration(utils.GetEnvInt("SERVER_SHUTDOWN_TIMEOUT", 30)) * time.Second
+	// The sessions stop sending first, so the queue and campaign workers waiting
+	// for a rate limiter slot return instead of eating the shutdown deadline
+	stopSending := func(ctx context.Context) error {
+		whatsappSessionManager.StopSending()
+		return nil
+	}
+	utils.StartServerWithGracefulShutdown(app, shutdownTimeout, flowUsecase.Shutdown, scheduleUsecase.Shutdown, stopSending,
+		sendQueueUsecase.Shutdown, campaignUsecase.Shutdown, whatsappSessionManager.Shutdown, webhookUsecase.Shutdown)
+}
+
+// newSessionCipher loads the session encryption key from the keyEnv env as
+// base64 or from the file named by the keyFileEnv env. No key means the
+// sessions are stored unencrypted.
+func newSessionCipher(keyEnv, keyFileEnv string) (*_frontendRepository.SessionCipher, error) {
+	encodedKey := os.Getenv(keyEnv)
+	if encodedKey == "" && os.Getenv(keyFileEnv) != "" {
+		b, err := ioutil.ReadFile(os.Getenv(keyFileEnv))
+		if err != nil {
+			return nil, err
+		}
+		encodedKey = string(b)
+	}
+
+	if encodedKey == "" {
+		return nil, nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encodedKey))
+	if err != nil {
+		return nil, fmt.Errorf("%s is not valid base64: %v", keyEnv, err)
+	}
+
+	return _frontendRepository.NewSessionCipher(key)
+}
+
+// rotateSessionKey re-encrypts every stored session with the key from
+// WHATSAPP_SESSION_ENCRYPTION_NEW_KEY or WHATSAPP_SESSION_ENCRYPTION_NEW_KEY_FILE.
+// Unencrypted sessions are encrypted as well.
+func rotateSessionKey(oldCipher *_frontendRepository.SessionCipher) error {
+	newCipher, err := newSessionCipher("WHATSAPP_SESSION_ENCRYPTION_NEW_KEY", "WHATSAPP_SESSION_ENCRYPTION_NEW_KEY_FILE")
+	if err != nil {
+		return err
+	}
+	if newCipher == nil {
+		return errors.New("WHATSAPP_SESSION_ENCRYPTION_NEW_KEY or WHATSAPP_SESSION_ENCRYPTION_NEW_KEY_FILE env is required")
+	}
+
+	oldStore, err := newSessionStore(oldCipher)
+	if err != nil {
+		return err
+	}
+
+	newStore, err := newSessionStore(newCipher)
+	if err != nil {
+		return err
+	}
+
+	sessionIDs, err := oldStore.List()
+	if err != nil {
+		return err
+	}
+
+	// Decrypt everything first so a wrong old key does not leave the store half rotated
+	sessions := make(map[string]whatsapp.Session, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		sessions[sessionID], err = oldStore.Read(sessionID)
+		if err != nil {
+			return fmt.Errorf("session %s: %w", sessionID, err)
+		}
+	}
+
+	for sessionID, session := range sessions {
+		err = newStore.Write(sessionID, session)
+		if err != nil {
+			return fmt.Errorf("session %s: %w", sessionID, err)
+		}
+	}
+
+	fmt.Printf("%d session(s) re-encrypted, update WHATSAPP_SESSION_ENCRYPTION_KEY to the new key before restarting\n", len(sessions))
+
+	return nil
+}
+
+// newSessionStore opens the session store selected by WHATSAPP_SESSION_STORE,
+// sessions are sealed with c when it is not nil.
+func newSessionStore(c *_frontendRepository.SessionCipher) (domain.SessionStore, error) {
+	switch os.Getenv("WHATSAPP_SESSION_STORE") {
+	case "", "file":
+		return _frontendRepository.NewFileSessionStore(os.Getenv("WHATSAPP_CLIENT_SESSION_PATH"), c), nil
+	case "sqlite":
+		db, err := openSqlite(os.Getenv("WHATSAPP_SESSION_SQLITE_DSN"))
+		if err != nil {
+			return nil, err
+		}
+
+		return _frontendRepository.NewSqliteSessionStore(db, c)
+	case "redis":
+		opt, err := redis.ParseURL(os.Getenv("WHATSAPP_SESSION_REDIS_URL"))
+		if err != nil {
+			return nil, err
+		}
+
+		keyPrefix := os.Getenv("WHATSAPP_SESSION_REDIS_PREFIX")
+		if keyPrefix == "" {
+			keyPrefix = "whatsapp:session:"
+		}
+
+		client := redis.NewClient(opt)
+		if err = client.Ping(context.Background()).Err(); err != nil {
+			return nil, err
+		}
+
+		return _frontendRepository.NewRedisSessionStore(client, keyPrefix, c), nil
+	}
+
+	return nil, fmt.Errorf("unknown WHATSAPP_SESSION_STORE %q, use file, sqlite or redis", os.Getenv("WHATSAPP_SESSION_STORE"))
+}
+
+// openSqlite opens the sqlite database dsn, default to whatsapp.db in the
+// session path. Writers wait for each other instead of failing with
+// "database is locked".
+func openSqlite(dsn string) (*sql.DB, error) {
+	if dsn == "" {
+		dsn = filepath.Join(os.Getenv("WHATSAPP_CLIENT_SESSION_PATH"), "whatsapp.db")
+	}
+	if !strings.Contains(dsn, "_busy_timeout") {
+		if strings.Contains(dsn, "?") {
+			dsn += "&_busy_timeout=5000"
+		} else {
+			dsn += "?_busy_timeout=5000"
+		}
+	}
+
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	return db, db.Ping()
+}
+
+// newWhatsappConn opens a whatsapp connection with the client version from env.
+func newWhatsappConn() (*whatsapp.Conn, error) {
+	wac, err := whatsapp.NewConnWithOptions(&whatsapp.Options{
+		// timeout
+		Timeout: 20 * time.Second,
+		//Proxy:   proxy,
+		// set custom client name
+		ShortClientName: "Cooljar Whatsapp",
+		LongClientName:  "Cooljar Whatsapp REST Api",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	waClientVerMajInt, err := strconv.Atoi(os.Getenv("WHATSAPP_CLIENT_VERSION_MAJOR"))
+	if err != nil {
+		return nil, err
+	}
+
+	waClientVerMinInt, err := strconv.Atoi(os.Getenv("WHATSAPP_CLIENT_VERSION_MINOR"))
+	if err != nil {
+		return nil, err
+	}
+
+	waClientVerBuildInt, err := strconv.Atoi(os.Getenv("WHATSAPP_CLIENT_VERSION_BUILD"))
+	if err != nil {
+		return nil, err
+	}
+
+	wac.SetClientVersion(waClientVerMajInt, waClientVerMinInt, waClientVerBuildInt)
+
+	return wac, nil
 }
 
 func exitf(s string, args ...interface{}) {
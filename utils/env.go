@@ -0,0 +1,16 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+)
+
+// GetEnvInt returns the env as int, or def when it is empty or not a number.
+func GetEnvInt(key string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+
+	return v
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"bytes"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+)
+
+// NewFileHeader wraps content in a multipart file header, as received from an
+// upload, to send a stored file through the paths expecting an upload.
+func NewFileHeader(fileName, mimeType string, content []byte) (*multipart.FileHeader, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{"name": "file", "filename": fileName}))
+	header.Set("Content-Type", mimeType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = part.Write(content); err != nil {
+		return nil, err
+	}
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+
+	form, err := multipart.NewReader(body, writer.Boundary()).ReadForm(int64(len(content)) + 1<<20)
+	if err != nil {
+		return nil, err
+	}
+
+	return form.File["file"][0], nil
+}
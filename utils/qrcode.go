@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/skip2/go-qrcode"
+	"strings"
+)
+
+// QR code output formats
+const (
+	QrFormatPNG      = "png"
+	QrFormatBase64   = "base64"
+	QrFormatJSON     = "json"
+	QrFormatSVG      = "svg"
+	QrFormatTerminal = "terminal"
+)
+
+// ParseQrRecoveryLevel parses the recovery level name: low, medium, high or highest (L, M, Q, H).
+func ParseQrRecoveryLevel(level string) (qrcode.RecoveryLevel, error) {
+	switch strings.ToLower(level) {
+	case "low", "l":
+		return qrcode.Low, nil
+	case "", "medium", "m":
+		return qrcode.Medium, nil
+	case "high", "q":
+		return qrcode.High, nil
+	case "highest", "h":
+		return qrcode.Highest, nil
+	}
+
+	return qrcode.Medium, errors.New("invalid recovery level, use low, medium, high or highest")
+}
+
+// QrPNG renders content as a size x size PNG.
+func QrPNG(content string, level qrcode.RecoveryLevel, size int) ([]byte, error) {
+	return qrcode.Encode(content, level, size)
+}
+
+// QrBase64PNG renders content as a PNG data URI.
+func QrBase64PNG(content string, level qrcode.RecoveryLevel, size int) (string, error) {
+	png, err := QrPNG(content, level, size)
+	if err != nil {
+		return "", err
+	}
+
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(png), nil
+}
+
+// QrSVG renders content as a size x size SVG, one path for all dark modules.
+func QrSVG(content string, level qrcode.RecoveryLevel, size int) ([]byte, error) {
+	q, err := qrcode.New(content, level)
+	if err != nil {
+		return nil, err
+	}
+
+	bitmap := q.Bitmap()
+	modules := len(bitmap)
+
+	var path strings.Builder
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&path, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size, modules, modules)
+	fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="#ffffff"/><path fill="#000000" d="%s"/></svg>`, path.String())
+
+	return buf.Bytes(), nil
+}
+
+// QrTerminal renders content with UTF-8 half blocks, two modules per line,
+// small enough to be scanned from a terminal.
+func QrTerminal(content string, level qrcode.RecoveryLevel) (string, error) {
+	q, err := qrcode.New(content, level)
+	if err != nil {
+		return "", err
+	}
+
+	return q.ToSmallString(false), nil
+}
@@ -1,27 +1,51 @@
 package utils
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 // StartServerWithGracefulShutdown function for starting server with a graceful shutdown.
-func StartServerWithGracefulShutdown(a *fiber.App) {
+// On SIGINT or SIGTERM the server stops accepting requests and waits for the
+// running ones, then the cleanups run. Everything must end within timeout.
+func StartServerWithGracefulShutdown(a *fiber.App, timeout time.Duration, cleanups ...func(ctx context.Context) error) {
 	// Create channel for idle connections.
 	idleConnsClosed := make(chan struct{})
 
 	go func() {
 		sigint := make(chan os.Signal, 1)
-		signal.Notify(sigint, os.Interrupt) // Catch OS signals.
+		signal.Notify(sigint, os.Interrupt, syscall.SIGTERM) // Catch OS signals.
 		<-sigint
 
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
 		// Received an interrupt signal, shutdown.
-		if err := a.Shutdown(); err != nil {
-			// Error from closing listeners, or context timeout:
-			log.Printf("Oops... Server is not shutting down! Reason: %v", err)
+		shutdownErr := make(chan error, 1)
+		go func() {
+			shutdownErr <- a.Shutdown()
+		}()
+
+		select {
+		case err := <-shutdownErr:
+			if err != nil {
+				// Error from closing listeners.
+				log.Printf("Oops... Server is not shutting down! Reason: %v", err)
+			}
+		case <-ctx.Done():
+			log.Printf("Oops... Requests still running at the shutdown deadline, closing anyway")
+		}
+
+		for _, cleanup := range cleanups {
+			if err := cleanup(ctx); err != nil {
+				log.Printf("Oops... Shutdown cleanup failed! Reason: %v", err)
+			}
 		}
 
 		close(idleConnsClosed)
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"encoding/json"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"strings"
+	"time"
+)
+
+// WaAck is a receipt of messages sent by the session, as reported by the
+// whatsapp JSON stream.
+type WaAck struct {
+	IDs       []string
+	Jid       string
+	Ack       domain.WaMessageAck
+	Timestamp time.Time
+}
+
+// waAckLevels maps the ack level of the receipts to the delivery statuses,
+// level -1 (error) and 0 (pending) are not receipts.
+var waAckLevels = map[int]domain.WaMessageAck{
+	1: domain.WaAckServer,
+	2: domain.WaAckDelivered,
+	3: domain.WaAckRead,
+	4: domain.WaAckPlayed,
+}
+
+// ParseWaAck parses a receipt, eg:
+//
+//	["Msg",{"cmd":"ack","id":"3EB0...","ack":2,"from":"6281234567890@c.us","to":"...","t":1626000000}]
+//
+// "acks" receipts hold a list of ids, "MsgInfo" ones are sent for the groups.
+// ok is false when message is not a receipt.
+func ParseWaAck(message string) (ack WaAck, ok bool) {
+	var msg []json.RawMessage
+	if json.Unmarshal([]byte(message), &msg) != nil || len(msg) < 2 {
+		return
+	}
+
+	var kind string
+	if json.Unmarshal(msg[0], &kind) != nil || (kind != "Msg" && kind != "MsgInfo") {
+		return
+	}
+
+	var body struct {
+		Cmd  string          `json:"cmd"`
+		ID   json.RawMessage `json:"id"`
+		Ack  int             `json:"ack"`
+		From string          `json:"from"`
+		T    int64           `json:"t"`
+	}
+	if json.Unmarshal(msg[1], &body) != nil || (body.Cmd != "ack" && body.Cmd != "acks") {
+		return
+	}
+
+	ack.Ack, ok = waAckLevels[body.Ack]
+	if !ok {
+		return
+	}
+
+	var id string
+	if json.Unmarshal(body.ID, &id) == nil {
+		ack.IDs = []string{id}
+	} else if json.Unmarshal(body.ID, &ack.IDs) != nil || len(ack.IDs) == 0 {
+		return ack, false
+	}
+
+	ack.Jid = strings.Replace(body.From, "@c.us", "@s.whatsapp.net", 1)
+	ack.Timestamp = time.Now()
+	if body.T > 0 {
+		ack.Timestamp = time.Unix(body.T, 0)
+	}
+
+	return ack, true
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"github.com/Rhymen/go-whatsapp"
+	"github.com/Rhymen/go-whatsapp/binary"
+	"github.com/Rhymen/go-whatsapp/binary/proto"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewWaChat returns the chat of the chat list sent by whatsapp, the list does
+// not keep the archived and pinned flags.
+func NewWaChat(sessionID string, chat whatsapp.Chat) domain.WaChat {
+	return newWaChat(sessionID, map[string]string{
+		"jid":   chat.Jid,
+		"name":  chat.Name,
+		"count": chat.Unread,
+		"t":     chat.LastMessageTime,
+		"mute":  chat.IsMuted,
+		"spam":  chat.IsMarkedSpam,
+	})
+}
+
+// NewWaChatsFromNode returns the chats of the response to the chats query.
+// Unlike whatsapp.Chat, its nodes hold the archived and pinned flags.
+func NewWaChatsFromNode(sessionID string, node *binary.Node) []domain.WaChat {
+	if node == nil {
+		return nil
+	}
+	nodes, ok := node.Content.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	chats := make([]domain.WaChat, 0, len(nodes))
+	for _, n := range nodes {
+		chatNode, ok := n.(binary.Node)
+		if !ok || chatNode.Attributes["jid"] == "" {
+			continue
+		}
+
+		chats = append(chats, newWaChat(sessionID, chatNode.Attributes))
+	}
+
+	return chats
+}
+
+// newWaChat returns the chat of the attributes of a chat node. mute is the
+// unix time the chat is muted until and pin the one it was pinned at.
+func newWaChat(sessionID string, attributes map[string]string) domain.WaChat {
+	chat := domain.WaChat{
+		SessionID: sessionID,
+		Jid:       strings.Replace(attributes["jid"], "@c.us", "@s.whatsapp.net", 1),
+		Name:      attributes["name"],
+		Archived:  attributes["archive"] == "true",
+		Pinned:    attributes["pin"] != "" && attributes["pin"] != "0",
+		Spam:      attributes["spam"] == "true",
+	}
+
+	// -1 is a chat marked as unread
+	chat.UnreadCount, _ = strconv.Atoi(attributes["count"])
+	if chat.UnreadCount < 0 {
+		chat.UnreadCount = 1
+	}
+
+	if t, _ := strconv.ParseInt(attributes["t"], 10, 64); t > 0 {
+		lastMessageAt := time.Unix(t, 0)
+		chat.LastMessageAt = &lastMessageAt
+	}
+
+	if mute, _ := strconv.ParseInt(attributes["mute"], 10, 64); mute > 0 && time.Unix(mute, 0).After(time.Now()) {
+		mutedUntil := time.Unix(mute, 0)
+		chat.Muted = true
+		chat.MutedUntil = &mutedUntil
+	}
+
+	return chat
+}
+
+// NewWaMessagesFromNode returns the messages of the response to a messages
+// query, the types that are not normalized are left out.
+func NewWaMessagesFromNode(sessionID string, node *binary.Node) []domain.WaMessage {
+	messages := []domain.WaMessage{}
+	if node == nil {
+		return messages
+	}
+	nodes, ok := node.Content.([]interface{})
+	if !ok {
+		return messages
+	}
+
+	for _, n := range nodes {
+		info, ok := n.(*proto.WebMessageInfo)
+		if !ok {
+			continue
+		}
+
+		if message, ok := NewWaProtoMessage(sessionID, info); ok {
+			messages = append(messages, message)
+		}
+	}
+
+	return messages
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"github.com/Rhymen/go-whatsapp"
+	"github.com/Rhymen/go-whatsapp/binary"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"strings"
+)
+
+func NewWaContact(sessionID string, contact whatsapp.Contact) domain.WaContact {
+	return domain.WaContact{
+		SessionID: sessionID,
+		Jid:       contact.Jid,
+		Name:      contact.Name,
+		Notify:    contact.Notify,
+		Short:     contact.Short,
+	}
+}
+
+// NewWaChatContact returns the contact of a chat, the name of a group chat is
+// its subject.
+func NewWaChatContact(sessionID string, chat whatsapp.Chat) domain.WaContact {
+	return domain.WaContact{
+		SessionID: sessionID,
+		Jid:       chat.Jid,
+		Name:      chat.Name,
+	}
+}
+
+// NewWaContactsFromNode returns the contacts of the response to the contacts
+// query. Unlike whatsapp.Contact, its nodes hold the verified name and the
+// verification level of the business accounts.
+func NewWaContactsFromNode(sessionID string, node *binary.Node) []domain.WaContact {
+	if node == nil {
+		return nil
+	}
+	nodes, ok := node.Content.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	contacts := make([]domain.WaContact, 0, len(nodes))
+	for _, n := range nodes {
+		contactNode, ok := n.(binary.Node)
+		if !ok || contactNode.Attributes["jid"] == "" {
+			continue
+		}
+
+		attributes := contactNode.Attributes
+		contacts = append(contacts, domain.WaContact{
+			SessionID:    sessionID,
+			Jid:          strings.Replace(attributes["jid"], "@c.us", "@s.whatsapp.net", 1),
+			Name:         attributes["name"],
+			Notify:       attributes["notify"],
+			Short:        attributes["short"],
+			VerifiedName: attributes["vname"],
+			Business:     attributes["verify"] != "" || attributes["vname"] != "",
+		})
+	}
+
+	return contacts
+}
@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"github.com/Rhymen/go-whatsapp"
+	"github.com/Rhymen/go-whatsapp/binary/proto"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
+	"time"
+)
+
+// NewWaMessage returns the normalized message of info, the type specific
+// fields are left to the caller.
+func NewWaMessage(sessionID, messageType string, info whatsapp.MessageInfo, ctx whatsapp.ContextInfo) domain.WaMessage {
+	direction := domain.WaMessageInbound
+	if info.FromMe {
+		direction = domain.WaMessageOutbound
+	}
+
+	return domain.WaMessage{
+		ID:              info.Id,
+		SessionID:       sessionID,
+		Jid:             info.RemoteJid,
+		SenderJid:       info.SenderJid,
+		PushName:        info.PushName,
+		FromMe:          info.FromMe,
+		Direction:       direction,
+		Type:            messageType,
+		QuotedMessageID: ctx.QuotedMessageID,
+		Timestamp:       time.Unix(int64(info.Timestamp), 0),
+	}
+}
+
+func NewWaTextMessage(sessionID string, message whatsapp.TextMessage) domain.WaMessage {
+	m := NewWaMessage(sessionID, "text", message.Info, message.ContextInfo)
+	m.Text = message.Text
+
+	return m
+}
+
+func NewWaImageMessage(sessionID string, message whatsapp.ImageMessage) domain.WaMessage {
+	m := NewWaMessage(sessionID, "image", message.Info, message.ContextInfo)
+	m.Caption = message.Caption
+	m.MimeType = message.Type
+
+	return m
+}
+
+func NewWaDocumentMessage(sessionID string, message whatsapp.DocumentMessage) domain.WaMessage {
+	m := NewWaMessage(sessionID, "document", message.Info, message.ContextInfo)
+	m.Caption = message.Title
+	m.MimeType = message.Type
+	m.FileName = message.FileName
+
+	return m
+}
+
+func NewWaAudioMessage(sessionID string, message whatsapp.AudioMessage) domain.WaMessage {
+	m := NewWaMessage(sessionID, "audio", message.Info, message.ContextInfo)
+	m.MimeType = message.Type
+
+	return m
+}
+
+func NewWaVideoMessage(sessionID string, message whatsapp.VideoMessage) domain.WaMessage {
+	m := NewWaMessage(sessionID, "video", message.Info, message.ContextInfo)
+	m.Caption = message.Caption
+	m.MimeType = message.Type
+
+	return m
+}
+
+func NewWaContactMessage(sessionID string, message whatsapp.ContactMessage) domain.WaMessage {
+	m := NewWaMessage(sessionID, "contact", message.Info, message.ContextInfo)
+	m.DisplayName = message.DisplayName
+	m.Vcard = message.Vcard
+
+	return m
+}
+
+func NewWaLocationMessage(sessionID string, message whatsapp.LocationMessage) domain.WaMessage {
+	m := NewWaMessage(sessionID, "location", message.Info, message.ContextInfo)
+	m.Latitude = &message.DegreesLatitude
+	m.Longitude = &message.DegreesLongitude
+	m.Text = message.Name
+
+	return m
+}
+
+// NewWaLiveLocationMessage returns a live location as a location message, at
+// the position it was shared from.
+func NewWaLiveLocationMessage(sessionID string, message whatsapp.LiveLocationMessage) domain.WaMessage {
+	m := NewWaMessage(sessionID, "location", message.Info, message.ContextInfo)
+	m.Latitude = &message.DegreesLatitude
+	m.Longitude = &message.DegreesLongitude
+	m.Caption = message.Caption
+
+	return m
+}
+
+// NewWaProtoMessage returns the normalized message of a message loaded from
+// the chat history, ok is false for the types that are not normalized.
+func NewWaProtoMessage(sessionID string, info *proto.WebMessageInfo) (m domain.WaMessage, ok bool) {
+	switch message := whatsapp.ParseProtoMessage(info).(type) {
+	case whatsapp.TextMessage:
+		return NewWaTextMessage(sessionID, message), true
+	case whatsapp.ImageMessage:
+		return NewWaImageMessage(sessionID, message), true
+	case whatsapp.DocumentMessage:
+		return NewWaDocumentMessage(sessionID, message), true
+	case whatsapp.AudioMessage:
+		return NewWaAudioMessage(sessionID, message), true
+	case whatsapp.VideoMessage:
+		return NewWaVideoMessage(sessionID, message), true
+	case whatsapp.ContactMessage:
+		return NewWaContactMessage(sessionID, message), true
+	case whatsapp.LocationMessage:
+		return NewWaLocationMessage(sessionID, message), true
+	case whatsapp.LiveLocationMessage:
+		return NewWaLiveLocationMessage(sessionID, message), true
+	}
+
+	return m, false
+}
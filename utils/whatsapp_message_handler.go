@@ -1,52 +1,189 @@
 package utils
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/Rhymen/go-whatsapp"
+	"github.com/cooljar/go-whatsapp-fiber/domain"
 	"os"
+	"time"
 )
 
-type WhatsappHandler struct{}
+type WhatsappHandler struct {
+	SessionID string
+	// Since drops the messages older than it, whatsapp replays the recent
+	// history of every chat on each connect.
+	Since time.Time
 
-func (WhatsappHandler) HandleError(err error) {
+	// OnDisconnect is called when the connection to the whatsapp servers is lost
+	OnDisconnect func(err error)
+	// OnBattery is called when the phone reports its battery
+	OnBattery func(message whatsapp.BatteryMessage)
+	// OnEvent is called with every event received by the session
+	OnEvent func(event domain.WaEvent)
+	// OnMedia is called with the received media messages before their event,
+	// it may download the media and set the message MediaID. size is the size
+	// announced by the message.
+	OnMedia func(message *domain.WaMessage, size uint64, download func() ([]byte, error))
+	// OnContacts is called with the contacts and chats sent by whatsapp
+	OnContacts func(contacts []domain.WaContact)
+	// OnChats is called with the chat list sent by whatsapp on connect
+	OnChats func(chats []domain.WaChat)
+	// OnAck is called with the receipts of the messages sent by the session
+	OnAck func(ack WaAck)
+}
+
+func (h WhatsappHandler) HandleError(err error) {
 	fmt.Fprintf(os.Stderr, "%v", err)
+
+	switch err.(type) {
+	case *whatsapp.ErrConnectionClosed, *whatsapp.ErrConnectionFailed:
+		if h.OnDisconnect != nil {
+			h.OnDisconnect(err)
+		}
+	}
+}
+
+func (h WhatsappHandler) HandleTextMessage(message whatsapp.TextMessage) {
+	h.message(domain.WaEventText, NewWaTextMessage(h.SessionID, message))
+}
+
+func (h WhatsappHandler) HandleImageMessage(message whatsapp.ImageMessage) {
+	size := message.Info.Source.GetMessage().GetImageMessage().GetFileLength()
+	h.mediaMessage(domain.WaEventImage, NewWaImageMessage(h.SessionID, message), size, message.Download)
+}
+
+func (h WhatsappHandler) HandleDocumentMessage(message whatsapp.DocumentMessage) {
+	size := message.Info.Source.GetMessage().GetDocumentMessage().GetFileLength()
+	h.mediaMessage(domain.WaEventDocument, NewWaDocumentMessage(h.SessionID, message), size, message.Download)
+}
+
+func (h WhatsappHandler) HandleVideoMessage(message whatsapp.VideoMessage) {
+	size := message.Info.Source.GetMessage().GetVideoMessage().GetFileLength()
+	h.mediaMessage(domain.WaEventVideo, NewWaVideoMessage(h.SessionID, message), size, message.Download)
+}
+
+func (h WhatsappHandler) HandleAudioMessage(message whatsapp.AudioMessage) {
+	size := message.Info.Source.GetMessage().GetAudioMessage().GetFileLength()
+	h.mediaMessage(domain.WaEventAudio, NewWaAudioMessage(h.SessionID, message), size, message.Download)
+}
+
+func (h WhatsappHandler) HandleJsonMessage(message string) {
+	if !json.Valid([]byte(message)) {
+		return
+	}
+
+	if ack, ok := ParseWaAck(message); ok && h.OnAck != nil {
+		h.OnAck(ack)
+	}
+
+	h.event(domain.WaEvent{
+		Type: domain.WaEventJSON,
+		Data: json.RawMessage(message),
+	})
 }
 
-func (WhatsappHandler) HandleTextMessage(message whatsapp.TextMessage) {
-	fmt.Println("------------------------------")
-	fmt.Println("RemoteJid: ", message.Info.RemoteJid)
-	fmt.Println("Text: ", message.Text)
-	fmt.Println("------------------------------")
+func (h WhatsappHandler) HandleContactMessage(message whatsapp.ContactMessage) {
+	h.message(domain.WaEventContact, NewWaContactMessage(h.SessionID, message))
 }
 
-func (WhatsappHandler) HandleImageMessage(message whatsapp.ImageMessage) {
-	//fmt.Println(message)
+func (h WhatsappHandler) HandleLocationMessage(message whatsapp.LocationMessage) {
+	h.message(domain.WaEventLocation, NewWaLocationMessage(h.SessionID, message))
 }
 
-func (WhatsappHandler) HandleDocumentMessage(message whatsapp.DocumentMessage) {
-	//fmt.Println(message)
+func (h WhatsappHandler) HandleLiveLocationMessage(message whatsapp.LiveLocationMessage) {
+	h.message(domain.WaEventLocation, NewWaLiveLocationMessage(h.SessionID, message))
 }
 
-func (WhatsappHandler) HandleVideoMessage(message whatsapp.VideoMessage) {
-	//fmt.Println(message)
+func (h WhatsappHandler) HandleBatteryMessage(message whatsapp.BatteryMessage) {
+	if h.OnBattery != nil {
+		h.OnBattery(message)
+	}
+
+	h.event(domain.WaEvent{
+		Type: domain.WaEventBattery,
+		Data: domain.WaBattery{
+			Percentage: message.Percentage,
+			Plugged:    message.Plugged,
+			Powersave:  message.Powersave,
+			UpdatedAt:  time.Now(),
+		},
+	})
 }
 
-func (WhatsappHandler) HandleAudioMessage(message whatsapp.AudioMessage) {
-	//fmt.Println(message)
+func (h WhatsappHandler) HandleNewContact(contact whatsapp.Contact) {
+	h.contacts([]domain.WaContact{NewWaContact(h.SessionID, contact)})
 }
 
-func (WhatsappHandler) HandleJsonMessage(message string) {
-	//fmt.Println(message)
+func (h WhatsappHandler) HandleContactList(contacts []whatsapp.Contact) {
+	waContacts := make([]domain.WaContact, 0, len(contacts))
+	for _, contact := range contacts {
+		waContacts = append(waContacts, NewWaContact(h.SessionID, contact))
+	}
+
+	h.contacts(waContacts)
 }
 
-func (WhatsappHandler) HandleContactMessage(message whatsapp.ContactMessage) {
-	//fmt.Println(message)
+func (h WhatsappHandler) HandleChatList(chats []whatsapp.Chat) {
+	waContacts := make([]domain.WaContact, 0, len(chats))
+	for _, chat := range chats {
+		waContacts = append(waContacts, NewWaChatContact(h.SessionID, chat))
+	}
+
+	h.contacts(waContacts)
+
+	if h.OnChats != nil {
+		waChats := make([]domain.WaChat, 0, len(chats))
+		for _, chat := range chats {
+			waChats = append(waChats, NewWaChat(h.SessionID, chat))
+		}
+		h.OnChats(waChats)
+	}
 }
 
-func (WhatsappHandler) HandleBatteryMessage(message whatsapp.BatteryMessage) {
-	//fmt.Println(message)
+func (h WhatsappHandler) contacts(contacts []domain.WaContact) {
+	if h.OnContacts != nil && len(contacts) > 0 {
+		h.OnContacts(contacts)
+	}
 }
 
-func (WhatsappHandler) HandleNewContact(contact whatsapp.Contact) {
-	//fmt.Println(contact)
+// mediaMessage hands the media of a received message to OnMedia, then
+// publishes the message.
+func (h WhatsappHandler) mediaMessage(eventType domain.WaEventType, message domain.WaMessage, size uint64, download func() ([]byte, error)) {
+	if message.Timestamp.Before(h.Since) {
+		return
+	}
+
+	if h.OnMedia != nil && !message.FromMe {
+		h.OnMedia(&message, size, download)
+	}
+
+	h.message(eventType, message)
+}
+
+func (h WhatsappHandler) message(eventType domain.WaEventType, message domain.WaMessage) {
+	if message.Timestamp.Before(h.Since) {
+		return
+	}
+
+	h.event(domain.WaEvent{
+		Type:      eventType,
+		Jid:       message.Jid,
+		Timestamp: message.Timestamp,
+		Data:      message,
+	})
+}
+
+func (h WhatsappHandler) event(event domain.WaEvent) {
+	if h.OnEvent == nil {
+		return
+	}
+
+	event.ID = NewID()
+	event.SessionID = h.SessionID
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	h.OnEvent(event)
 }